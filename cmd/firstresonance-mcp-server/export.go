@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/exporter"
+	"github.com/firstresonance/fr-mcp-server/pkg/firstresonance"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export ENTITY",
+	Short: "Export parts, orders, suppliers, inventory items or ABOMs to a file",
+	Long: fmt.Sprintf(`Export every %s as CSV, JSON Lines or Parquet.
+Records are streamed page by page, so large result sets are not held in memory.
+
+ABOMs are written one per record with their items as a list (--abom-layout nested),
+or one record per item with the ABOM's fields repeated (--abom-layout flat).`,
+		strings.Join(firstresonance.ExportEntities, ", ")),
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: firstresonance.ExportEntities,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		fields, _ := cmd.Flags().GetStringSlice("fields")
+		expr, _ := cmd.Flags().GetString("filter")
+		layout, _ := cmd.Flags().GetString("abom-layout")
+		output, _ := cmd.Flags().GetString("output")
+		if format == "" {
+			format = formatFromPath(output)
+		}
+
+		client, err := newClient(configFromViper())
+		if err != nil {
+			return err
+		}
+
+		w := os.Stdout
+		if output != "" && output != "-" {
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer func() { _ = f.Close() }()
+			w = f
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		count, err := client.Export.Export(ctx, w, &firstresonance.ExportOptions{
+			Entity:     args[0],
+			Format:     format,
+			Fields:     fields,
+			Filter:     expr,
+			ABomLayout: layout,
+		})
+		if err != nil {
+			return err
+		}
+		if w != os.Stdout {
+			if err := w.Sync(); err != nil {
+				return fmt.Errorf("failed to write output file: %w", err)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "exported %d records\n", count)
+		return nil
+	},
+}
+
+// formatFromPath returns the export format for an output file's extension,
+// defaulting to CSV
+func formatFromPath(path string) string {
+	for _, format := range exporter.Formats {
+		if strings.HasSuffix(strings.ToLower(path), "."+format) {
+			return format
+		}
+	}
+	return exporter.FormatCSV
+}
+
+func init() {
+	exportCmd.Flags().String("format", "", fmt.Sprintf("Output format: %s (default from the output file's extension, else csv)", strings.Join(exporter.Formats, ", ")))
+	exportCmd.Flags().StringSlice("fields", nil, "Fields to write, in order (default all)")
+	exportCmd.Flags().String("filter", "", "Filter expression records must match, e.g. \"status in (active,draft)\"")
+	exportCmd.Flags().String("abom-layout", firstresonance.ABomLayoutNested, fmt.Sprintf("Layout of ABOMs: %s or %s", firstresonance.ABomLayoutNested, firstresonance.ABomLayoutFlat))
+	exportCmd.Flags().StringP("output", "o", "", "File to write (default standard output)")
+
+	rootCmd.AddCommand(exportCmd)
+}
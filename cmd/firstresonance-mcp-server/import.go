@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/audit"
+	"github.com/firstresonance/fr-mcp-server/pkg/firstresonance"
+	"github.com/firstresonance/fr-mcp-server/pkg/importer"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import FILE",
+	Short: "Bulk create or update records from a CSV or XLSX file",
+	Long: `Create or update parts, suppliers or inventory items from the rows of a CSV or XLSX file.
+Rows with an id update that record; others are created.
+
+Without --apply, every row is validated and a report of what would change is printed.
+With --apply, nothing is imported if any row is invalid. The result of every row is
+appended to the results file, and rerunning the same import skips the rows it records
+as done, so a partially failed import can be resumed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file := args[0]
+		apply, _ := cmd.Flags().GetBool("apply")
+		if apply && viper.GetBool("read-only") {
+			return fmt.Errorf("cannot apply an import in read-only mode")
+		}
+
+		entity, _ := cmd.Flags().GetString("entity")
+		sheet, _ := cmd.Flags().GetString("sheet")
+		pairs, _ := cmd.Flags().GetStringSlice("map")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		batchSize, _ := cmd.Flags().GetInt("batch-size")
+		resultsFile, _ := cmd.Flags().GetString("results")
+		if resultsFile == "" {
+			resultsFile = file + ".results.jsonl"
+		}
+
+		mapping := map[string]string{}
+		for _, pair := range pairs {
+			field, column, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("invalid --map %q, expected field=column", pair)
+			}
+			mapping[field] = column
+		}
+
+		table, err := importer.ReadFile(file, sheet)
+		if err != nil {
+			return err
+		}
+		client, err := newClient(configFromViper())
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		ctx = audit.WithCall(ctx, audit.Call{Caller: "cli", Tool: "import", Arguments: map[string]interface{}{"file": file, "entity": entity}})
+
+		report, err := client.Import.Import(ctx, table, &firstresonance.ImportOptions{
+			Entity:  entity,
+			Mapping: mapping,
+			Options: importer.Options{
+				DryRun:      !apply,
+				Concurrency: concurrency,
+				BatchSize:   batchSize,
+				ResultsPath: resultsFile,
+				InputPath:   file,
+			},
+		})
+		if report != nil {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return fmt.Errorf("failed to write report: %w", err)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if report.Failed > 0 {
+			return fmt.Errorf("%d rows failed, see %s; run again to retry them", report.Failed, resultsFile)
+		}
+		return nil
+	},
+}
+
+func init() {
+	importCmd.Flags().String("entity", "", fmt.Sprintf("Type of record in every row: %s", strings.Join(firstresonance.ImportEntities, ", ")))
+	importCmd.Flags().String("sheet", "", "Worksheet to read from an XLSX file (default the first)")
+	importCmd.Flags().StringSlice("map", nil, "Column for a field, as field=column, where not named the same (repeatable)")
+	importCmd.Flags().Bool("apply", false, "Create and update the records; without it only a dry-run report is printed")
+	importCmd.Flags().Int("concurrency", importer.DefaultConcurrency, "Rows applied at once")
+	importCmd.Flags().Int("batch-size", importer.DefaultBatchSize, "Rows applied before checking whether the import was cancelled")
+	importCmd.Flags().String("results", "", "JSON Lines file recording the result of every row (default FILE.results.jsonl)")
+	_ = importCmd.MarkFlagRequired("entity")
+
+	rootCmd.AddCommand(importCmd)
+}
@@ -7,14 +7,18 @@ import (
 	stdlog "log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/mark3labs/mcp-go/server"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/firstresonance/fr-mcp-server/pkg/audit"
 	"github.com/firstresonance/fr-mcp-server/pkg/firstresonance"
+	"github.com/firstresonance/fr-mcp-server/pkg/translations"
 )
 
 var version = "version"
@@ -40,12 +44,10 @@ var (
 			if err != nil {
 				stdlog.Fatal("Failed to initialize logger:", err)
 			}
-			logCommands := viper.GetBool("enable-command-logging")
-			cfg := runConfig{
-				readOnly:    readOnly,
-				logger:      logger,
-				logCommands: logCommands,
-			}
+			cfg := configFromViper()
+			cfg.readOnly = readOnly
+			cfg.logger = logger
+			cfg.logCommands = viper.GetBool("enable-command-logging")
 			if err := runStdioServer(cfg); err != nil {
 				stdlog.Fatal("failed to run stdio server:", err)
 			}
@@ -60,11 +62,33 @@ func init() {
 	rootCmd.PersistentFlags().Bool("read-only", false, "Restrict the server to read-only operations")
 	rootCmd.PersistentFlags().String("log-file", "", "Path to log file")
 	rootCmd.PersistentFlags().Bool("enable-command-logging", false, "When enabled, the server will log all command requests and responses to the log file")
+	rootCmd.PersistentFlags().String("fr-host", "", "Specify the First Resonance hostname")
+	rootCmd.PersistentFlags().String("audit-log", "", "Path to an append-only JSON Lines audit log of every create and update")
+	rootCmd.PersistentFlags().String("reorder-points", "", "Path to a JSON file of per-part minimum stock and reorder quantities")
+	rootCmd.PersistentFlags().String("approved-vendors", "", "Path to a JSON file of approved vendors per part, used when the First Resonance API does not keep an approved vendor list")
+	rootCmd.PersistentFlags().String("cost-table", "", "Path to a JSON file of per-part standard costs; parts not in it are costed from their order line prices")
+	rootCmd.PersistentFlags().String("search-index", "", "Path to persist a local full-text index of parts, suppliers and orders; enables search_local")
+	rootCmd.PersistentFlags().Duration("search-index-refresh", 15*time.Minute, "How often the local search index is rebuilt")
+	rootCmd.PersistentFlags().Bool("redact-contacts", false, "Mask supplier contact names, emails, phones and street addresses in tool and resource results")
+	rootCmd.PersistentFlags().String("order-lifecycle", "", "Path to a JSON file defining order statuses and allowed transitions (default draft → submitted → approved → received → closed)")
+	rootCmd.PersistentFlags().String("units", "", "Path to a JSON file of further units of measure and of per-part base and alternate units")
+	rootCmd.PersistentFlags().String("import-dir", "", "Directory the import_records tool reads files from and writes results to; the tool is disabled without it")
 
 	// Bind flag to viper
 	_ = viper.BindPFlag("read-only", rootCmd.PersistentFlags().Lookup("read-only"))
 	_ = viper.BindPFlag("log-file", rootCmd.PersistentFlags().Lookup("log-file"))
 	_ = viper.BindPFlag("enable-command-logging", rootCmd.PersistentFlags().Lookup("enable-command-logging"))
+	_ = viper.BindPFlag("fr-host", rootCmd.PersistentFlags().Lookup("fr-host"))
+	_ = viper.BindPFlag("audit-log", rootCmd.PersistentFlags().Lookup("audit-log"))
+	_ = viper.BindPFlag("reorder-points", rootCmd.PersistentFlags().Lookup("reorder-points"))
+	_ = viper.BindPFlag("approved-vendors", rootCmd.PersistentFlags().Lookup("approved-vendors"))
+	_ = viper.BindPFlag("cost-table", rootCmd.PersistentFlags().Lookup("cost-table"))
+	_ = viper.BindPFlag("search-index", rootCmd.PersistentFlags().Lookup("search-index"))
+	_ = viper.BindPFlag("search-index-refresh", rootCmd.PersistentFlags().Lookup("search-index-refresh"))
+	_ = viper.BindPFlag("order-lifecycle", rootCmd.PersistentFlags().Lookup("order-lifecycle"))
+	_ = viper.BindPFlag("redact-contacts", rootCmd.PersistentFlags().Lookup("redact-contacts"))
+	_ = viper.BindPFlag("units", rootCmd.PersistentFlags().Lookup("units"))
+	_ = viper.BindPFlag("import-dir", rootCmd.PersistentFlags().Lookup("import-dir"))
 
 	// Add subcommands
 	rootCmd.AddCommand(stdioCmd)
@@ -73,7 +97,11 @@ func init() {
 func initConfig() {
 	// Initialize Viper configuration
 	viper.SetEnvPrefix("FR_MCP")
+	// Read flags such as order-lifecycle from FR_MCP_ORDER_LIFECYCLE
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.AutomaticEnv()
+	_ = viper.BindEnv("fr-host", "FR_HOST")
+	_ = viper.BindEnv("personal-access-token", "FIRSTRESONANCE_API_TOKEN")
 }
 
 func initLogger(outPath string) (*log.Logger, error) {
@@ -97,6 +125,84 @@ type runConfig struct {
 	readOnly    bool
 	logger      *log.Logger
 	logCommands bool
+	host        string
+	token       string
+	auditLog    string
+	reorderFile string
+	vendorsFile string
+	costsFile   string
+	indexFile   string
+	indexEvery  time.Duration
+	lifecycle   string
+	unitsFile   string
+	importDir   string
+	redact      bool
+}
+
+// configFromViper reads the client configuration shared by every command
+// from flags and the environment
+func configFromViper() runConfig {
+	return runConfig{
+		host:        viper.GetString("fr-host"),
+		token:       viper.GetString("personal-access-token"),
+		auditLog:    viper.GetString("audit-log"),
+		reorderFile: viper.GetString("reorder-points"),
+		vendorsFile: viper.GetString("approved-vendors"),
+		costsFile:   viper.GetString("cost-table"),
+		indexFile:   viper.GetString("search-index"),
+		indexEvery:  viper.GetDuration("search-index-refresh"),
+		lifecycle:   viper.GetString("order-lifecycle"),
+		unitsFile:   viper.GetString("units"),
+		importDir:   viper.GetString("import-dir"),
+		redact:      viper.GetBool("redact-contacts"),
+	}
+}
+
+// newClient creates a First Resonance client from the run configuration
+func newClient(cfg runConfig) (*firstresonance.Client, error) {
+	if cfg.token == "" {
+		return nil, fmt.Errorf("FIRSTRESONANCE_API_TOKEN not set")
+	}
+	if cfg.host == "" {
+		return nil, fmt.Errorf("FR_HOST not set")
+	}
+
+	client := firstresonance.NewClient(cfg.host, cfg.token, nil)
+	client.SetContactRedaction(cfg.redact)
+	if cfg.auditLog != "" {
+		client.SetAuditSink(audit.NewFileSink(cfg.auditLog))
+	}
+	if cfg.reorderFile != "" {
+		client.SetReorderPointsFile(cfg.reorderFile)
+	}
+	if cfg.vendorsFile != "" {
+		client.SetApprovedVendorsFile(cfg.vendorsFile)
+	}
+	if cfg.costsFile != "" {
+		client.SetCostTableFile(cfg.costsFile)
+	}
+	if cfg.importDir != "" {
+		client.SetImportDir(cfg.importDir)
+	}
+	if cfg.indexFile != "" {
+		if cfg.indexEvery <= 0 {
+			return nil, fmt.Errorf("search-index-refresh must be positive")
+		}
+		if err := client.SetSearchIndexFile(cfg.indexFile); err != nil {
+			return nil, fmt.Errorf("failed to load search index: %w", err)
+		}
+	}
+	if cfg.lifecycle != "" {
+		if err := client.SetOrderLifecycleFile(cfg.lifecycle); err != nil {
+			return nil, fmt.Errorf("failed to load order lifecycle: %w", err)
+		}
+	}
+	if cfg.unitsFile != "" {
+		if err := client.SetUnitsFile(cfg.unitsFile); err != nil {
+			return nil, fmt.Errorf("failed to load units: %w", err)
+		}
+	}
+	return client, nil
 }
 
 func runStdioServer(cfg runConfig) error {
@@ -104,8 +210,24 @@ func runStdioServer(cfg runConfig) error {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// Create First Resonance client
+	client, err := newClient(cfg)
+	if err != nil {
+		return err
+	}
+	getClient := func(_ context.Context) (*firstresonance.Client, error) {
+		return client, nil
+	}
+
+	// Keep the local search index fresh
+	if client.SearchIndex.Enabled() {
+		go client.SearchIndex.Run(ctx, cfg.indexEvery, func(err error) {
+			cfg.logger.WithError(err).Error("failed to refresh search index")
+		})
+	}
+
 	// Create First Resonance server
-	frServer := firstresonance.NewServer(version, cfg.readOnly)
+	frServer := firstresonance.NewServer(getClient, version, cfg.readOnly, translations.NullTranslationHelper)
 	stdioServer := server.NewStdioServer(frServer)
 
 	stdLogger := stdlog.New(cfg.logger.Writer(), "stdioserver", 0)
@@ -131,7 +131,14 @@ func (h *ModelHandler) handleCreateOrder(ctx context.Context, req *ModelRequest,
 		}, nil
 	}
 
-	if items, ok := orderData["items"].([]interface{}); ok {
+	if rawItems, ok := orderData["items"].([]interface{}); ok {
+		items, err := firstresonance.ParseOrderItems(rawItems)
+		if err != nil {
+			return &ModelResponse{
+				Success: false,
+				Error:   err.Error(),
+			}, nil
+		}
 		order.Items = items
 	} else {
 		return &ModelResponse{
@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+// Actions recorded by the API client
+const (
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionAdjust   = "adjust"
+	ActionTransfer = "transfer"
+	ActionReceive  = "receive"
+)
+
+// Outcomes recorded for a mutation
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Entry represents a single mutation recorded in the audit log
+type Entry struct {
+	Timestamp time.Time              `json:"timestamp"`
+	Caller    string                 `json:"caller,omitempty"`
+	Tool      string                 `json:"tool,omitempty"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Entity    string                 `json:"entity"`
+	EntityID  string                 `json:"entity_id,omitempty"`
+	Action    string                 `json:"action"`
+	Before    interface{}            `json:"before,omitempty"`
+	After     interface{}            `json:"after,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	Outcome   string                 `json:"outcome"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// QueryOptions represents filters for querying the audit log
+type QueryOptions struct {
+	Entity   string
+	EntityID string
+	Action   string
+	Tool     string
+	Caller   string
+	Outcome  string
+	Since    time.Time
+	Until    time.Time
+	Limit    int
+}
+
+// Matches reports whether an entry satisfies every filter set in the options
+func (o *QueryOptions) Matches(e *Entry) bool {
+	if o == nil {
+		return true
+	}
+	if o.Entity != "" && e.Entity != o.Entity {
+		return false
+	}
+	if o.EntityID != "" && e.EntityID != o.EntityID {
+		return false
+	}
+	if o.Action != "" && e.Action != o.Action {
+		return false
+	}
+	if o.Tool != "" && e.Tool != o.Tool {
+		return false
+	}
+	if o.Caller != "" && e.Caller != o.Caller {
+		return false
+	}
+	if o.Outcome != "" && e.Outcome != o.Outcome {
+		return false
+	}
+	if !o.Since.IsZero() && e.Timestamp.Before(o.Since) {
+		return false
+	}
+	if !o.Until.IsZero() && e.Timestamp.After(o.Until) {
+		return false
+	}
+	return true
+}
+
+// Sink is a destination for audit entries. Implementations must be safe for
+// concurrent use and must never modify or remove entries once appended.
+type Sink interface {
+	// Append adds an entry to the end of the log
+	Append(ctx context.Context, entry *Entry) error
+	// Query returns matching entries, most recent first
+	Query(ctx context.Context, opts *QueryOptions) ([]*Entry, error)
+}
+
+// Call identifies the tool call on whose behalf a mutation is made
+type Call struct {
+	Caller    string
+	Tool      string
+	Arguments map[string]interface{}
+}
+
+type callKey struct{}
+
+// WithCall returns a copy of ctx that carries the given tool call
+func WithCall(ctx context.Context, call Call) context.Context {
+	return context.WithValue(ctx, callKey{}, call)
+}
+
+// CallFromContext returns the tool call carried by ctx, if any
+func CallFromContext(ctx context.Context) (Call, bool) {
+	call, ok := ctx.Value(callKey{}).(Call)
+	return call, ok
+}
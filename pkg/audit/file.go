@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FileSink is a Sink that appends entries to a local JSON Lines file
+type FileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileSink creates a new FileSink writing to the given path. The file is
+// created on first append if it does not exist.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Append implements Sink
+func (s *FileSink) Append(_ context.Context, entry *Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := file.Write(line); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+	return file.Close()
+}
+
+// Query implements Sink
+func (s *FileSink) Query(ctx context.Context, opts *QueryOptions) ([]*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var entries []*Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log line %d: %w", lineNo, err)
+		}
+		if opts.Matches(&entry) {
+			entries = append(entries, &entry)
+			// Only the last Limit matches are returned, so keep no more
+			// than twice that many
+			if opts != nil && opts.Limit > 0 && len(entries) == 2*opts.Limit {
+				entries = append(entries[:0], entries[opts.Limit:]...)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	// Return the most recent entries first
+	result := make([]*Entry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		result = append(result, entries[i])
+		if opts != nil && opts.Limit > 0 && len(result) == opts.Limit {
+			break
+		}
+	}
+	return result, nil
+}
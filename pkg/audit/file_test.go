@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Query on missing file returns no entries", func(t *testing.T) {
+		sink := NewFileSink(filepath.Join(t.TempDir(), "audit.jsonl"))
+
+		entries, err := sink.Query(ctx, nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Append and query round trip with filters", func(t *testing.T) {
+		sink := NewFileSink(filepath.Join(t.TempDir(), "audit.jsonl"))
+		for i, e := range []*Entry{
+			{Entity: "part", EntityID: "p1", Action: "create", Outcome: OutcomeSuccess, Tool: "create_part"},
+			{Entity: "order", EntityID: "o1", Action: "update", Outcome: OutcomeFailure, Error: "boom"},
+			{Entity: "part", EntityID: "p1", Action: "update", Outcome: OutcomeSuccess,
+				Before: map[string]interface{}{"name": "old"}, After: map[string]interface{}{"name": "new"}},
+		} {
+			e.Timestamp = base.Add(time.Duration(i) * time.Hour)
+			require.NoError(t, sink.Append(ctx, e))
+		}
+
+		entries, err := sink.Query(ctx, &QueryOptions{Entity: "part"})
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, "update", entries[0].Action, "most recent entry first")
+		assert.Equal(t, map[string]interface{}{"name": "old"}, entries[0].Before)
+		assert.Equal(t, "create", entries[1].Action)
+
+		entries, err = sink.Query(ctx, &QueryOptions{Outcome: OutcomeFailure})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "boom", entries[0].Error)
+
+		entries, err = sink.Query(ctx, &QueryOptions{Since: base.Add(30 * time.Minute), Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, base.Add(2*time.Hour), entries[0].Timestamp)
+	})
+
+	t.Run("Limit returns the most recent matches", func(t *testing.T) {
+		sink := NewFileSink(filepath.Join(t.TempDir(), "audit.jsonl"))
+		for i := range 11 {
+			require.NoError(t, sink.Append(ctx, &Entry{Timestamp: base.Add(time.Duration(i) * time.Hour), Entity: "part", Outcome: OutcomeSuccess}))
+		}
+
+		entries, err := sink.Query(ctx, &QueryOptions{Limit: 3})
+
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		for i, e := range entries {
+			assert.Equal(t, base.Add(time.Duration(10-i)*time.Hour), e.Timestamp)
+		}
+	})
+}
+
+func TestCallContext(t *testing.T) {
+	_, ok := CallFromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := WithCall(context.Background(), Call{Caller: "agent", Tool: "update_part"})
+	call, ok := CallFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "update_part", call.Tool)
+}
@@ -0,0 +1,140 @@
+// Package contacts validates, normalizes and masks personal contact data.
+//
+// Email addresses are normalized to a bare lowercase address. Phone numbers
+// are normalized to their digits, keeping a leading + for international
+// numbers and an extension as ";ext=", as in RFC 3966. The Mask functions
+// hide all but enough of a value to tell values apart.
+package contacts
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	// minPhoneDigits and maxPhoneDigits bound the digits of a phone number;
+	// E.164 numbers have at most 15
+	minPhoneDigits = 7
+	maxPhoneDigits = 15
+)
+
+// Masked replaces a hidden value
+const Masked = "***"
+
+// extension matches a phone extension at the end of a number
+var extension = regexp.MustCompile(`(?i)\s*(?:;\s*ext=|ext\.?|extension|x|#)\s*(\d{1,6})\s*$`)
+
+// NormalizeEmail validates an email address and returns it trimmed and
+// lowercased. Display names, as in "Jane <jane@example.com>", are rejected.
+func NormalizeEmail(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Name != "" || addr.Address != s {
+		return "", fmt.Errorf("%q is not an email address", s)
+	}
+	local, domain, _ := strings.Cut(addr.Address, "@")
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", fmt.Errorf("%q is not an email address: domain %q has no top-level domain", s, domain)
+	}
+	return strings.ToLower(local + "@" + domain), nil
+}
+
+// NormalizePhone validates a phone number and returns its digits, with a
+// leading + if the number was written with one or with a 00 international
+// prefix, and its extension as ";ext=N". Spaces, dashes, dots, slashes and
+// parentheses are dropped.
+func NormalizePhone(s string) (string, error) {
+	number := strings.TrimSpace(s)
+	var ext string
+	if m := extension.FindStringSubmatchIndex(number); m != nil {
+		ext = number[m[2]:m[3]]
+		number = number[:m[0]]
+	}
+
+	// A trunk prefix written as (0) after a country code is not dialled
+	if strings.HasPrefix(number, "+") {
+		number = strings.Replace(number, "(0)", "", 1)
+	}
+
+	var digits strings.Builder
+	international := false
+	for i, r := range number {
+		switch {
+		case unicode.IsDigit(r) && r <= unicode.MaxASCII:
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+			international = true
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '/':
+		default:
+			return "", fmt.Errorf("%q is not a phone number: unexpected %q", s, r)
+		}
+	}
+
+	n := digits.String()
+	if !international && strings.HasPrefix(n, "00") {
+		n, international = n[2:], true
+	}
+	if len(n) < minPhoneDigits || len(n) > maxPhoneDigits {
+		return "", fmt.Errorf("%q is not a phone number: it must have %d to %d digits", s, minPhoneDigits, maxPhoneDigits)
+	}
+	if international {
+		n = "+" + n
+	}
+	if ext != "" {
+		n += ";ext=" + ext
+	}
+	return n, nil
+}
+
+// MaskEmail hides the local part of an email address but its first
+// character, keeping the domain
+func MaskEmail(s string) string {
+	local, domain, ok := strings.Cut(s, "@")
+	if !ok {
+		return Mask(s)
+	}
+	return firstRune(local) + Masked + "@" + domain
+}
+
+// MaskPhone hides all but the last four digits of a phone number
+func MaskPhone(s string) string {
+	number, _, _ := strings.Cut(s, ";")
+	var digits []rune
+	for _, r := range number {
+		if unicode.IsDigit(r) {
+			digits = append(digits, r)
+		}
+	}
+	if len(digits) <= 4 {
+		return Masked
+	}
+	return Masked + string(digits[len(digits)-4:])
+}
+
+// MaskName reduces a name to its initials
+func MaskName(s string) string {
+	var initials []string
+	for _, word := range strings.Fields(s) {
+		initials = append(initials, firstRune(word)+".")
+	}
+	return strings.Join(initials, " ")
+}
+
+// Mask hides a value entirely, keeping only whether it was set
+func Mask(s string) string {
+	if s == "" {
+		return ""
+	}
+	return Masked
+}
+
+// firstRune returns the first character of s
+func firstRune(s string) string {
+	for _, r := range s {
+		return string(r)
+	}
+	return ""
+}
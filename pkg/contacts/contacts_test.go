@@ -0,0 +1,58 @@
+package contacts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	for in, want := range map[string]string{
+		"jane.doe@example.com":       "jane.doe@example.com",
+		"  Jane.Doe@Example.COM ":    "jane.doe@example.com",
+		"buyer+parts@sub.acme.co.uk": "buyer+parts@sub.acme.co.uk",
+	} {
+		got, err := NormalizeEmail(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got)
+	}
+
+	for _, in := range []string{"", "jane", "jane@", "@example.com", "jane@localhost", "Jane <jane@example.com>", "a@b@example.com"} {
+		_, err := NormalizeEmail(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	for in, want := range map[string]string{
+		"(555) 123-4567":          "5551234567",
+		"555.123.4567":            "5551234567",
+		"+1 555 123 4567":         "+15551234567",
+		"0044 20 7946 0958":       "+442079460958",
+		"+1 (555) 123-4567 x89":   "+15551234567;ext=89",
+		"555-123-4567 ext. 12":    "5551234567;ext=12",
+		"+15551234567;ext=3":      "+15551234567;ext=3",
+		"+49 (0)30 1234567 #4411": "+49301234567;ext=4411",
+	} {
+		got, err := NormalizePhone(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"", "12345", "call me", "555-CALL-NOW", "1+5551234567", "+1234567890123456"} {
+		_, err := NormalizePhone(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestMask(t *testing.T) {
+	assert.Equal(t, "j***@example.com", MaskEmail("jane.doe@example.com"))
+	assert.Equal(t, "***", MaskEmail("not an email"))
+	assert.Equal(t, "***4567", MaskPhone("+15551234567;ext=89"))
+	assert.Equal(t, "***", MaskPhone("123"))
+	assert.Equal(t, "J. D.", MaskName("Jane  Doe"))
+	assert.Equal(t, "", MaskName(""))
+	assert.Equal(t, "***", Mask("1 Main St"))
+	assert.Equal(t, "", Mask(""))
+}
@@ -0,0 +1,82 @@
+// Package dates parses the date formats found in First Resonance records.
+//
+// Dates entered by people and imported from other systems arrive in many
+// shapes. Parse accepts ISO 8601 dates and timestamps, slash-separated dates
+// (month first, as in the US), written-out month names and Unix timestamps.
+package dates
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// layouts are the formats Parse tries, in order
+var layouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	time.DateOnly,
+	"2006/01/02",
+	"20060102",
+	"1/2/2006",
+	"1/2/06",
+	"1-2-2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	"Jan 2 2006",
+	"January 2 2006",
+	"Mon Jan 2 2006",
+	"Monday January 2 2006",
+}
+
+// rawLayouts are the formats with commas, tried before commas are dropped
+var rawLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// Parse parses a date or timestamp. Timestamps without a zone are taken as
+// UTC. Outside RFC 1123 timestamps, commas are ignored, so "Jan 2, 2006"
+// parses.
+func Parse(s string) (time.Time, error) {
+	for _, layout := range rawLayouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t, nil
+		}
+	}
+	value := strings.Join(strings.Fields(strings.ReplaceAll(s, ",", " ")), " ")
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	// Unix timestamps in seconds or milliseconds
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil && len(value) >= 9 {
+		if len(value) >= 13 {
+			return time.UnixMilli(n).UTC(), nil
+		}
+		return time.Unix(n, 0).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", s)
+}
+
+// Day returns the calendar day of t in its own location, as midnight UTC, so
+// days can be compared and subtracted
+func Day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// DaysBetween returns the whole calendar days from a to b, negative if b is
+// before a
+func DaysBetween(a, b time.Time) int {
+	return int(Day(b).Sub(Day(a)).Hours() / 24)
+}
@@ -0,0 +1,69 @@
+package dates
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	want := time.Date(2026, time.March, 4, 0, 0, 0, 0, time.UTC)
+	for _, s := range []string{
+		"2026-03-04",
+		"2026/03/04",
+		"20260304",
+		"3/4/2026",
+		"03/04/2026",
+		"3/4/26",
+		"4 Mar 2026",
+		"4 March 2026",
+		"Mar 4, 2026",
+		"March 4 2026",
+		"Wednesday, March 4, 2026",
+		"  2026-03-04 ",
+	} {
+		got, err := Parse(s)
+		require.NoError(t, err, s)
+		assert.True(t, want.Equal(got), "%s parsed as %s", s, got)
+	}
+
+	got, err := Parse("2026-03-04T15:30:00-07:00")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.March, 4, 22, 30, 0, 0, time.UTC), got.UTC())
+
+	got, err = Parse("2026-03-04 15:30")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.March, 4, 15, 30, 0, 0, time.UTC), got)
+
+	got, err = Parse("Wed, 04 Mar 2026 15:30:00 -0700")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.March, 4, 22, 30, 0, 0, time.UTC), got.UTC())
+
+	got, err = Parse("Wed, 04 Mar 2026 15:30:00 UTC")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.March, 4, 15, 30, 0, 0, time.UTC), got.UTC())
+
+	got, err = Parse("1772582400")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	got, err = Parse("1772582400000")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	for _, s := range []string{"", "soon", "13/45/2026", "42"} {
+		_, err := Parse(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestDaysBetween(t *testing.T) {
+	a := time.Date(2026, time.March, 4, 23, 0, 0, 0, time.UTC)
+	b := time.Date(2026, time.March, 6, 1, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 2, DaysBetween(a, b))
+	assert.Equal(t, -2, DaysBetween(b, a))
+	assert.Equal(t, 0, DaysBetween(a, a.Add(30*time.Minute)))
+}
@@ -0,0 +1,319 @@
+// Package dedupe finds likely duplicate records, such as parts entered twice
+// under differently written names.
+package dedupe
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Record is a record to compare
+type Record struct {
+	ID          string
+	Name        string
+	Description string
+}
+
+// Match is a pair of likely duplicate records
+type Match struct {
+	A          string  `json:"a"`
+	B          string  `json:"b"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Cluster is a group of records that are likely duplicates of each other
+type Cluster struct {
+	IDs []string `json:"ids"`
+	// Confidence is the highest confidence of the matches in the cluster
+	Confidence float64 `json:"confidence"`
+	Matches    []Match `json:"matches"`
+}
+
+// numberMismatchPenalty scales the similarity of records whose numbers differ,
+// so that M3x8 and M3x10 are not taken for the same part
+const numberMismatchPenalty = 0.5
+
+// abbreviations expands common abbreviations to their words
+var abbreviations = map[string]string{
+	"shcs":      "socket head cap screw",
+	"bhcs":      "button head cap screw",
+	"fhcs":      "flat head cap screw",
+	"scs":       "socket cap screw",
+	"hhcs":      "hex head cap screw",
+	"scr":       "screw",
+	"blt":       "bolt",
+	"wshr":      "washer",
+	"brkt":      "bracket",
+	"assy":      "assembly",
+	"asm":       "assembly",
+	"ss":        "stainless steel",
+	"sst":       "stainless steel",
+	"al":        "aluminum",
+	"alu":       "aluminum",
+	"alum":      "aluminum",
+	"aluminium": "aluminum",
+	"dia":       "diameter",
+	"thk":       "thick",
+	"lg":        "long",
+	"hd":        "head",
+	"skt":       "socket",
+	"pcb":       "circuit board",
+	"pcba":      "circuit board assembly",
+}
+
+// units maps unit spellings to a canonical symbol
+var units = map[string]string{
+	"mm":          "mm",
+	"millimeter":  "mm",
+	"millimeters": "mm",
+	"millimetre":  "mm",
+	"millimetres": "mm",
+	"cm":          "cm",
+	"in":          "in",
+	"inch":        "in",
+	"inches":      "in",
+	"ft":          "ft",
+	"feet":        "ft",
+	"foot":        "ft",
+	"kg":          "kg",
+	"g":           "g",
+	"gram":        "g",
+	"grams":       "g",
+	"lb":          "lb",
+	"lbs":         "lb",
+	"v":           "v",
+	"volt":        "v",
+	"volts":       "v",
+}
+
+// stopwords carry no meaning in part names
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "for": true, "of": true, "the": true, "with": true, "w": true,
+}
+
+var (
+	// dimensionRe matches dimensions such as "M3 x 8" so they can be joined
+	dimensionRe = regexp.MustCompile(`(\d)\s*[x×*]\s*(\d)`)
+	// quantityRe matches a number followed by a unit, such as "10 mm"
+	quantityRe = regexp.MustCompile(`(\d)\s+([a-z]+)\b`)
+	// inchRe matches the inch mark after a number
+	inchRe = regexp.MustCompile(`(\d)"`)
+)
+
+// Normalize returns the canonical terms of text: lowercased, with
+// abbreviations expanded, units and dimensions written one way, stopwords
+// removed and plurals made singular.
+func Normalize(text string) []string {
+	text = strings.ToLower(text)
+	text = inchRe.ReplaceAllString(text, "${1}in")
+	text = dimensionRe.ReplaceAllString(text, "${1}x${2}")
+	text = quantityRe.ReplaceAllStringFunc(text, func(m string) string {
+		parts := quantityRe.FindStringSubmatch(m)
+		if unit, ok := units[parts[2]]; ok {
+			return parts[1] + unit
+		}
+		return m
+	})
+
+	var terms []string
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.'
+	}) {
+		word = strings.Trim(word, ".")
+		if word == "" || stopwords[word] {
+			continue
+		}
+		if expanded, ok := abbreviations[word]; ok {
+			terms = append(terms, strings.Fields(expanded)...)
+			continue
+		}
+		if unit, ok := units[word]; ok {
+			word = unit
+		}
+		terms = append(terms, singular(word))
+	}
+	return terms
+}
+
+// singular strips a plural s from longer words
+func singular(word string) string {
+	if len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !hasDigit(word) {
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+func hasDigit(s string) bool {
+	return strings.IndexFunc(s, unicode.IsDigit) >= 0
+}
+
+// normalized is a record's normalized terms
+type normalized struct {
+	name    map[string]bool
+	all     map[string]bool
+	numbers map[string]bool
+}
+
+func normalize(r Record) *normalized {
+	n := &normalized{name: map[string]bool{}, all: map[string]bool{}, numbers: map[string]bool{}}
+	for _, t := range Normalize(r.Name) {
+		n.name[t] = true
+		n.all[t] = true
+	}
+	for _, t := range Normalize(r.Description) {
+		n.all[t] = true
+	}
+	for t := range n.all {
+		if hasDigit(t) {
+			n.numbers[t] = true
+		}
+	}
+	return n
+}
+
+// Similarity returns a confidence between 0 and 1 that two records are the same
+func Similarity(a, b Record) float64 {
+	return similarity(normalize(a), normalize(b))
+}
+
+// similarity is the Dice coefficient of the records' name terms, or of all
+// their terms if that is higher, penalized when their numbers differ
+func similarity(a, b *normalized) float64 {
+	score := max(dice(a.name, b.name), dice(a.all, b.all))
+	if (len(a.numbers) > 0 || len(b.numbers) > 0) && !sameSet(a.numbers, b.numbers) {
+		score *= numberMismatchPenalty
+	}
+	return score
+}
+
+func dice(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range a {
+		if b[t] {
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(a)+len(b))
+}
+
+func sameSet(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for t := range a {
+		if !b[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// Similar returns the records that are likely duplicates of candidate, most
+// similar first
+func Similar(candidate Record, records []Record, threshold float64) []Match {
+	c := normalize(candidate)
+	matches := []Match{}
+	for _, r := range records {
+		if r.ID != "" && r.ID == candidate.ID {
+			continue
+		}
+		if score := similarity(c, normalize(r)); score >= threshold {
+			matches = append(matches, Match{A: candidate.ID, B: r.ID, Confidence: score})
+		}
+	}
+	sortMatches(matches)
+	return matches
+}
+
+// Find groups records into clusters of likely duplicates, joining any two
+// records at least threshold similar. Clusters are returned most confident first.
+func Find(records []Record, threshold float64) []Cluster {
+	norm := make([]*normalized, len(records))
+	byTerm := map[string][]int{}
+	for i, r := range records {
+		norm[i] = normalize(r)
+		for t := range norm[i].all {
+			byTerm[t] = append(byTerm[t], i)
+		}
+	}
+
+	// Union-find over the records, comparing only those sharing a term
+	parent := make([]int, len(records))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	var matches []Match
+	compared := map[[2]int]bool{}
+	for _, ids := range byTerm {
+		for x := 0; x < len(ids); x++ {
+			for y := x + 1; y < len(ids); y++ {
+				i, j := ids[x], ids[y]
+				if compared[[2]int{i, j}] {
+					continue
+				}
+				compared[[2]int{i, j}] = true
+				if score := similarity(norm[i], norm[j]); score >= threshold {
+					matches = append(matches, Match{A: records[i].ID, B: records[j].ID, Confidence: score})
+					parent[find(i)] = find(j)
+				}
+			}
+		}
+	}
+
+	index := map[string]int{}
+	for i, r := range records {
+		index[r.ID] = i
+	}
+	groups := map[int]*Cluster{}
+	for _, m := range matches {
+		root := find(index[m.A])
+		if groups[root] == nil {
+			groups[root] = &Cluster{}
+		}
+		groups[root].Matches = append(groups[root].Matches, m)
+		groups[root].Confidence = max(groups[root].Confidence, m.Confidence)
+	}
+	for i, r := range records {
+		if g := groups[find(i)]; g != nil {
+			g.IDs = append(g.IDs, r.ID)
+		}
+	}
+
+	clusters := make([]Cluster, 0, len(groups))
+	for _, g := range groups {
+		sortMatches(g.Matches)
+		clusters = append(clusters, *g)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Confidence != clusters[j].Confidence {
+			return clusters[i].Confidence > clusters[j].Confidence
+		}
+		return clusters[i].IDs[0] < clusters[j].IDs[0]
+	})
+	return clusters
+}
+
+func sortMatches(matches []Match) {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Confidence != matches[j].Confidence {
+			return matches[i].Confidence > matches[j].Confidence
+		}
+		if matches[i].A != matches[j].A {
+			return matches[i].A < matches[j].A
+		}
+		return matches[i].B < matches[j].B
+	})
+}
@@ -0,0 +1,64 @@
+package dedupe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"M3x8 SHCS", []string{"m3x8", "socket", "head", "cap", "screw"}},
+		{"Screw, socket head, M3 x 8", []string{"screw", "socket", "head", "m3x8"}},
+		{"Plate 10 millimeters thk, Al", []string{"plate", "10mm", "thick", "aluminum"}},
+		{`Rod 1/4" dia`, []string{"rod", "1", "4in", "diameter"}},
+		{"Washers for the M4 bolts", []string{"washer", "m4", "bolt"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.text, func(t *testing.T) {
+			assert.Equal(t, tc.want, Normalize(tc.text))
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.InDelta(t, 0.89, Similarity(Record{Name: "M3x8 SHCS"}, Record{Name: "Screw, socket head, M3 x 8"}), 0.01)
+	assert.Less(t, Similarity(Record{Name: "M3x8 SHCS"}, Record{Name: "M3x10 SHCS"}), 0.5, "different sizes are not duplicates")
+	assert.Equal(t, 0.0, Similarity(Record{Name: "Flange"}, Record{Name: "Bracket"}))
+}
+
+func TestFind(t *testing.T) {
+	records := []Record{
+		{ID: "p1", Name: "M3x8 SHCS"},
+		{ID: "p2", Name: "Screw, socket head, M3 x 8"},
+		{ID: "p3", Name: "M3x10 SHCS"},
+		{ID: "p4", Name: "Bracket, Al 6061"},
+		{ID: "p5", Name: "Aluminum bracket 6061"},
+		{ID: "p6", Name: "Socket head cap screws M3x8"},
+		{ID: "p7", Name: "Hex nut M3"},
+	}
+
+	clusters := Find(records, 0.8)
+
+	require.Len(t, clusters, 2)
+	assert.Equal(t, []string{"p1", "p2", "p6"}, clusters[0].IDs)
+	assert.Equal(t, 1.0, clusters[0].Confidence)
+	assert.Equal(t, Match{A: "p1", B: "p6", Confidence: 1}, clusters[0].Matches[0])
+	assert.Equal(t, []string{"p4", "p5"}, clusters[1].IDs)
+}
+
+func TestSimilar(t *testing.T) {
+	records := []Record{
+		{ID: "p1", Name: "M3x8 SHCS"},
+		{ID: "p3", Name: "M3x10 SHCS"},
+	}
+
+	matches := Similar(Record{Name: "Socket head cap screw, M3 x 8"}, records, 0.8)
+
+	require.Len(t, matches, 1)
+	assert.Equal(t, "p1", matches[0].B)
+}
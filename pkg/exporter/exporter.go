@@ -0,0 +1,178 @@
+// Package exporter writes records as CSV, JSON Lines or Parquet.
+//
+// Records are written one at a time as they are read, so a full result set
+// never has to be held in memory. Every format writes the same columns, in
+// the order given; values that are not scalars, such as lists of order items,
+// are written as JSON text to CSV and Parquet.
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Output formats
+const (
+	FormatCSV     = "csv"
+	FormatJSONL   = "jsonl"
+	FormatParquet = "parquet"
+)
+
+// Formats are the supported output formats
+var Formats = []string{FormatCSV, FormatJSONL, FormatParquet}
+
+// Type is the type of a column's values
+type Type int
+
+// Column types
+const (
+	String Type = iota
+	Int
+	Float
+	Bool
+)
+
+// Column is a named, typed column of the output
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Record is a row to write, keyed by column name. Missing and nil values are
+// written as empty or null.
+type Record map[string]interface{}
+
+// Writer writes records in a format
+type Writer interface {
+	// Write writes a record
+	Write(record Record) error
+	// Close flushes anything buffered and finishes the output. It does not
+	// close the underlying io.Writer.
+	Close() error
+}
+
+// NewWriter returns a Writer of the given format writing columns to w
+func NewWriter(w io.Writer, format string, columns []Column) (Writer, error) {
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns to write")
+	}
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatJSONL:
+		return newJSONLWriter(w, columns), nil
+	case FormatParquet:
+		return newParquetWriter(w, columns)
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// Select returns the columns with the given names, in that order, or all
+// columns if names is empty
+func Select(columns []Column, names []string) ([]Column, error) {
+	if len(names) == 0 {
+		return columns, nil
+	}
+
+	byName := make(map[string]Column, len(columns))
+	available := make([]string, len(columns))
+	for i, c := range columns {
+		byName[c.Name] = c
+		available[i] = c.Name
+	}
+	selected := make([]Column, 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		c, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q, expected one of %s", name, strings.Join(available, ", "))
+		}
+		if !seen[name] {
+			seen[name] = true
+			selected = append(selected, c)
+		}
+	}
+	return selected, nil
+}
+
+// stringValue converts a value for a String column. Values that are not
+// strings are written as JSON.
+func stringValue(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// intValue converts a value for an Int column
+func intValue(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case float64:
+		if n != math.Trunc(n) {
+			return 0, fmt.Errorf("%v is not a whole number", n)
+		}
+		return int64(n), nil
+	case json.Number:
+		return n.Int64()
+	default:
+		return 0, fmt.Errorf("cannot write %T as an integer", v)
+	}
+}
+
+// floatValue converts a value for a Float column
+func floatValue(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case json.Number:
+		return n.Float64()
+	default:
+		return 0, fmt.Errorf("cannot write %T as a number", v)
+	}
+}
+
+// boolValue converts a value for a Bool column
+func boolValue(v interface{}) (bool, error) {
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("cannot write %T as a boolean", v)
+	}
+	return b, nil
+}
+
+// formatValue formats a value of a column as text, with nil as ""
+func formatValue(c Column, v interface{}) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	switch c.Type {
+	case Int:
+		n, err := intValue(v)
+		return strconv.FormatInt(n, 10), err
+	case Float:
+		f, err := floatValue(v)
+		return strconv.FormatFloat(f, 'f', -1, 64), err
+	case Bool:
+		b, err := boolValue(v)
+		return strconv.FormatBool(b), err
+	default:
+		return stringValue(v)
+	}
+}
@@ -0,0 +1,139 @@
+package exporter
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testColumns = []Column{
+	{Name: "id", Type: String},
+	{Name: "quantity", Type: Int},
+	{Name: "cost", Type: Float},
+	{Name: "active", Type: Bool},
+	{Name: "items", Type: String},
+}
+
+var testRecords = []Record{
+	{"id": "p1", "quantity": float64(3), "cost": 1.5, "active": true, "items": []interface{}{"a", "b"}},
+	{"id": "p,2", "cost": float64(2), "active": false},
+	{"id": "p3", "quantity": float64(7), "active": true, "extra": "ignored"},
+}
+
+func export(t *testing.T, format string, columns []Column, records []Record) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, format, columns)
+	require.NoError(t, err)
+	for _, r := range records {
+		require.NoError(t, w.Write(r))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestSelect(t *testing.T) {
+	all, err := Select(testColumns, nil)
+	require.NoError(t, err)
+	assert.Equal(t, testColumns, all)
+
+	selected, err := Select(testColumns, []string{"quantity", "id", "quantity"})
+	require.NoError(t, err)
+	assert.Equal(t, []Column{{Name: "quantity", Type: Int}, {Name: "id", Type: String}}, selected)
+
+	_, err = Select(testColumns, []string{"color"})
+	assert.ErrorContains(t, err, `unknown field "color"`)
+}
+
+func TestCSV(t *testing.T) {
+	out := export(t, FormatCSV, testColumns, testRecords)
+
+	assert.Equal(t, "id,quantity,cost,active,items\n"+
+		"p1,3,1.5,true,\"[\"\"a\"\",\"\"b\"\"]\"\n"+
+		"\"p,2\",,2,false,\n"+
+		"p3,7,,true,\n", string(out))
+
+	w, err := NewWriter(&bytes.Buffer{}, FormatCSV, testColumns)
+	require.NoError(t, err)
+	assert.ErrorContains(t, w.Write(Record{"quantity": 1.5}), "field quantity: 1.5 is not a whole number")
+}
+
+func TestJSONL(t *testing.T) {
+	out := export(t, FormatJSONL, testColumns[:2], testRecords)
+
+	assert.Equal(t, `{"id":"p1","quantity":3}`+"\n"+
+		`{"id":"p,2","quantity":null}`+"\n"+
+		`{"id":"p3","quantity":7}`+"\n", string(out))
+}
+
+func TestUnknownFormat(t *testing.T) {
+	_, err := NewWriter(&bytes.Buffer{}, "xml", testColumns)
+	assert.ErrorContains(t, err, `unknown format "xml"`)
+}
+
+func TestParquet(t *testing.T) {
+	out := export(t, FormatParquet, testColumns, testRecords)
+
+	f, err := parquet.OpenFile(bytes.NewReader(out), int64(len(out)))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), f.NumRows())
+	fields := f.Schema().Fields()
+	require.Len(t, fields, len(testColumns))
+	for i, c := range testColumns {
+		assert.Equal(t, c.Name, fields[i].Name(), "columns are in the order given")
+		assert.True(t, fields[i].Optional(), c.Name)
+	}
+	assert.Equal(t, parquet.ByteArray, fields[0].Type().Kind())
+	assert.NotNil(t, fields[0].Type().LogicalType().UTF8, "strings are annotated as text")
+	assert.Equal(t, parquet.Int64, fields[1].Type().Kind())
+	assert.Equal(t, parquet.Double, fields[2].Type().Kind())
+	assert.Equal(t, parquet.Boolean, fields[3].Type().Kind())
+
+	rows := make([]parquet.Row, 3)
+	n, err := f.RowGroups()[0].Rows().ReadRows(rows)
+	require.Equal(t, 3, n)
+	if err != io.EOF {
+		require.NoError(t, err)
+	}
+	values := func(row parquet.Row) []interface{} {
+		var vs []interface{}
+		for _, v := range row {
+			switch {
+			case v.IsNull():
+				vs = append(vs, nil)
+			case v.Kind() == parquet.ByteArray:
+				vs = append(vs, v.String())
+			case v.Kind() == parquet.Int64:
+				vs = append(vs, v.Int64())
+			case v.Kind() == parquet.Double:
+				vs = append(vs, v.Double())
+			case v.Kind() == parquet.Boolean:
+				vs = append(vs, v.Boolean())
+			}
+		}
+		return vs
+	}
+	assert.Equal(t, []interface{}{"p1", int64(3), 1.5, true, `["a","b"]`}, values(rows[0]))
+	assert.Equal(t, []interface{}{"p,2", nil, 2.0, false, nil}, values(rows[1]))
+	assert.Equal(t, []interface{}{"p3", int64(7), nil, true, nil}, values(rows[2]))
+
+	w, err := NewWriter(&bytes.Buffer{}, FormatParquet, testColumns)
+	require.NoError(t, err)
+	assert.ErrorContains(t, w.Write(Record{"quantity": 1.5}), "field quantity: 1.5 is not a whole number")
+}
+
+func TestParquetRowGroups(t *testing.T) {
+	records := make([]Record, parquetRowGroupSize+1)
+	for i := range records {
+		records[i] = Record{"id": "p"}
+	}
+	out := export(t, FormatParquet, testColumns[:1], records)
+
+	f, err := parquet.OpenFile(bytes.NewReader(out), int64(len(out)))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(records)), f.NumRows())
+	assert.Len(t, f.RowGroups(), 2)
+}
@@ -0,0 +1,96 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetRowGroupSize is how many records are written to each row group
+const parquetRowGroupSize = 10000
+
+// parquetWriter writes an uncompressed Parquet file with a flat schema of
+// optional columns, in the order given
+type parquetWriter struct {
+	w       *parquet.Writer
+	columns []Column
+	rows    []parquet.Row
+}
+
+func newParquetWriter(w io.Writer, columns []Column) (*parquetWriter, error) {
+	schema, err := parquetSchema(columns)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetWriter{
+		w:       parquet.NewWriter(w, schema, parquet.MaxRowsPerRowGroup(parquetRowGroupSize)),
+		columns: columns,
+		rows:    make([]parquet.Row, 1),
+	}, nil
+}
+
+func (pw *parquetWriter) Write(record Record) error {
+	row := pw.rows[0][:0]
+	for i, c := range pw.columns {
+		v := record[c.Name]
+		if v == nil {
+			row = append(row, parquet.NullValue().Level(0, 0, i))
+			continue
+		}
+		var value interface{}
+		var err error
+		switch c.Type {
+		case Int:
+			value, err = intValue(v)
+		case Float:
+			value, err = floatValue(v)
+		case Bool:
+			value, err = boolValue(v)
+		default:
+			value, err = stringValue(v)
+		}
+		if err != nil {
+			return fmt.Errorf("field %s: %w", c.Name, err)
+		}
+		row = append(row, parquet.ValueOf(value).Level(0, 1, i))
+	}
+	pw.rows[0] = row
+	_, err := pw.w.WriteRows(pw.rows)
+	return err
+}
+
+func (pw *parquetWriter) Close() error {
+	return pw.w.Close()
+}
+
+// parquetSchema returns the schema of columns. It is built from a struct
+// type rather than a parquet.Group, which would sort the columns by name.
+func parquetSchema(columns []Column) (schema *parquet.Schema, err error) {
+	fields := make([]reflect.StructField, len(columns))
+	for i, c := range columns {
+		var t reflect.Type
+		switch c.Type {
+		case Int:
+			t = reflect.TypeFor[int64]()
+		case Float:
+			t = reflect.TypeFor[float64]()
+		case Bool:
+			t = reflect.TypeFor[bool]()
+		default:
+			t = reflect.TypeFor[string]()
+		}
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("Column%d", i),
+			Type: reflect.PointerTo(t),
+			Tag:  reflect.StructTag(fmt.Sprintf("parquet:%q", c.Name+",optional")),
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid columns: %v", r)
+		}
+	}()
+	return parquet.SchemaOf(reflect.New(reflect.StructOf(fields)).Interface()), nil
+}
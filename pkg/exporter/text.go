@@ -0,0 +1,83 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// csvWriter writes a header row and then a row per record
+type csvWriter struct {
+	w       *csv.Writer
+	columns []Column
+	row     []string
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), columns: columns, row: make([]string, len(columns))}
+	for i, c := range columns {
+		cw.row[i] = c.Name
+	}
+	if err := cw.w.Write(cw.row); err != nil {
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(record Record) error {
+	for i, c := range cw.columns {
+		s, err := formatValue(c, record[c.Name])
+		if err != nil {
+			return fmt.Errorf("field %s: %w", c.Name, err)
+		}
+		cw.row[i] = s
+	}
+	return cw.w.Write(cw.row)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// jsonlWriter writes a JSON object per record with its keys in column
+// order, keeping nested values as JSON
+type jsonlWriter struct {
+	w       *bufio.Writer
+	columns []Column
+	keys    [][]byte
+}
+
+func newJSONLWriter(w io.Writer, columns []Column) *jsonlWriter {
+	jw := &jsonlWriter{w: bufio.NewWriter(w), columns: columns, keys: make([][]byte, len(columns))}
+	for i, c := range columns {
+		// Marshaling a string cannot fail
+		jw.keys[i], _ = json.Marshal(c.Name)
+	}
+	return jw
+}
+
+func (jw *jsonlWriter) Write(record Record) error {
+	line := []byte{'{'}
+	for i, c := range jw.columns {
+		value, err := json.Marshal(record[c.Name])
+		if err != nil {
+			return fmt.Errorf("field %s: %w", c.Name, err)
+		}
+		if i > 0 {
+			line = append(line, ',')
+		}
+		line = append(line, jw.keys[i]...)
+		line = append(line, ':')
+		line = append(line, value...)
+	}
+	line = append(line, '}', '\n')
+	_, err := jw.w.Write(line)
+	return err
+}
+
+func (jw *jsonlWriter) Close() error {
+	return jw.w.Flush()
+}
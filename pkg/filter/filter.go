@@ -0,0 +1,264 @@
+// Package filter parses and evaluates filter expressions such as
+//
+//	status in (active, draft) and due_date < 2026-11-01 and priority = high
+//
+// Conditions compare a field with a value using =, !=, <, <=, >, >=, in,
+// not in or contains, and combine with and, or, not and parentheses.
+// Values are bare words or quoted strings.
+package filter
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/dates"
+)
+
+// Type is the type of a field, which decides how its values are compared
+type Type int
+
+// Field types
+const (
+	// String fields compare case-insensitively
+	String Type = iota
+	Number
+	// Date fields take values in any format dates.Parse accepts
+	Date
+)
+
+// Fields maps the names of the fields an expression may use to their types
+type Fields map[string]Type
+
+// names returns the field names in order
+func (f Fields) names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Filter is a parsed filter expression
+type Filter struct {
+	expr string
+	root node
+}
+
+// Parse parses a filter expression, validating it against fields
+func Parse(expr string, fields Fields) (*Filter, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, fields: fields}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+	return &Filter{expr: expr, root: root}, nil
+}
+
+// String returns the expression the filter was parsed from
+func (f *Filter) String() string {
+	return f.expr
+}
+
+// Match reports whether a record, keyed by field name, satisfies the filter
+func (f *Filter) Match(record map[string]interface{}) bool {
+	return f.root.match(record)
+}
+
+// Equalities returns the field values that every matching record must equal,
+// from conditions joined to the rest of the expression by and. They can be
+// used to narrow a query before the filter is applied.
+func (f *Filter) Equalities() map[string]string {
+	values := map[string]string{}
+	conflicts := map[string]bool{}
+	var walk func(n node)
+	walk = func(n node) {
+		switch n := n.(type) {
+		case *andNode:
+			walk(n.left)
+			walk(n.right)
+		case *comparison:
+			if n.op != opEq && (n.op != opIn || len(n.values) != 1) {
+				return
+			}
+			if v, ok := values[n.field]; ok && !strings.EqualFold(v, n.values[0].raw) {
+				conflicts[n.field] = true
+			}
+			values[n.field] = n.values[0].raw
+		}
+	}
+	walk(f.root)
+	for field := range conflicts {
+		delete(values, field)
+	}
+	return values
+}
+
+// node is a node of a parsed expression
+type node interface {
+	match(record map[string]interface{}) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n *andNode) match(r map[string]interface{}) bool { return n.left.match(r) && n.right.match(r) }
+
+type orNode struct{ left, right node }
+
+func (n *orNode) match(r map[string]interface{}) bool { return n.left.match(r) || n.right.match(r) }
+
+type notNode struct{ operand node }
+
+func (n *notNode) match(r map[string]interface{}) bool { return !n.operand.match(r) }
+
+// Comparison operators
+const (
+	opEq       = "="
+	opNe       = "!="
+	opLt       = "<"
+	opLe       = "<="
+	opGt       = ">"
+	opGe       = ">="
+	opIn       = "in"
+	opNotIn    = "not in"
+	opContains = "contains"
+)
+
+// value is a literal in an expression, parsed for its field's type
+type value struct {
+	raw  string
+	num  float64
+	date time.Time
+}
+
+// comparison compares a field of the record with one or more values
+type comparison struct {
+	field  string
+	typ    Type
+	op     string
+	values []value
+}
+
+func (c *comparison) match(r map[string]interface{}) bool {
+	actual, ok := recordValue(r[c.field], c.typ)
+	if !ok {
+		// Missing values only satisfy negative conditions
+		return c.op == opNe || c.op == opNotIn
+	}
+
+	switch c.op {
+	case opIn:
+		for _, v := range c.values {
+			if compare(actual, v, c.typ) == 0 {
+				return true
+			}
+		}
+		return false
+	case opNotIn:
+		for _, v := range c.values {
+			if compare(actual, v, c.typ) == 0 {
+				return false
+			}
+		}
+		return true
+	case opContains:
+		return strings.Contains(strings.ToLower(actual.raw), strings.ToLower(c.values[0].raw))
+	}
+
+	cmp := compare(actual, c.values[0], c.typ)
+	switch c.op {
+	case opEq:
+		return cmp == 0
+	case opNe:
+		return cmp != 0
+	case opLt:
+		return cmp < 0
+	case opLe:
+		return cmp <= 0
+	case opGt:
+		return cmp > 0
+	case opGe:
+		return cmp >= 0
+	}
+	return false
+}
+
+// compare returns -1, 0 or 1 as a is less than, equal to or greater than b
+func compare(a, b value, typ Type) int {
+	switch typ {
+	case Number:
+		switch {
+		case a.num < b.num:
+			return -1
+		case a.num > b.num:
+			return 1
+		}
+		return 0
+	case Date:
+		return a.date.Compare(b.date)
+	default:
+		return strings.Compare(strings.ToLower(a.raw), strings.ToLower(b.raw))
+	}
+}
+
+// recordValue converts a record's value for comparison, reporting false if
+// it is missing or not of the field's type
+func recordValue(v interface{}, typ Type) (value, bool) {
+	var raw string
+	switch v := v.(type) {
+	case nil:
+		return value{}, false
+	case string:
+		raw = v
+	case float64:
+		if typ == Number {
+			return value{raw: strconv.FormatFloat(v, 'f', -1, 64), num: v}, true
+		}
+		raw = strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		if typ == Number {
+			return value{raw: strconv.Itoa(v), num: float64(v)}, true
+		}
+		raw = strconv.Itoa(v)
+	case bool:
+		raw = strconv.FormatBool(v)
+	default:
+		raw = fmt.Sprint(v)
+	}
+	if raw == "" {
+		return value{}, false
+	}
+	parsed, err := parseValue(raw, typ)
+	return parsed, err == nil
+}
+
+// parseValue parses a literal for a field's type
+func parseValue(raw string, typ Type) (value, error) {
+	v := value{raw: raw}
+	switch typ {
+	case Number:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return v, fmt.Errorf("%q is not a number", raw)
+		}
+		v.num = n
+	case Date:
+		t, err := dates.Parse(raw)
+		if err != nil {
+			return v, fmt.Errorf("%q is not a date", raw)
+		}
+		v.date = t
+	}
+	return v, nil
+}
@@ -0,0 +1,95 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var orderFields = Fields{
+	"id":       String,
+	"status":   String,
+	"priority": String,
+	"due_date": Date,
+	"quantity": Number,
+}
+
+func TestMatch(t *testing.T) {
+	record := map[string]interface{}{
+		"id":       "o1",
+		"status":   "Active",
+		"priority": "high",
+		"due_date": "2026-10-15",
+		"quantity": float64(12),
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"status in (active,draft) and due_date < 2026-11-01 and priority = high", true},
+		{"status in (draft, closed)", false},
+		{"status not in (draft, closed)", true},
+		{"status != active", false},
+		{"due_date >= 2026-10-15T00:00:00Z", true},
+		{"due_date = 10/15/2026", true},
+		{`due_date < "Oct 16, 2026"`, true},
+		{"due_date > 20261015", false},
+		{"quantity > 10 and quantity <= 12", true},
+		{"quantity = 12.5 or priority = 'high'", true},
+		{"not (priority = high or status = draft)", false},
+		{"id contains O", true},
+		{`priority = "low"`, false},
+		{"supplier_missing_ok = x", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.expr, func(t *testing.T) {
+			fields := Fields{"supplier_missing_ok": String}
+			for k, v := range orderFields {
+				fields[k] = v
+			}
+			f, err := Parse(tc.expr, fields)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, f.Match(record))
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"color = red", `unknown field "color", expected one of: due_date, id, priority, quantity, status`},
+		{"priority < high", "operator < needs a number or date field, priority is text"},
+		{"due_date < soon", `"soon" is not a date`},
+		{"quantity = lots", `"lots" is not a number`},
+		{"status in active", `expected "(" after in`},
+		{"status = active and", "expected a field name"},
+		{"(status = active", `expected ")"`},
+		{"status = 'active", "unterminated string"},
+		{"status = active priority = high", `unexpected "priority"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.expr, func(t *testing.T) {
+			_, err := Parse(tc.expr, orderFields)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.want)
+		})
+	}
+}
+
+func TestEqualities(t *testing.T) {
+	f, err := Parse("status = active and (priority = high or priority = low) and id in (o1) and quantity > 3", orderFields)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"status": "active", "id": "o1"}, f.Equalities())
+
+	f, err = Parse("status = active and status = draft", orderFields)
+	require.NoError(t, err)
+	assert.Empty(t, f.Equalities(), "conflicting values are not pushed down")
+
+	f, err = Parse("status = active or priority = high", orderFields)
+	require.NoError(t, err)
+	assert.Empty(t, f.Equalities())
+}
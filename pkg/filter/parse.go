@@ -0,0 +1,246 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOp
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// is reports whether the token is the given keyword
+func (t token) is(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+// isWordRune reports whether r can appear in a bare word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-.:/+", r)
+}
+
+// lex splits an expression into tokens
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+		case r == '=':
+			tokens = append(tokens, token{kind: tokenOp, text: opEq, pos: i})
+			i++
+		case r == '!' || r == '<' || r == '>':
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected \"!\" at position %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: i})
+			i += len(op)
+		case r == '"' || r == '\'':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				b.WriteRune(runes[j])
+			}
+			if j == len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: b.String(), pos: i})
+			i = j + 1
+		case isWordRune(r):
+			j := i
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[i:j]), pos: i})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", r, i)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, text: "end of filter", pos: len(runes)}), nil
+}
+
+// parser is a recursive descent parser over the grammar
+//
+//	or         = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" or ")" | comparison
+//	comparison = field op value | field [ "not" ] "in" "(" value { "," value } ")" | field "contains" value
+type parser struct {
+	tokens []token
+	pos    int
+	fields Fields
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	switch t := p.peek(); {
+	case t.is("not"):
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	case t.kind == tokenLParen:
+		p.next()
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenRParen {
+			return nil, fmt.Errorf("expected \")\" at position %d, got %q", t.pos, t.text)
+		}
+		return n, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	t := p.next()
+	if t.kind != tokenWord {
+		return nil, fmt.Errorf("expected a field name at position %d, got %q", t.pos, t.text)
+	}
+	field := strings.ToLower(t.text)
+	typ, ok := p.fields[field]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q, expected one of: %s", t.text, strings.Join(p.fields.names(), ", "))
+	}
+	c := &comparison{field: field, typ: typ}
+
+	switch t := p.next(); {
+	case t.kind == tokenOp:
+		c.op = t.text
+		if c.op != opEq && c.op != opNe && typ == String {
+			return nil, fmt.Errorf("operator %s needs a number or date field, %s is text", c.op, field)
+		}
+	case t.is("in"):
+		c.op = opIn
+	case t.is("not") && p.peek().is("in"):
+		p.next()
+		c.op = opNotIn
+	case t.is("contains"):
+		if typ != String {
+			return nil, fmt.Errorf("operator contains needs a text field, %s is not", field)
+		}
+		c.op = opContains
+	default:
+		return nil, fmt.Errorf("expected an operator after %s at position %d, got %q", field, t.pos, t.text)
+	}
+
+	if c.op != opIn && c.op != opNotIn {
+		v, err := p.parseValue(typ)
+		if err != nil {
+			return nil, err
+		}
+		c.values = []value{v}
+		return c, nil
+	}
+
+	if t := p.next(); t.kind != tokenLParen {
+		return nil, fmt.Errorf("expected \"(\" after %s at position %d, got %q", c.op, t.pos, t.text)
+	}
+	for {
+		v, err := p.parseValue(typ)
+		if err != nil {
+			return nil, err
+		}
+		c.values = append(c.values, v)
+
+		t := p.next()
+		if t.kind == tokenRParen {
+			return c, nil
+		}
+		if t.kind != tokenComma {
+			return nil, fmt.Errorf("expected \",\" or \")\" at position %d, got %q", t.pos, t.text)
+		}
+	}
+}
+
+func (p *parser) parseValue(typ Type) (value, error) {
+	t := p.next()
+	if t.kind != tokenWord && t.kind != tokenString {
+		return value{}, fmt.Errorf("expected a value at position %d, got %q", t.pos, t.text)
+	}
+	v, err := parseValue(t.text, typ)
+	if err != nil {
+		return value{}, fmt.Errorf("%w at position %d", err, t.pos)
+	}
+	return v, nil
+}
@@ -0,0 +1,100 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/dates"
+)
+
+// ABCAnalysisReport creates a tool to classify parts by annual usage value and flag slow moving and dead stock.
+func ABCAnalysisReport(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("abc_analysis",
+			mcp.WithDescription(t("TOOL_ABC_ANALYSIS_DESCRIPTION", "Classify parts into A, B and C by annual usage value, from consumption in the audit log and part costs, and flag stock on hand with no movement for slow_days (slow moving) or dead_days (dead). Returns a summary per class and a line per part, highest usage value first, to drive cycle count frequency and purge decisions")),
+			mcp.WithNumber("period_days",
+				mcp.Description(fmt.Sprintf("Days of consumption to annualize (default %d)", defaultABCPeriodDays)),
+				mcp.Min(1),
+			),
+			mcp.WithNumber("slow_days",
+				mcp.Description(fmt.Sprintf("Days without movement that make stock slow moving (default %d)", defaultSlowDays)),
+				mcp.Min(1),
+			),
+			mcp.WithNumber("dead_days",
+				mcp.Description(fmt.Sprintf("Days without movement that make stock dead (default %d)", defaultDeadDays)),
+				mcp.Min(1),
+			),
+			mcp.WithNumber("a_threshold",
+				mcp.Description(fmt.Sprintf("Cumulative share of annual usage value covered by class A (default %g)", defaultAThreshold)),
+				mcp.Min(0),
+				mcp.Max(1),
+			),
+			mcp.WithNumber("b_threshold",
+				mcp.Description(fmt.Sprintf("Cumulative share of annual usage value covered by classes A and B (default %g)", defaultBThreshold)),
+				mcp.Min(0),
+				mcp.Max(1),
+			),
+			mcp.WithString("cost_basis",
+				mcp.Description("Cost to value usage and stock at (default standard)"),
+				mcp.Enum(CostBasisStandard, CostBasisAverage),
+			),
+			mcp.WithString("as_of",
+				mcp.Description("Date to analyze as of (default today)"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			opts := &ABCAnalysisOptions{}
+			var err error
+			if opts.PeriodDays, err = OptionalIntParam(request, "period_days"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if opts.SlowDays, err = OptionalIntParam(request, "slow_days"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if opts.DeadDays, err = OptionalIntParam(request, "dead_days"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if opts.AThreshold, err = OptionalParam[float64](request, "a_threshold"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if opts.BThreshold, err = OptionalParam[float64](request, "b_threshold"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if opts.CostBasis, err = OptionalParam[string](request, "cost_basis"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			asOf, err := OptionalParam[string](request, "as_of")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if asOf != "" {
+				if opts.AsOf, err = dates.Parse(asOf); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid as_of: %s", err)), nil
+				}
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			analysis, err := client.Inventory.ABCAnalysis(ctx, opts)
+			if errors.Is(err, ErrInvalidABCAnalysis) || errors.Is(err, ErrInvalidCostBasis) || errors.Is(err, ErrAuditDisabled) ||
+				errors.Is(err, ErrTooManyResults) || isUnitError(err) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to run ABC analysis: %w", err)
+			}
+
+			r, err := json.Marshal(analysis)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
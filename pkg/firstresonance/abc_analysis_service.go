@@ -0,0 +1,318 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/audit"
+	"github.com/firstresonance/fr-mcp-server/pkg/dates"
+)
+
+// ErrInvalidABCAnalysis is returned for ABC analysis options out of range
+var ErrInvalidABCAnalysis = errors.New("invalid ABC analysis")
+
+// Defaults of an ABC analysis
+const (
+	defaultABCPeriodDays = 365
+	defaultSlowDays      = 90
+	defaultDeadDays      = 365
+	defaultAThreshold    = 0.8
+	defaultBThreshold    = 0.95
+)
+
+// ABCAnalysis classifies parts into A, B and C by annual usage value, the
+// quantity consumed over the period, annualized, times the part's unit cost,
+// and flags stock on hand that has not moved for a while. Consumption and
+// movement are read from the audit log: consumption adjustments count as
+// usage, and adjustments other than cycle counts, transfers and new stock
+// count as movement. Parts are ranked by annual usage value; A parts cover
+// the first AThreshold of the total, B parts the next up to BThreshold, and
+// the rest, along with parts with no usage or no cost, are C. A cost in
+// another unit than the part's stock counts as no cost. Only the audit log
+// of the longer of the period and DeadDays is read; stock not moved since
+// then is reported as still since its start.
+func (s *InventoryService) ABCAnalysis(ctx context.Context, opts *ABCAnalysisOptions) (*ABCAnalysis, error) {
+	o, err := abcOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	basis, err := costBasis(o.CostBasis)
+	if err != nil {
+		return nil, err
+	}
+	if !s.client.Audit.Enabled() {
+		return nil, fmt.Errorf("%w: consumption and movement are read from it", ErrAuditDisabled)
+	}
+	costs, err := s.client.Costs.Costs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.analysisInventory(ctx, &ListInventoryItemsOptions{})
+	if err != nil {
+		return nil, err
+	}
+	onHand := map[string]float64{}
+	for _, item := range items {
+		if item.PartID == "" || item.Quantity <= 0 {
+			continue
+		}
+		onHand[item.PartID] = s.client.Units.round(item.PartID, onHand[item.PartID]+item.Quantity)
+	}
+
+	// Only the window that decides usage and movement is read from the log.
+	// Stock last moved before it is dead however long ago that was.
+	windowStart := dates.Day(o.AsOf).AddDate(0, 0, -max(o.PeriodDays, o.DeadDays))
+	entries, err := s.client.Audit.Query(ctx, &audit.QueryOptions{
+		Entity:  auditEntityInventoryItem,
+		Outcome: audit.OutcomeSuccess,
+		Since:   windowStart,
+		Until:   dates.Day(o.AsOf).AddDate(0, 0, 1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading audit log: %w", err)
+	}
+	earlier, err := s.client.Audit.Query(ctx, &audit.QueryOptions{
+		Entity:  auditEntityInventoryItem,
+		Outcome: audit.OutcomeSuccess,
+		Until:   windowStart,
+		Limit:   1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading audit log: %w", err)
+	}
+	consumed := map[string]float64{}
+	lastMoved := map[string]time.Time{}
+	var logStart time.Time
+	if len(earlier) > 0 {
+		logStart = windowStart
+	}
+	for _, entry := range entries {
+		item := auditItem(entry.After)
+		if item == nil || item.PartID == "" {
+			item = auditItem(entry.Before)
+		}
+		if item == nil || item.PartID == "" {
+			continue
+		}
+		if logStart.IsZero() || entry.Timestamp.Before(logStart) {
+			logStart = entry.Timestamp
+		}
+
+		var moved bool
+		switch entry.Action {
+		case audit.ActionAdjust:
+			reason, _ := entry.Details["reason"].(string)
+			moved = reason != AdjustmentReasonCycleCount
+			delta, _ := entry.Details["delta"].(float64)
+			if reason == AdjustmentReasonConsumption && delta < 0 && dates.DaysBetween(entry.Timestamp, o.AsOf) < o.PeriodDays {
+				consumed[item.PartID] = s.client.Units.round(item.PartID, consumed[item.PartID]-delta)
+			}
+		case audit.ActionTransfer:
+			moved = true
+		case audit.ActionCreate:
+			moved = item.Quantity > 0
+		}
+		if moved && entry.Timestamp.After(lastMoved[item.PartID]) {
+			lastMoved[item.PartID] = entry.Timestamp
+		}
+	}
+
+	analysis := &ABCAnalysis{
+		AsOf:          o.AsOf.Format(time.DateOnly),
+		PeriodDays:    o.PeriodDays,
+		SlowDays:      o.SlowDays,
+		DeadDays:      o.DeadDays,
+		CostBasis:     basis,
+		Parts:         []*ABCPart{},
+		UncostedParts: []string{},
+	}
+	partIDs := map[string]bool{}
+	for partID := range onHand {
+		partIDs[partID] = true
+	}
+	for partID := range consumed {
+		partIDs[partID] = true
+	}
+	names, err := s.client.Parts.names(ctx, partIDs)
+	if err != nil {
+		return nil, err
+	}
+	var totalValue float64
+	for partID := range partIDs {
+		p := &ABCPart{
+			PartID:      partID,
+			OnHand:      onHand[partID],
+			Consumed:    consumed[partID],
+			AnnualUsage: s.client.Units.round(partID, consumed[partID]*365/float64(o.PeriodDays)),
+			PartName:    names[partID],
+		}
+		if _, unit, err := s.client.Units.StockQuantity(partID, 0, ""); err == nil {
+			p.Unit = unit
+		}
+		if cost, ok := s.client.Costs.stockUnitCost(costs, partID, p.Unit, basis); ok {
+			p.UnitCost = &cost
+			p.OnHandValue = roundMoney(cost * p.OnHand)
+			p.AnnualUsageValue = roundMoney(cost * p.AnnualUsage)
+		} else {
+			analysis.UncostedParts = append(analysis.UncostedParts, partID)
+		}
+		if p.OnHand > 0 {
+			p.LastMovement, p.DaysSinceMovement, p.Movement = movement(lastMoved[partID], logStart, o)
+		}
+		totalValue = roundMoney(totalValue + p.AnnualUsageValue)
+		analysis.Parts = append(analysis.Parts, p)
+	}
+	sort.Strings(analysis.UncostedParts)
+
+	sort.Slice(analysis.Parts, func(i, j int) bool {
+		a, b := analysis.Parts[i], analysis.Parts[j]
+		if a.AnnualUsageValue != b.AnnualUsageValue {
+			return a.AnnualUsageValue > b.AnnualUsageValue
+		}
+		if (a.UnitCost != nil) != (b.UnitCost != nil) {
+			return a.UnitCost != nil
+		}
+		if a.AnnualUsage != b.AnnualUsage {
+			return a.AnnualUsage > b.AnnualUsage
+		}
+		return a.PartID < b.PartID
+	})
+
+	classes := map[string]*ABCClassSummary{}
+	for _, class := range []string{ABCClassA, ABCClassB, ABCClassC} {
+		classes[class] = &ABCClassSummary{Class: class}
+		analysis.Classes = append(analysis.Classes, classes[class])
+	}
+	var cumulative float64
+	for _, p := range analysis.Parts {
+		// A part's class is set by the share of the parts ranked above it,
+		// so the part that crosses a threshold stays in the higher class
+		var above float64
+		if totalValue > 0 {
+			above = cumulative / totalValue
+			cumulative += p.AnnualUsageValue
+			p.UsageShare = roundShare(p.AnnualUsageValue / totalValue)
+			p.CumulativeShare = roundShare(cumulative / totalValue)
+		}
+		switch {
+		case p.AnnualUsageValue > 0 && above < o.AThreshold:
+			p.Class = ABCClassA
+		case p.AnnualUsageValue > 0 && above < o.BThreshold:
+			p.Class = ABCClassB
+		default:
+			p.Class = ABCClassC
+		}
+
+		summary := classes[p.Class]
+		summary.Parts++
+		summary.AnnualUsageValue = roundMoney(summary.AnnualUsageValue + p.AnnualUsageValue)
+		summary.OnHandValue = roundMoney(summary.OnHandValue + p.OnHandValue)
+		switch p.Movement {
+		case MovementSlowMoving:
+			analysis.SlowMoving++
+			analysis.SlowMovingValue = roundMoney(analysis.SlowMovingValue + p.OnHandValue)
+		case MovementDead:
+			analysis.Dead++
+			analysis.DeadValue = roundMoney(analysis.DeadValue + p.OnHandValue)
+		}
+	}
+	if totalValue > 0 {
+		for _, summary := range analysis.Classes {
+			summary.UsageShare = roundShare(summary.AnnualUsageValue / totalValue)
+		}
+	}
+
+	return analysis, nil
+}
+
+// abcOptions fills in the defaults of ABC analysis options and checks them
+func abcOptions(opts *ABCAnalysisOptions) (ABCAnalysisOptions, error) {
+	o := *opts
+	if o.AsOf.IsZero() {
+		o.AsOf = time.Now().UTC()
+	}
+	if o.PeriodDays == 0 {
+		o.PeriodDays = defaultABCPeriodDays
+	}
+	if o.SlowDays == 0 {
+		o.SlowDays = defaultSlowDays
+	}
+	if o.DeadDays == 0 {
+		o.DeadDays = defaultDeadDays
+	}
+	if o.AThreshold == 0 {
+		o.AThreshold = defaultAThreshold
+	}
+	if o.BThreshold == 0 {
+		o.BThreshold = defaultBThreshold
+	}
+
+	switch {
+	case o.PeriodDays < 0 || o.SlowDays < 0 || o.DeadDays < 0:
+		return o, fmt.Errorf("%w: period_days, slow_days and dead_days must be positive", ErrInvalidABCAnalysis)
+	case o.SlowDays > o.DeadDays:
+		return o, fmt.Errorf("%w: slow_days %d is more than dead_days %d", ErrInvalidABCAnalysis, o.SlowDays, o.DeadDays)
+	case o.AThreshold < 0 || o.AThreshold >= o.BThreshold || o.BThreshold > 1:
+		return o, fmt.Errorf("%w: thresholds must satisfy 0 < a_threshold < b_threshold <= 1", ErrInvalidABCAnalysis)
+	}
+	return o, nil
+}
+
+// movement returns when stock of a part last moved, how many days ago, and
+// its movement status. Stock never moved in the audit log has been still
+// since the log began, which flags it once the log is old enough; until
+// then its status is unknown.
+func movement(lastMoved, logStart time.Time, o ABCAnalysisOptions) (string, *int, string) {
+	var last string
+	since := lastMoved
+	if !lastMoved.IsZero() {
+		last = lastMoved.Format(time.DateOnly)
+	} else if logStart.IsZero() {
+		return "", nil, MovementUnknown
+	} else {
+		since = logStart
+	}
+
+	days := dates.DaysBetween(since, o.AsOf)
+	switch {
+	case days >= o.DeadDays:
+		return last, &days, MovementDead
+	case days >= o.SlowDays:
+		return last, &days, MovementSlowMoving
+	case lastMoved.IsZero():
+		return last, &days, MovementUnknown
+	default:
+		return last, &days, MovementActive
+	}
+}
+
+// auditItem reads the inventory item recorded before or after a change in
+// an audit entry, which holds it as a map once read back from the log
+func auditItem(v interface{}) *InventoryItem {
+	if item, ok := v.(*InventoryItem); ok {
+		return item
+	}
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var item InventoryItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil
+	}
+	return &item
+}
+
+// roundShare keeps a share to four decimal places
+func roundShare(share float64) float64 {
+	return math.Round(share*1e4) / 1e4
+}
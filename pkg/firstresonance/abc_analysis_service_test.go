@@ -0,0 +1,85 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/audit"
+)
+
+func TestABCAnalysis(t *testing.T) {
+	ctx := context.Background()
+	parts := []*Part{{ID: "x", Name: "Other"}, {ID: "a", Name: "Alpha"}, {ID: "b", Name: "Beta"}}
+	items := []*InventoryItem{{ID: "i1", PartID: "a", Quantity: 5}, {ID: "i2", PartID: "b", Quantity: 3}}
+	var gets atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string `json:"query"`
+			Variables struct {
+				Page    int `json:"page"`
+				PerPage int `json:"perPage"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		v := body.Variables
+		data := map[string]interface{}{}
+		switch {
+		case strings.Contains(body.Query, "GetPart"):
+			gets.Add(1)
+		case strings.Contains(body.Query, "ListParts"):
+			data["parts"] = pageOf(parts, v.Page, v.PerPage)
+		case strings.Contains(body.Query, "ListInventoryItems"):
+			data["inventoryItems"] = pageOf(items, v.Page, v.PerPage)
+		case strings.Contains(body.Query, "ListOrders"):
+			data["orders"] = []*Order{}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	t.Cleanup(server.Close)
+
+	sink := audit.NewFileSink(filepath.Join(t.TempDir(), "audit.jsonl"))
+	for _, e := range []*audit.Entry{
+		// Before the analysis window, so not read but for knowing the log is older
+		{Timestamp: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Action: audit.ActionTransfer, After: &InventoryItem{PartID: "b"}},
+		{Timestamp: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), Action: audit.ActionAdjust, After: &InventoryItem{PartID: "a"},
+			Details: map[string]interface{}{"reason": AdjustmentReasonConsumption, "delta": -10.0}},
+	} {
+		e.Entity, e.Outcome = auditEntityInventoryItem, audit.OutcomeSuccess
+		require.NoError(t, sink.Append(ctx, e))
+	}
+	client := NewClient(server.URL, "token", nil)
+	client.SetAuditSink(sink)
+
+	analysis, err := client.Inventory.ABCAnalysis(ctx, &ABCAnalysisOptions{AsOf: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)})
+
+	require.NoError(t, err)
+	assert.Zero(t, gets.Load(), "parts are listed, not got one by one")
+	byID := map[string]*ABCPart{}
+	for _, p := range analysis.Parts {
+		byID[p.PartID] = p
+	}
+	require.Len(t, byID, 2)
+	assert.Equal(t, "Alpha", byID["a"].PartName)
+	assert.Equal(t, 10.0, byID["a"].Consumed)
+	assert.Equal(t, MovementActive, byID["a"].Movement)
+	assert.Equal(t, "Beta", byID["b"].PartName)
+	assert.Equal(t, MovementDead, byID["b"].Movement, "stock not moved in the window is dead")
+	assert.Empty(t, byID["b"].LastMovement)
+	require.NotNil(t, byID["b"].DaysSinceMovement)
+	assert.Equal(t, 365, *byID["b"].DaysSinceMovement)
+}
+
+// pageOf returns a page of all, counting pages from 1
+func pageOf[T any](all []T, page, perPage int) []T {
+	return all[min((page-1)*perPage, len(all)):min(page*perPage, len(all))]
+}
@@ -0,0 +1,66 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// TraceGenealogy creates a tool to trace the genealogy of a unit, lot or serial through the as-built BOMs.
+func TraceGenealogy(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("trace_genealogy",
+			mcp.WithDescription(t("TOOL_TRACE_GENEALOGY_DESCRIPTION", "Trace genealogy through the as-built BOMs: down from a serial number to every lot and serial that went into the unit, or up from a suspect lot or serial to every finished unit containing it. Returns a tree and a flat list of affected units for recall scoping")),
+			mcp.WithString("serial_number",
+				mcp.Description("Serial number to start from"),
+			),
+			mcp.WithString("lot_number",
+				mcp.Description("Lot number to start from"),
+			),
+			mcp.WithString("part_id",
+				mcp.Description("Part ID of the serial or lot, if serial or lot numbers are not unique across parts"),
+			),
+			mcp.WithString("direction",
+				mcp.Description("Direction to trace (default down from a serial number, up from a lot number)"),
+				mcp.Enum(GenealogyDown, GenealogyUp),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			opts := &TraceGenealogyOptions{}
+			var err error
+			if opts.SerialNumber, err = OptionalParam[string](request, "serial_number"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if opts.LotNumber, err = OptionalParam[string](request, "lot_number"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if opts.PartID, err = OptionalParam[string](request, "part_id"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if opts.Direction, err = OptionalParam[string](request, "direction"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			genealogy, err := client.ABom.TraceGenealogy(ctx, opts)
+			if errors.Is(err, ErrInvalidGenealogy) || errors.Is(err, ErrGenealogyNotFound) || errors.Is(err, ErrTooManyResults) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to trace genealogy: %w", err)
+			}
+
+			r, err := json.Marshal(genealogy)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
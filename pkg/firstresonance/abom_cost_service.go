@@ -0,0 +1,140 @@
+package firstresonance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrInvalidCostRollup is returned when a cost roll-up is asked for with a
+// missing or contradictory start
+var ErrInvalidCostRollup = errors.New("invalid cost roll-up")
+
+// CostRollup rolls the material cost of a unit up through its multi-level
+// as-built BOM. Items consuming a serial with an ABOM of its own are costed
+// by rolling up that ABOM, and items without a serial of a part that has an
+// ABOM, such as a subassembly, by rolling up the part's latest ABOM; every
+// other item is costed at its part's unit cost. When a unit has more than
+// one ABOM, the most recently updated is followed.
+func (s *ABomService) CostRollup(ctx context.Context, opts *CostRollupOptions) (*CostRollup, error) {
+	basis, err := costBasis(opts.CostBasis)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ABomID == "" && opts.SerialNumber == "" && opts.PartID == "" {
+		return nil, fmt.Errorf("%w: an abom_id, serial_number or part_id is required", ErrInvalidCostRollup)
+	}
+	if opts.ABomID != "" && opts.SerialNumber != "" {
+		return nil, fmt.Errorf("%w: give an abom_id or a serial_number, not both", ErrInvalidCostRollup)
+	}
+
+	index, err := s.genealogyIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var abom *ABom
+	switch {
+	case opts.ABomID != "":
+		if abom = index.byID[opts.ABomID]; abom == nil {
+			return nil, fmt.Errorf("%w: ABOM %s", ErrGenealogyNotFound, opts.ABomID)
+		}
+	case opts.SerialNumber != "":
+		if abom = index.unitABom(opts.SerialNumber, opts.PartID); abom == nil {
+			return nil, fmt.Errorf("%w: serial number %s", ErrGenealogyNotFound, opts.SerialNumber)
+		}
+	default:
+		if abom = index.partABom(opts.PartID); abom == nil {
+			return nil, fmt.Errorf("%w: part %s", ErrGenealogyNotFound, opts.PartID)
+		}
+	}
+
+	costs, err := s.client.Costs.Costs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rollup := &CostRollup{CostBasis: basis, UncostedParts: []string{}}
+	rollup.Root = &CostRollupNode{PartID: abom.PartID, SerialNumber: abom.SerialNumber, Quantity: 1, ABomID: abom.ID}
+	if _, unit, err := s.client.Units.StockQuantity(abom.PartID, 1, ""); err == nil {
+		rollup.Root.Unit = unit
+	}
+	uncosted := map[string]bool{}
+	cost, err := s.rollUp(index, costs, basis, rollup.Root, abom, map[string]bool{abom.ID: true}, uncosted)
+	if err != nil {
+		return nil, err
+	}
+	rollup.Root.Cost = cost
+	rollup.MaterialCost = cost
+	for partID := range uncosted {
+		rollup.UncostedParts = append(rollup.UncostedParts, partID)
+	}
+	sort.Strings(rollup.UncostedParts)
+	return rollup, nil
+}
+
+// rollUp adds a node's children for the items of the ABOM that built it and
+// returns the cost of one unit. path holds the ABOMs above the node, so a
+// cycle in the records is not followed.
+func (s *ABomService) rollUp(index *genealogyIndex, costs map[string]*PartCost, basis string, node *CostRollupNode, abom *ABom, path, uncosted map[string]bool) (float64, error) {
+	var total float64
+	for i, item := range abom.Items {
+		quantity, unit, err := s.client.Units.StockQuantity(item.PartID, item.Quantity, item.Unit)
+		if err != nil {
+			return 0, fmt.Errorf("ABOM %s item %d: %w", abom.ID, i+1, err)
+		}
+		child := &CostRollupNode{
+			PartID:       item.PartID,
+			SerialNumber: item.SerialNumber,
+			LotNumber:    item.LotNumber,
+			Quantity:     quantity,
+			Unit:         unit,
+		}
+		node.Children = append(node.Children, child)
+
+		// A consumed serial is rolled up from the ABOM that built it; any
+		// other line of a part with an ABOM of its own, from the part's
+		// latest one
+		var sub *ABom
+		if item.SerialNumber != "" {
+			sub = index.unitABom(item.SerialNumber, item.PartID)
+		} else if item.PartID != "" {
+			sub = index.partABom(item.PartID)
+		}
+		if sub != nil && !path[sub.ID] {
+			child.ABomID = sub.ID
+			path[sub.ID] = true
+			cost, err := s.rollUp(index, costs, basis, child, sub, path, uncosted)
+			delete(path, sub.ID)
+			if err != nil {
+				return 0, err
+			}
+			child.Cost = roundMoney(cost * quantity)
+		} else if c, ok := s.client.Costs.stockUnitCost(costs, item.PartID, unit, basis); ok {
+			child.UnitCost = &c
+			child.Cost = roundMoney(c * quantity)
+		} else {
+			child.Uncosted = true
+			if item.PartID != "" {
+				uncosted[item.PartID] = true
+			}
+		}
+		total = roundMoney(total + child.Cost)
+	}
+	return total, nil
+}
+
+// partABom returns the most recently updated ABOM of a part, or nil if it
+// has none
+func (x *genealogyIndex) partABom(partID string) *ABom {
+	var latest *ABom
+	for _, abom := range x.byID {
+		if abom.PartID != partID {
+			continue
+		}
+		if latest == nil || abom.UpdatedAt > latest.UpdatedAt || (abom.UpdatedAt == latest.UpdatedAt && abom.ID > latest.ID) {
+			latest = abom
+		}
+	}
+	return latest
+}
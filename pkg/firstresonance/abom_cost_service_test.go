@@ -0,0 +1,55 @@
+package firstresonance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollUp(t *testing.T) {
+	client := NewClient("http://localhost", "token", nil)
+	aboms := []*ABom{
+		{ID: "top", PartID: "assembly", SerialNumber: "A1", Items: []ABomItem{
+			{PartID: "motor", Quantity: 1, SerialNumber: "M1"},
+			{PartID: "bracket", Quantity: 2},
+			{PartID: "bolt", Quantity: 8},
+			{PartID: "sticker", Quantity: 1},
+		}},
+		{ID: "motor-m1", PartID: "motor", SerialNumber: "M1", Items: []ABomItem{{PartID: "winding", Quantity: 1}}},
+		{ID: "bracket-old", PartID: "bracket", UpdatedAt: "2026-01-01", Items: []ABomItem{{PartID: "plate", Quantity: 9}}},
+		{ID: "bracket-new", PartID: "bracket", UpdatedAt: "2026-06-01", Items: []ABomItem{
+			{PartID: "plate", Quantity: 1},
+			{PartID: "bolt", Quantity: 2},
+		}},
+	}
+	index := &genealogyIndex{byID: map[string]*ABom{}, bySerial: map[string][]*ABom{}}
+	for _, abom := range aboms {
+		index.byID[abom.ID] = abom
+		if abom.SerialNumber != "" {
+			index.bySerial[abom.SerialNumber] = append(index.bySerial[abom.SerialNumber], abom)
+		}
+	}
+	costs := map[string]*PartCost{
+		"winding": {PartID: "winding", StandardCost: 40},
+		"plate":   {PartID: "plate", StandardCost: 3},
+		"bolt":    {PartID: "bolt", StandardCost: 0.25},
+		// A flat cost of a subassembly with an ABOM is not used
+		"bracket": {PartID: "bracket", StandardCost: 100},
+	}
+
+	root := &CostRollupNode{PartID: "assembly", Quantity: 1, ABomID: "top"}
+	uncosted := map[string]bool{}
+	cost, err := client.ABom.rollUp(index, costs, CostBasisStandard, root, index.byID["top"], map[string]bool{"top": true}, uncosted)
+
+	require.NoError(t, err)
+	// motor 40 + 2 brackets of (3 + 2 × 0.25) + 8 bolts × 0.25
+	assert.Equal(t, 49.0, cost)
+	require.Len(t, root.Children, 4)
+	assert.Equal(t, "motor-m1", root.Children[0].ABomID)
+	bracket := root.Children[1]
+	assert.Equal(t, "bracket-new", bracket.ABomID, "an unserialized subassembly is rolled up from its latest ABOM")
+	assert.Equal(t, 7.0, bracket.Cost)
+	assert.Nil(t, bracket.UnitCost)
+	assert.Equal(t, map[string]bool{"sticker": true}, uncosted)
+}
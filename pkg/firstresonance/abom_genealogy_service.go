@@ -0,0 +1,226 @@
+package firstresonance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrInvalidGenealogy is returned when a genealogy trace is asked for with
+// a missing or contradictory start
+var ErrInvalidGenealogy = errors.New("invalid genealogy trace")
+
+// ErrGenealogyNotFound is returned when no as-built record exists for the
+// serial number a trace down starts from
+var ErrGenealogyNotFound = errors.New("no as-built record found")
+
+// genealogyABomLimit caps how many ABOMs a genealogy trace reads
+const genealogyABomLimit = 10000
+
+// abomConsumption is an ABOM item consuming a lot or serial into a unit
+type abomConsumption struct {
+	abom *ABom
+	item ABomItem
+}
+
+// genealogyIndex links the ABOMs read for a trace by ID, by the units they
+// build and by the lots and serials they consume
+type genealogyIndex struct {
+	byID            map[string]*ABom
+	bySerial        map[string][]*ABom
+	consumingLot    map[string][]abomConsumption
+	consumingSerial map[string][]abomConsumption
+}
+
+// TraceGenealogy traces, through the items of the as-built BOMs, the lots and
+// serials that went into a unit or the units a lot or serial went into. When
+// a unit has more than one ABOM, the most recently updated is followed.
+func (s *ABomService) TraceGenealogy(ctx context.Context, opts *TraceGenealogyOptions) (*Genealogy, error) {
+	direction, err := genealogyDirection(opts)
+	if err != nil {
+		return nil, err
+	}
+	index, err := s.genealogyIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	genealogy := &Genealogy{Direction: direction, AffectedUnits: []*AffectedUnit{}}
+	if direction == GenealogyDown {
+		abom := index.unitABom(opts.SerialNumber, opts.PartID)
+		if abom == nil {
+			return nil, fmt.Errorf("%w: serial number %s", ErrGenealogyNotFound, opts.SerialNumber)
+		}
+		genealogy.Root = &GenealogyNode{PartID: abom.PartID, SerialNumber: abom.SerialNumber}
+		index.traceDown(genealogy, genealogy.Root, abom, 1, map[string]bool{abom.SerialNumber: true})
+		return genealogy, nil
+	}
+
+	genealogy.Root = &GenealogyNode{PartID: opts.PartID, SerialNumber: opts.SerialNumber, LotNumber: opts.LotNumber}
+	if opts.SerialNumber != "" {
+		if abom := index.unitABom(opts.SerialNumber, opts.PartID); abom != nil {
+			genealogy.Root.PartID, genealogy.Root.ABomID = abom.PartID, abom.ID
+		}
+	}
+	index.traceUp(genealogy, genealogy.Root, 1, map[string]bool{}, map[string]bool{})
+	sort.Slice(genealogy.AffectedUnits, func(i, j int) bool {
+		a, b := genealogy.AffectedUnits[i], genealogy.AffectedUnits[j]
+		if a.PartID != b.PartID {
+			return a.PartID < b.PartID
+		}
+		if a.SerialNumber != b.SerialNumber {
+			return a.SerialNumber < b.SerialNumber
+		}
+		return a.ABomID < b.ABomID
+	})
+	return genealogy, nil
+}
+
+// genealogyDirection validates the start of a trace and returns its
+// direction, by default down from a serial number and up from a lot
+func genealogyDirection(opts *TraceGenealogyOptions) (string, error) {
+	switch {
+	case opts.SerialNumber == "" && opts.LotNumber == "":
+		return "", fmt.Errorf("%w: a serial_number or lot_number is required", ErrInvalidGenealogy)
+	case opts.SerialNumber != "" && opts.LotNumber != "":
+		return "", fmt.Errorf("%w: give a serial_number or a lot_number, not both", ErrInvalidGenealogy)
+	}
+	switch opts.Direction {
+	case "":
+		if opts.SerialNumber != "" {
+			return GenealogyDown, nil
+		}
+		return GenealogyUp, nil
+	case GenealogyDown:
+		if opts.SerialNumber == "" {
+			return "", fmt.Errorf("%w: a trace down starts from a serial_number", ErrInvalidGenealogy)
+		}
+		return GenealogyDown, nil
+	case GenealogyUp:
+		return GenealogyUp, nil
+	default:
+		return "", fmt.Errorf("%w: direction must be %s or %s, not %q", ErrInvalidGenealogy, GenealogyDown, GenealogyUp, opts.Direction)
+	}
+}
+
+// genealogyIndex reads every ABOM, failing with ErrTooManyResults beyond
+// genealogyABomLimit, and indexes them for tracing
+func (s *ABomService) genealogyIndex(ctx context.Context) (*genealogyIndex, error) {
+	iterOpts := &IterOptions{PerPage: defaultIterPerPage, MaxItems: genealogyABomLimit + 1}
+	aboms, err := collect(s.Iter(ctx, &ListABomsOptions{}, iterOpts), genealogyABomLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ABOMs: %w", err)
+	}
+
+	index := &genealogyIndex{
+		byID:            map[string]*ABom{},
+		bySerial:        map[string][]*ABom{},
+		consumingLot:    map[string][]abomConsumption{},
+		consumingSerial: map[string][]abomConsumption{},
+	}
+	for _, abom := range aboms {
+		index.byID[abom.ID] = abom
+		if abom.SerialNumber != "" {
+			index.bySerial[abom.SerialNumber] = append(index.bySerial[abom.SerialNumber], abom)
+		}
+		for _, item := range abom.Items {
+			if item.LotNumber != "" {
+				index.consumingLot[item.LotNumber] = append(index.consumingLot[item.LotNumber], abomConsumption{abom, item})
+			}
+			if item.SerialNumber != "" {
+				index.consumingSerial[item.SerialNumber] = append(index.consumingSerial[item.SerialNumber], abomConsumption{abom, item})
+			}
+		}
+	}
+	return index, nil
+}
+
+// unitABom returns the most recently updated ABOM of a unit, or nil if it
+// has none. An empty partID matches any part.
+func (x *genealogyIndex) unitABom(serialNumber, partID string) *ABom {
+	var latest *ABom
+	for _, abom := range x.bySerial[serialNumber] {
+		if partID != "" && abom.PartID != partID {
+			continue
+		}
+		if latest == nil || abom.UpdatedAt > latest.UpdatedAt {
+			latest = abom
+		}
+	}
+	return latest
+}
+
+// traceDown adds the items of a unit's ABOM as children of its node, and
+// follows each consumed serial into its own ABOM. Serials already on the
+// path are not followed again, so a cycle in the records cannot loop.
+func (x *genealogyIndex) traceDown(genealogy *Genealogy, node *GenealogyNode, abom *ABom, depth int, path map[string]bool) {
+	node.ABomID = abom.ID
+	for _, item := range abom.Items {
+		child := &GenealogyNode{
+			PartID:       item.PartID,
+			SerialNumber: item.SerialNumber,
+			LotNumber:    item.LotNumber,
+			Quantity:     item.Quantity,
+		}
+		node.Children = append(node.Children, child)
+		if item.SerialNumber != "" && !path[item.SerialNumber] {
+			if sub := x.unitABom(item.SerialNumber, item.PartID); sub != nil {
+				path[item.SerialNumber] = true
+				x.traceDown(genealogy, child, sub, depth+1, path)
+				delete(path, item.SerialNumber)
+			}
+		}
+		if item.SerialNumber != "" || item.LotNumber != "" {
+			genealogy.AffectedUnits = append(genealogy.AffectedUnits, &AffectedUnit{
+				PartID:       child.PartID,
+				SerialNumber: child.SerialNumber,
+				LotNumber:    child.LotNumber,
+				ABomID:       child.ABomID,
+				Depth:        depth,
+			})
+		}
+	}
+}
+
+// traceUp adds the units that consumed a node's lot or serial as its
+// children, and follows each unit's serial up into the units that consumed
+// it. A unit nothing consumed is finished and is added to the affected
+// units once, however many paths lead to it.
+func (x *genealogyIndex) traceUp(genealogy *Genealogy, node *GenealogyNode, depth int, path, affected map[string]bool) {
+	var consumers []abomConsumption
+	if node.LotNumber != "" {
+		consumers = x.consumingLot[node.LotNumber]
+	} else {
+		consumers = x.consumingSerial[node.SerialNumber]
+	}
+	for _, c := range consumers {
+		if node.PartID != "" && c.item.PartID != node.PartID {
+			continue
+		}
+		if path[c.abom.ID] {
+			continue
+		}
+		child := &GenealogyNode{
+			PartID:       c.abom.PartID,
+			SerialNumber: c.abom.SerialNumber,
+			Quantity:     c.item.Quantity,
+			ABomID:       c.abom.ID,
+		}
+		node.Children = append(node.Children, child)
+		if child.SerialNumber != "" {
+			path[c.abom.ID] = true
+			x.traceUp(genealogy, child, depth+1, path, affected)
+			delete(path, c.abom.ID)
+		}
+		if len(child.Children) == 0 && !affected[c.abom.ID] {
+			affected[c.abom.ID] = true
+			genealogy.AffectedUnits = append(genealogy.AffectedUnits, &AffectedUnit{
+				PartID:       child.PartID,
+				SerialNumber: child.SerialNumber,
+				ABomID:       child.ABomID,
+				Depth:        depth,
+			})
+		}
+	}
+}
@@ -3,12 +3,19 @@ package firstresonance
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"strings"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/audit"
 )
 
+// ErrInvalidABomItems is returned when an ABOM's items fail validation
+var ErrInvalidABomItems = errors.New("invalid ABOM items")
+
 // Get retrieves an ABOM by its ID
 func (s *ABomService) Get(ctx context.Context, id string) (*ABom, error) {
 	// GraphQL query to fetch an ABOM by ID
@@ -17,6 +24,8 @@ func (s *ABomService) Get(ctx context.Context, id string) (*ABom, error) {
 			abom(id: $id) {
 				id
 				name
+				part_id
+				serial_number
 				description
 				version
 				status
@@ -28,6 +37,8 @@ func (s *ABomService) Get(ctx context.Context, id string) (*ABom, error) {
 					quantity
 					unit
 					notes
+					lot_number
+					serial_number
 				}
 			}
 		}
@@ -113,6 +124,8 @@ func (s *ABomService) List(ctx context.Context, opts *ListABomsOptions) ([]*ABom
 			aboms(status: $status, sort: $sort, direction: $direction, page: $page, perPage: $perPage) {
 				id
 				name
+				part_id
+				serial_number
 				description
 				version
 				status
@@ -124,6 +137,8 @@ func (s *ABomService) List(ctx context.Context, opts *ListABomsOptions) ([]*ABom
 					quantity
 					unit
 					notes
+					lot_number
+					serial_number
 				}
 			}
 		}
@@ -211,14 +226,41 @@ func (s *ABomService) List(ctx context.Context, opts *ListABomsOptions) ([]*ABom
 	return result.Data.ABoms, nil
 }
 
-// Create creates a new ABOM
+// Iter returns an iterator over every ABOM matching opts, fetching pages as needed
+func (s *ABomService) Iter(ctx context.Context, opts *ListABomsOptions, iterOpts *IterOptions) iter.Seq2[*ABom, error] {
+	return paginate(ctx, func(ctx context.Context, page, perPage int) ([]*ABom, error) {
+		pageOpts := ListABomsOptions{}
+		if opts != nil {
+			pageOpts = *opts
+		}
+		pageOpts.Page, pageOpts.PerPage = page, perPage
+		return s.List(ctx, &pageOpts)
+	}, iterOpts)
+}
+
+// Create creates a new ABOM and records it in the audit log
 func (s *ABomService) Create(ctx context.Context, abom *ABom) (*ABom, error) {
+	if err := s.validateItems(abom.Items); err != nil {
+		return nil, err
+	}
+	created, err := s.create(ctx, abom)
+	entry := &audit.Entry{Entity: auditEntityABom, Action: audit.ActionCreate, After: created}
+	if created != nil {
+		entry.EntityID = created.ID
+	}
+	return created, s.client.Audit.record(ctx, entry, err)
+}
+
+// create sends the createABom mutation
+func (s *ABomService) create(ctx context.Context, abom *ABom) (*ABom, error) {
 	// GraphQL mutation to create a new ABOM
 	query := `
 		mutation CreateABom($input: CreateABomInput!) {
 			createABom(input: $input) {
 				id
 				name
+				part_id
+				serial_number
 				description
 				version
 				status
@@ -230,6 +272,8 @@ func (s *ABomService) Create(ctx context.Context, abom *ABom) (*ABom, error) {
 					quantity
 					unit
 					notes
+					lot_number
+					serial_number
 				}
 			}
 		}
@@ -238,11 +282,13 @@ func (s *ABomService) Create(ctx context.Context, abom *ABom) (*ABom, error) {
 	// Create variables for the mutation
 	variables := map[string]interface{}{
 		"input": map[string]interface{}{
-			"name":        abom.Name,
-			"description": abom.Description,
-			"version":     abom.Version,
-			"status":      abom.Status,
-			"items":       abom.Items,
+			"name":          abom.Name,
+			"part_id":       abom.PartID,
+			"serial_number": abom.SerialNumber,
+			"description":   abom.Description,
+			"version":       abom.Version,
+			"status":        abom.Status,
+			"items":         abom.Items,
 		},
 	}
 
@@ -308,14 +354,46 @@ func (s *ABomService) Create(ctx context.Context, abom *ABom) (*ABom, error) {
 	return result.Data.CreateABom, nil
 }
 
-// Update updates an existing ABOM
+// Update updates an existing ABOM and records the change in the audit log
 func (s *ABomService) Update(ctx context.Context, id string, update *ABomUpdateRequest) (*ABom, error) {
+	if update.Items != nil {
+		if err := s.validateItems(*update.Items); err != nil {
+			return nil, err
+		}
+	}
+	before := auditSnapshot(ctx, s.client, s.Get, id)
+	updated, err := s.update(ctx, id, update)
+	entry := &audit.Entry{Entity: auditEntityABom, EntityID: id, Action: audit.ActionUpdate, Before: before, After: updated}
+	return updated, s.client.Audit.record(ctx, entry, err)
+}
+
+// validateItems checks that every ABOM item's unit converts to the unit its
+// part's stock is counted in, which it is consumed from: the part's base
+// unit, or each for a part without one. All problems found are reported
+// together.
+func (s *ABomService) validateItems(items []ABomItem) error {
+	var problems []string
+	for i, item := range items {
+		if _, _, err := s.client.Units.StockQuantity(item.PartID, item.Quantity, item.Unit); err != nil {
+			problems = append(problems, fmt.Sprintf("item %d: %v", i+1, err))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidABomItems, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// update sends the updateABom mutation
+func (s *ABomService) update(ctx context.Context, id string, update *ABomUpdateRequest) (*ABom, error) {
 	// GraphQL mutation to update an existing ABOM
 	query := `
 		mutation UpdateABom($id: ID!, $input: UpdateABomInput!) {
 			updateABom(id: $id, input: $input) {
 				id
 				name
+				part_id
+				serial_number
 				description
 				version
 				status
@@ -327,6 +405,8 @@ func (s *ABomService) Update(ctx context.Context, id string, update *ABomUpdateR
 					quantity
 					unit
 					notes
+					lot_number
+					serial_number
 				}
 			}
 		}
@@ -336,11 +416,13 @@ func (s *ABomService) Update(ctx context.Context, id string, update *ABomUpdateR
 	variables := map[string]interface{}{
 		"id": id,
 		"input": map[string]interface{}{
-			"name":        update.Name,
-			"description": update.Description,
-			"version":     update.Version,
-			"status":      update.Status,
-			"items":       update.Items,
+			"name":          update.Name,
+			"part_id":       update.PartID,
+			"serial_number": update.SerialNumber,
+			"description":   update.Description,
+			"version":       update.Version,
+			"status":        update.Status,
+			"items":         update.Items,
 		},
 	}
 
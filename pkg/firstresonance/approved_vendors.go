@@ -0,0 +1,69 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ListPartSuppliers creates a tool to list the suppliers on a part's approved vendor list.
+func ListPartSuppliers(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("list_part_suppliers",
+			mcp.WithDescription(t("TOOL_LIST_PART_SUPPLIERS_DESCRIPTION", "List the suppliers on a part's approved vendor list with their part numbers, lead times, price breaks and approval status, approved suppliers with the shortest lead time first")),
+			mcp.WithString("part_id",
+				mcp.Required(),
+				mcp.Description("Part ID"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			partID, err := requiredParam[string](request, "part_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return listApprovedVendors(ctx, getClient, &ListApprovedVendorsOptions{PartID: partID})
+		}
+}
+
+// ListSupplierParts creates a tool to list the parts a supplier is on the approved vendor list for.
+func ListSupplierParts(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("list_supplier_parts",
+			mcp.WithDescription(t("TOOL_LIST_SUPPLIER_PARTS_DESCRIPTION", "List the parts a supplier is on the approved vendor list for, with its part numbers, lead times, price breaks and approval status")),
+			mcp.WithString("supplier_id",
+				mcp.Required(),
+				mcp.Description("Supplier ID"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			supplierID, err := requiredParam[string](request, "supplier_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return listApprovedVendors(ctx, getClient, &ListApprovedVendorsOptions{SupplierID: supplierID})
+		}
+}
+
+// listApprovedVendors returns the approved vendor entries matching opts as a tool result
+func listApprovedVendors(ctx context.Context, getClient GetClientFn, opts *ListApprovedVendorsOptions) (*mcp.CallToolResult, error) {
+	client, err := getClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+	}
+	vendors, err := client.ApprovedVendors.List(ctx, opts)
+	if errors.Is(err, ErrApprovedVendorsDisabled) {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approved vendors: %w", err)
+	}
+
+	r, err := json.Marshal(vendors)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return mcp.NewToolResultText(string(r)), nil
+}
@@ -0,0 +1,287 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ErrApprovedVendorsDisabled is returned when the API keeps no approved
+// vendor list and no file is configured
+var ErrApprovedVendorsDisabled = errors.New("the approved vendor list is not available: the API does not keep one and no approved vendors file is configured")
+
+// approvedVendorsField is the query field of the API's approved vendor list
+const approvedVendorsField = "approvedVendors"
+
+// vendorStatusRank orders approved vendor statuses from most to least usable
+var vendorStatusRank = map[string]int{
+	VendorStatusApproved:     0,
+	VendorStatusConditional:  1,
+	VendorStatusPending:      2,
+	VendorStatusDisqualified: 3,
+}
+
+// List returns the approved vendor entries matching opts, from the API if it
+// keeps an approved vendor list and from the configured file otherwise.
+// Entries are sorted by part, then status, then lead time.
+func (s *ApprovedVendorsService) List(ctx context.Context, opts *ListApprovedVendorsOptions) ([]*ApprovedVendor, error) {
+	if opts == nil {
+		opts = &ListApprovedVendorsOptions{}
+	}
+
+	vendors, err := s.fetch(ctx, opts)
+	if errors.Is(err, errConnectionsUnsupported) {
+		vendors, err = s.readFile(opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(vendors, func(i, j int) bool {
+		a, b := vendors[i], vendors[j]
+		if a.PartID != b.PartID {
+			return a.PartID < b.PartID
+		}
+		if ra, rb := vendorStatusRank[a.Status], vendorStatusRank[b.Status]; ra != rb {
+			return ra < rb
+		}
+		if a.LeadTimeDays != b.LeadTimeDays {
+			return a.LeadTimeDays < b.LeadTimeDays
+		}
+		return a.SupplierID < b.SupplierID
+	})
+	return vendors, nil
+}
+
+// CheckOrder returns a warning for every part on an order that its supplier
+// is not approved for. It returns no warnings for orders without a supplier,
+// or if there is no approved vendor list.
+func (s *ApprovedVendorsService) CheckOrder(ctx context.Context, order *Order) ([]string, error) {
+	if order.SupplierID == "" {
+		return nil, nil
+	}
+
+	var warnings []string
+	checked := map[string]bool{}
+	for _, partID := range orderPartIDs(order) {
+		if checked[partID] {
+			continue
+		}
+		checked[partID] = true
+
+		vendors, err := s.List(ctx, &ListApprovedVendorsOptions{PartID: partID})
+		if errors.Is(err, ErrApprovedVendorsDisabled) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var approved []string
+		var entry *ApprovedVendor
+		for _, v := range vendors {
+			if v.SupplierID == order.SupplierID {
+				entry = v
+			}
+			if v.Status == VendorStatusApproved {
+				approved = append(approved, v.SupplierID)
+			}
+		}
+		switch {
+		case entry != nil && entry.Status == VendorStatusApproved:
+			continue
+		case entry != nil:
+			warnings = append(warnings, fmt.Sprintf("supplier %s is %s, not approved, for part %s", order.SupplierID, entry.Status, partID))
+		default:
+			warnings = append(warnings, fmt.Sprintf("supplier %s is not on the approved vendor list for part %s", order.SupplierID, partID))
+		}
+		if len(approved) > 0 {
+			warnings[len(warnings)-1] += fmt.Sprintf("; approved suppliers are %s", strings.Join(approved, ", "))
+		}
+	}
+	return warnings, nil
+}
+
+// UnitPrice returns the unit price of the largest price break at or below
+// quantity, and false if the vendor has no price for that quantity
+func (v *ApprovedVendor) UnitPrice(quantity float64) (float64, bool) {
+	price, found, best := 0.0, false, 0.0
+	for _, b := range v.PriceBreaks {
+		if b.MinQuantity <= quantity && (!found || b.MinQuantity > best) {
+			price, found, best = b.UnitPrice, true, b.MinQuantity
+		}
+	}
+	return price, found
+}
+
+// fetch queries the API's approved vendor list. It returns
+// errConnectionsUnsupported if the API does not keep one, and remembers this
+// so the query is not retried.
+func (s *ApprovedVendorsService) fetch(ctx context.Context, opts *ListApprovedVendorsOptions) ([]*ApprovedVendor, error) {
+	if _, unsupported := s.client.connectionsUnsupported.Load(approvedVendorsField); unsupported {
+		return nil, errConnectionsUnsupported
+	}
+
+	// GraphQL query to fetch approved vendors by part or supplier
+	query := `
+		query ApprovedVendors($partId: ID, $supplierId: ID) {
+			approvedVendors(partId: $partId, supplierId: $supplierId) {
+				part_id
+				supplier_id
+				supplier_part_number
+				lead_time_days
+				price_breaks {
+					min_quantity
+					unit_price
+				}
+				status
+			}
+		}
+	`
+
+	// Create variables for the query
+	variables := map[string]interface{}{}
+	if opts.PartID != "" {
+		variables["partId"] = opts.PartID
+	}
+	if opts.SupplierID != "" {
+		variables["supplierId"] = opts.SupplierID
+	}
+
+	// Create the request body
+	requestBody := map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	}
+
+	// Marshal the request body to JSON
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request body: %w", err)
+	}
+
+	// Create the HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", s.client.baseURL+"/graphql", strings.NewReader(string(jsonBody)))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.client.apiToken)
+
+	// Send the request
+	resp, err := s.client.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read the response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	// Check for non-200 status codes
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	// Parse the response
+	var result struct {
+		Data struct {
+			ApprovedVendors []*ApprovedVendor `json:"approvedVendors"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+
+	// Check for GraphQL errors, telling a missing approved vendor list apart
+	if len(result.Errors) > 0 {
+		if strings.Contains(result.Errors[0].Message, "Cannot query field \""+approvedVendorsField+"\"") {
+			s.client.connectionsUnsupported.Store(approvedVendorsField, true)
+			return nil, errConnectionsUnsupported
+		}
+		return nil, fmt.Errorf("GraphQL error: %s", result.Errors[0].Message)
+	}
+
+	vendors := result.Data.ApprovedVendors
+	if vendors == nil {
+		vendors = []*ApprovedVendor{}
+	}
+	return vendors, nil
+}
+
+// readFile reads the approved vendors matching opts from the configured file.
+// Entries without a status are approved.
+func (s *ApprovedVendorsService) readFile(opts *ListApprovedVendorsOptions) ([]*ApprovedVendor, error) {
+	if s.client.vendorsPath == "" {
+		return nil, ErrApprovedVendorsDisabled
+	}
+
+	data, err := os.ReadFile(s.client.vendorsPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading approved vendors: %w", err)
+	}
+
+	var all []*ApprovedVendor
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("error parsing approved vendors: %w", err)
+	}
+
+	vendors := []*ApprovedVendor{}
+	seen := map[[2]string]bool{}
+	for i, v := range all {
+		if v.Status == "" {
+			v.Status = VendorStatusApproved
+		}
+		if err := validateApprovedVendor(v); err != nil {
+			return nil, fmt.Errorf("approved vendor %d: %w", i, err)
+		}
+		key := [2]string{v.PartID, v.SupplierID}
+		if seen[key] {
+			return nil, fmt.Errorf("approved vendor %d: supplier %s is listed more than once for part %s", i, v.SupplierID, v.PartID)
+		}
+		seen[key] = true
+
+		if (opts.PartID == "" || v.PartID == opts.PartID) && (opts.SupplierID == "" || v.SupplierID == opts.SupplierID) {
+			vendors = append(vendors, v)
+		}
+	}
+	return vendors, nil
+}
+
+// validateApprovedVendor checks that an approved vendor entry names a part and
+// a supplier, has a known status and has no negative lead time or prices
+func validateApprovedVendor(v *ApprovedVendor) error {
+	if v.PartID == "" {
+		return fmt.Errorf("no part_id")
+	}
+	if v.SupplierID == "" {
+		return fmt.Errorf("no supplier_id for part %s", v.PartID)
+	}
+	if _, ok := vendorStatusRank[v.Status]; !ok {
+		return fmt.Errorf("unknown status %q for part %s, must be approved, conditional, pending or disqualified", v.Status, v.PartID)
+	}
+	if v.LeadTimeDays < 0 {
+		return fmt.Errorf("negative lead time for part %s", v.PartID)
+	}
+	for _, b := range v.PriceBreaks {
+		if b.MinQuantity < 0 || b.UnitPrice < 0 {
+			return fmt.Errorf("negative price break for part %s", v.PartID)
+		}
+	}
+	return nil
+}
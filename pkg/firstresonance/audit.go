@@ -0,0 +1,179 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/audit"
+)
+
+// QueryAuditLog creates a tool to query the audit log of mutations.
+func QueryAuditLog(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("query_audit_log",
+			mcp.WithDescription(t("TOOL_QUERY_AUDIT_LOG_DESCRIPTION", "Query the audit log of creates and updates made through this server, most recent first")),
+			mcp.WithString("entity",
+				mcp.Description("Filter by entity type"),
+				mcp.Enum(auditEntityPart, auditEntityOrder, auditEntitySupplier, auditEntityInventoryItem, auditEntityABom),
+			),
+			mcp.WithString("entity_id",
+				mcp.Description("Filter by entity ID"),
+			),
+			mcp.WithString("action",
+				mcp.Description("Filter by action, e.g. create, update, adjust or transfer"),
+			),
+			mcp.WithString("tool",
+				mcp.Description("Filter by the tool that made the change"),
+			),
+			mcp.WithString("caller",
+				mcp.Description("Filter by caller identity"),
+			),
+			mcp.WithString("outcome",
+				mcp.Description("Filter by outcome"),
+				mcp.Enum(audit.OutcomeSuccess, audit.OutcomeFailure),
+			),
+			mcp.WithString("since",
+				mcp.Description("Only entries at or after this time (RFC 3339)"),
+			),
+			mcp.WithString("until",
+				mcp.Description("Only entries at or before this time (RFC 3339)"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Maximum number of entries to return (default 100)"),
+				mcp.Min(1),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			entity, err := OptionalParam[string](request, "entity")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			entityID, err := OptionalParam[string](request, "entity_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			action, err := OptionalParam[string](request, "action")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			toolName, err := OptionalParam[string](request, "tool")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			caller, err := OptionalParam[string](request, "caller")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			outcome, err := OptionalParam[string](request, "outcome")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			opts := &audit.QueryOptions{
+				Entity:   entity,
+				EntityID: entityID,
+				Action:   action,
+				Tool:     toolName,
+				Caller:   caller,
+				Outcome:  outcome,
+			}
+			if opts.Since, err = optionalTimeParam(request, "since"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if opts.Until, err = optionalTimeParam(request, "until"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if opts.Limit, err = OptionalIntParamWithDefault(request, "limit", 100); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			entries, err := client.Audit.Query(ctx, opts)
+			if errors.Is(err, ErrAuditDisabled) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to query audit log: %w", err)
+			}
+
+			r, err := json.Marshal(entries)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
+
+// optionalTimeParam fetches an optional RFC 3339 timestamp parameter from the request.
+func optionalTimeParam(r mcp.CallToolRequest, p string) (time.Time, error) {
+	v, err := OptionalParam[string](r, p)
+	if err != nil || v == "" {
+		return time.Time{}, err
+	}
+	ts, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parameter %s is not an RFC 3339 timestamp: %w", p, err)
+	}
+	return ts, nil
+}
+
+// auditCallers remembers which client implementation opened each session so
+// that mutations can be attributed to the agent that made them.
+type auditCallers struct {
+	sessions sync.Map
+}
+
+// register adds the hooks that capture client info on initialize and forget
+// it when the session ends. The server has no hook for a session ending, but
+// a session is registered with the context of its connection, which is done
+// once the session is unregistered.
+func (a *auditCallers) register(hooks *server.Hooks) {
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		go func() {
+			<-ctx.Done()
+			a.sessions.Delete(session.SessionID())
+		}()
+	})
+	hooks.AddAfterInitialize(func(ctx context.Context, _ any, message *mcp.InitializeRequest, _ *mcp.InitializeResult) {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			a.sessions.Store(session.SessionID(), message.Params.ClientInfo)
+		}
+	})
+}
+
+// caller returns the identity of the client behind the current session.
+func (a *auditCallers) caller(ctx context.Context) string {
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return ""
+	}
+	info, ok := a.sessions.Load(session.SessionID())
+	if !ok {
+		return session.SessionID()
+	}
+	impl := info.(mcp.Implementation)
+	return fmt.Sprintf("%s/%s (session %s)", impl.Name, impl.Version, session.SessionID())
+}
+
+// wrap returns the tool with a handler that tags the context with the calling
+// session, tool name and arguments before any mutation is made.
+func (a *auditCallers) wrap(tool mcp.Tool, handler server.ToolHandlerFunc) (mcp.Tool, server.ToolHandlerFunc) {
+	return tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = audit.WithCall(ctx, audit.Call{
+			Caller:    a.caller(ctx),
+			Tool:      request.Params.Name,
+			Arguments: request.Params.Arguments,
+		})
+		return handler(ctx, request)
+	}
+}
@@ -0,0 +1,142 @@
+package firstresonance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/audit"
+)
+
+// Entity names used in audit entries
+const (
+	auditEntityPart          = "part"
+	auditEntityOrder         = "order"
+	auditEntitySupplier      = "supplier"
+	auditEntityInventoryItem = "inventory_item"
+	auditEntityABom          = "abom"
+)
+
+// ErrAuditDisabled is returned when the audit log is queried but no sink is configured
+var ErrAuditDisabled = errors.New("audit log is not enabled")
+
+// ErrAuditNotRecorded is returned alongside the result of a mutation that
+// succeeded but could not be recorded in the audit log. The mutation must not
+// be retried; callers return its result with the error as a warning.
+var ErrAuditNotRecorded = errors.New("the change was made but not recorded in the audit log")
+
+// Enabled reports whether mutations are being recorded
+func (s *AuditService) Enabled() bool {
+	return s.client.auditSink != nil
+}
+
+// Query retrieves entries from the audit log, most recent first
+func (s *AuditService) Query(ctx context.Context, opts *audit.QueryOptions) ([]*audit.Entry, error) {
+	if !s.Enabled() {
+		return nil, ErrAuditDisabled
+	}
+	return s.client.auditSink.Query(ctx, opts)
+}
+
+// record appends an entry for a mutation to the audit log. It returns the
+// mutation error, joined with any error from the sink, or, if only the sink
+// failed, an error wrapping ErrAuditNotRecorded.
+func (s *AuditService) record(ctx context.Context, entry *audit.Entry, err error) error {
+	return auditOutcome(err, s.append(ctx, entry, err))
+}
+
+// auditOutcome combines the error of a mutation with the errors of writing it
+// to the audit log
+func auditOutcome(err error, auditErrs ...error) error {
+	auditErr := errors.Join(auditErrs...)
+	switch {
+	case auditErr == nil:
+		return err
+	case err != nil:
+		return errors.Join(err, auditErr)
+	default:
+		return &auditError{err: auditErr}
+	}
+}
+
+// auditError is the error of a mutation that was made but not recorded in
+// the audit log. It is ErrAuditNotRecorded and unwraps to the sink's error.
+type auditError struct {
+	err error
+}
+
+func (e *auditError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrAuditNotRecorded, e.err)
+}
+
+func (e *auditError) Is(target error) bool {
+	return target == ErrAuditNotRecorded
+}
+
+func (e *auditError) Unwrap() error {
+	return e.err
+}
+
+// unaudited returns why a mutation that was made could not be recorded in
+// the audit log, or nil if err is nil or the mutation itself failed
+func unaudited(err error) error {
+	var e *auditError
+	if errors.As(err, &e) {
+		return e.err
+	}
+	return nil
+}
+
+// append completes the entry with the calling tool and outcome of the mutation
+// and writes it to the sink, returning only the sink's error.
+func (s *AuditService) append(ctx context.Context, entry *audit.Entry, err error) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	entry.Timestamp = time.Now().UTC()
+	if call, ok := audit.CallFromContext(ctx); ok {
+		entry.Caller = call.Caller
+		entry.Tool = call.Tool
+		entry.Arguments = call.Arguments
+	}
+	entry.Before = auditValue(entry.Before)
+	entry.After = auditValue(entry.After)
+	entry.Outcome = audit.OutcomeSuccess
+	if err != nil {
+		entry.Outcome = audit.OutcomeFailure
+		entry.Error = err.Error()
+	}
+
+	// Record the entry even if the caller has gone away mid-request
+	if err := s.client.auditSink.Append(context.WithoutCancel(ctx), entry); err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// auditSnapshot fetches the current state of a record for the audit log. It
+// returns nil when auditing is disabled or the record cannot be read.
+func auditSnapshot[T any](ctx context.Context, c *Client, get func(context.Context, string) (*T, error), id string) interface{} {
+	if !c.Audit.Enabled() {
+		return nil
+	}
+	v, err := get(ctx, id)
+	if err != nil || v == nil {
+		return nil
+	}
+	return v
+}
+
+// auditValue turns typed nil pointers into untyped nil so they are omitted from entries
+func auditValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil
+	}
+	return v
+}
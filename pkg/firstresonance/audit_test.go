@@ -0,0 +1,55 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSession is a client session of a connection
+type fakeSession struct {
+	id string
+}
+
+func (s *fakeSession) Initialize()                                         {}
+func (s *fakeSession) Initialized() bool                                   { return true }
+func (s *fakeSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s *fakeSession) SessionID() string                                   { return s.id }
+
+func TestAuditCallers(t *testing.T) {
+	callers := &auditCallers{}
+	hooks := &server.Hooks{}
+	callers.register(hooks)
+	s := server.NewMCPServer("test", "1.0", server.WithHooks(hooks))
+
+	connCtx, disconnect := context.WithCancel(context.Background())
+	session := &fakeSession{id: "s1"}
+	require.NoError(t, s.RegisterSession(connCtx, session))
+	ctx := s.WithContext(connCtx, session)
+	initialize, err := json.Marshal(mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      1,
+		Request: mcp.Request{Method: string(mcp.MethodInitialize)},
+		Params: map[string]interface{}{
+			"protocolVersion": mcp.LATEST_PROTOCOL_VERSION,
+			"clientInfo":      map[string]interface{}{"name": "agent", "version": "2.1"},
+		},
+	})
+	require.NoError(t, err)
+	s.HandleMessage(ctx, initialize)
+
+	assert.Equal(t, "agent/2.1 (session s1)", callers.caller(ctx))
+
+	s.UnregisterSession(session.SessionID())
+	disconnect()
+	assert.Eventually(t, func() bool {
+		_, ok := callers.sessions.Load("s1")
+		return !ok
+	}, time.Second, time.Millisecond, "the session is forgotten once it ends")
+}
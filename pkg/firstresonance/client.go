@@ -12,6 +12,11 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/audit"
+	"github.com/firstresonance/fr-mcp-server/pkg/index"
+	"github.com/firstresonance/fr-mcp-server/pkg/lifecycle"
+	"github.com/firstresonance/fr-mcp-server/pkg/uom"
 )
 
 // cacheItem represents a cached item with a timestamp
@@ -47,6 +52,10 @@ func NewClient(baseURL string, apiToken string, httpClient *http.Client) *Client
 		httpClient: httpClient,
 		cache:      &sync.Map{},
 		cacheTTL:   5 * time.Minute,
+		// Orders follow the default lifecycle unless SetOrderLifecycleFile replaces it
+		orderLifecycle: DefaultOrderLifecycle(),
+		// Parts have no base units unless SetUnitsFile gives them some
+		units: uom.Default(),
 	}
 
 	// Initialize services
@@ -56,23 +65,52 @@ func NewClient(baseURL string, apiToken string, httpClient *http.Client) *Client
 	client.Inventory = &InventoryService{client: client}
 	client.Search = &SearchService{client: client}
 	client.ABom = &ABomService{client: client}
+	client.Audit = &AuditService{client: client}
+	client.ReorderPoints = &ReorderPointsService{client: client}
+	client.ApprovedVendors = &ApprovedVendorsService{client: client}
+	client.Units = &UnitsService{client: client}
+	client.Costs = &CostsService{client: client}
+	client.SearchIndex = &SearchIndexService{client: client}
+	client.Import = &ImportService{client: client}
+	client.Export = &ExportService{client: client}
 
 	return client
 }
 
 // Client represents the First Resonance API client
 type Client struct {
-	baseURL    string
-	apiToken   string
-	httpClient *http.Client
-	Parts      *PartsService
-	Orders     *OrdersService
-	Suppliers  *SuppliersService
-	Inventory  *InventoryService
-	Search     *SearchService
-	ABom       *ABomService
-	cache      *sync.Map
-	cacheTTL   time.Duration
+	baseURL           string
+	apiToken          string
+	httpClient        *http.Client
+	Parts             *PartsService
+	Orders            *OrdersService
+	Suppliers         *SuppliersService
+	Inventory         *InventoryService
+	Search            *SearchService
+	ABom              *ABomService
+	Audit             *AuditService
+	ReorderPoints     *ReorderPointsService
+	ApprovedVendors   *ApprovedVendorsService
+	Units             *UnitsService
+	Costs             *CostsService
+	SearchIndex       *SearchIndexService
+	Import            *ImportService
+	Export            *ExportService
+	cache             *sync.Map
+	cacheTTL          time.Duration
+	auditSink         audit.Sink
+	reorderPointsPath string
+	vendorsPath       string
+	costTablePath     string
+	importDir         string
+	searchIndex       *index.Index
+	searchIndexPath   string
+	orderLifecycle    *lifecycle.Lifecycle
+	units             *uom.Registry
+	redactContacts    bool
+	// connectionsUnsupported holds the connection and other query fields the
+	// API does not have
+	connectionsUnsupported sync.Map
 }
 
 // SetCacheTTL sets the cache time-to-live
@@ -80,6 +118,53 @@ func (c *Client) SetCacheTTL(ttl time.Duration) {
 	c.cacheTTL = ttl
 }
 
+// SetAuditSink sets the sink that every create and update is recorded to.
+// A nil sink disables auditing.
+func (c *Client) SetAuditSink(sink audit.Sink) {
+	c.auditSink = sink
+}
+
+// SetReorderPointsFile sets the JSON file that per-part reorder points are read from
+func (c *Client) SetReorderPointsFile(path string) {
+	c.reorderPointsPath = path
+}
+
+// SetApprovedVendorsFile sets the JSON file the approved vendor list is read
+// from when the API does not keep one
+func (c *Client) SetApprovedVendorsFile(path string) {
+	c.vendorsPath = path
+}
+
+// SetCostTableFile sets the JSON file that per-part standard costs are read from
+func (c *Client) SetCostTableFile(path string) {
+	c.costTablePath = path
+}
+
+// SetImportDir sets the directory the import_records tool reads files from
+// and writes results files to. Without one, the tool is disabled.
+func (c *Client) SetImportDir(dir string) {
+	c.importDir = dir
+}
+
+// SetContactRedaction sets whether supplier contact info is masked in tool
+// and resource results, for models that should not see personal data
+func (c *Client) SetContactRedaction(redact bool) {
+	c.redactContacts = redact
+}
+
+// SetSearchIndexFile enables the local search index, persisted to path. The
+// index is loaded from path if it exists; it is filled by
+// SearchIndexService.Refresh.
+func (c *Client) SetSearchIndexFile(path string) error {
+	ix, err := index.Load(path)
+	if err != nil {
+		return err
+	}
+	c.searchIndex = ix
+	c.searchIndexPath = path
+	return nil
+}
+
 // ClearCache clears the entire cache
 func (c *Client) ClearCache() {
 	c.cache = &sync.Map{}
@@ -0,0 +1,164 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ListPartCosts creates a tool to list the standard and average cost of parts.
+func ListPartCosts(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("list_part_costs",
+			mcp.WithDescription(t("TOOL_LIST_PART_COSTS_DESCRIPTION", "List the standard and average cost per base unit of parts. Standard cost comes from the cost table, or else the price of the part's most recent order line; average cost is the mean price of its order lines, weighted by quantity")),
+			mcp.WithString("part_id",
+				mcp.Description("Part ID, to list one part's cost"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			partID, err := OptionalParam[string](request, "part_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			costs, err := client.Costs.List(ctx, partID)
+			if errors.Is(err, ErrTooManyResults) || isUnitError(err) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to list part costs: %w", err)
+			}
+			if partID != "" && len(costs) == 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("part %s has no cost table entry or priced order line", partID)), nil
+			}
+
+			r, err := json.Marshal(costs)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
+
+// BomCostRollup creates a tool to roll up the material cost of an assembly through its as-built BOM.
+func BomCostRollup(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("bom_cost_rollup",
+			mcp.WithDescription(t("TOOL_BOM_COST_ROLLUP_DESCRIPTION", "Compute the material cost of an assembly through its full multi-level as-built BOM. Consumed serials with an ABOM of their own, and unserialized subassemblies with an ABOM, are rolled up in turn; every other item is costed at its part's unit cost. Returns a cost tree, the total and the parts with no cost")),
+			mcp.WithString("abom_id",
+				mcp.Description("ABOM ID to roll up"),
+			),
+			mcp.WithString("serial_number",
+				mcp.Description("Serial number of the unit to roll up"),
+			),
+			mcp.WithString("part_id",
+				mcp.Description("Part ID; alone, rolls up the part's most recently updated ABOM, and with serial_number, narrows it to the part"),
+			),
+			mcp.WithString("cost_basis",
+				mcp.Description("Cost to value parts at (default standard)"),
+				mcp.Enum(CostBasisStandard, CostBasisAverage),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			opts := &CostRollupOptions{}
+			var err error
+			if opts.ABomID, err = OptionalParam[string](request, "abom_id"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if opts.SerialNumber, err = OptionalParam[string](request, "serial_number"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if opts.PartID, err = OptionalParam[string](request, "part_id"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if opts.CostBasis, err = OptionalParam[string](request, "cost_basis"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			rollup, err := client.ABom.CostRollup(ctx, opts)
+			if errors.Is(err, ErrInvalidCostRollup) || errors.Is(err, ErrInvalidCostBasis) || errors.Is(err, ErrGenealogyNotFound) ||
+				errors.Is(err, ErrTooManyResults) || isUnitError(err) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to roll up BOM cost: %w", err)
+			}
+
+			r, err := json.Marshal(rollup)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
+
+// InventoryValuationReport creates a tool to value on-hand inventory by location and part category.
+func InventoryValuationReport(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("inventory_valuation",
+			mcp.WithDescription(t("TOOL_INVENTORY_VALUATION_DESCRIPTION", "Report the value of on-hand inventory at standard or average cost, totalled by location and by part category (the part's type), with a line per part and location and the parts that have no cost")),
+			mcp.WithString("location",
+				mcp.Description("Value only this location"),
+			),
+			mcp.WithString("part_id",
+				mcp.Description("Value only this part"),
+			),
+			mcp.WithString("cost_basis",
+				mcp.Description("Cost to value stock at (default standard)"),
+				mcp.Enum(CostBasisStandard, CostBasisAverage),
+			),
+			mcp.WithString("format",
+				mcp.Description("Output format (default markdown)"),
+				mcp.Enum("markdown", "json"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			opts := &InventoryValuationOptions{}
+			var err error
+			if opts.Location, err = OptionalParam[string](request, "location"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if opts.PartID, err = OptionalParam[string](request, "part_id"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if opts.CostBasis, err = OptionalParam[string](request, "cost_basis"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			format, err := OptionalParam[string](request, "format")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			valuation, err := client.Inventory.Valuation(ctx, opts)
+			if errors.Is(err, ErrInvalidCostBasis) || errors.Is(err, ErrTooManyResults) || isUnitError(err) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to value inventory: %w", err)
+			}
+
+			if format == "json" {
+				r, err := json.Marshal(valuation)
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal response: %w", err)
+				}
+				return mcp.NewToolResultText(string(r)), nil
+			}
+			return mcp.NewToolResultText(ValuationMarkdown(valuation)), nil
+		}
+}
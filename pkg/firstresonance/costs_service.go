@@ -0,0 +1,217 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/dates"
+)
+
+// ErrInvalidCostBasis is returned for a cost basis other than standard or average
+var ErrInvalidCostBasis = errors.New("invalid cost basis")
+
+// costTable reads the standard costs from the configured file. Without one
+// there are no standard costs, and parts are costed from their order lines.
+func (s *CostsService) costTable() ([]*CostTableEntry, error) {
+	if s.client.costTablePath == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(s.client.costTablePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cost table: %w", err)
+	}
+
+	var entries []*CostTableEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing cost table: %w", err)
+	}
+	seen := map[string]bool{}
+	for i, e := range entries {
+		if e.PartID == "" {
+			return nil, fmt.Errorf("cost table entry %d has no part_id", i)
+		}
+		if e.StandardCost < 0 {
+			return nil, fmt.Errorf("cost table entry for part %s has a negative cost", e.PartID)
+		}
+		if seen[e.PartID] {
+			return nil, fmt.Errorf("cost table has more than one entry for part %s", e.PartID)
+		}
+		seen[e.PartID] = true
+	}
+
+	return entries, nil
+}
+
+// pricedLine is an order line's price per base unit of its part
+type pricedLine struct {
+	price    float64
+	quantity float64
+	unit     string
+	date     time.Time
+}
+
+// Costs returns the cost per base unit of every part in the cost table or on
+// a priced order line, by part ID. Lines of cancelled orders, and lines whose
+// unit does not convert to the part's base unit, are left out. For a part
+// without a base unit, lines are converted to the base unit of their unit's
+// dimension, and only those in the unit of its cost table entry, or else of
+// its most recent line, are counted.
+func (s *CostsService) Costs(ctx context.Context) (map[string]*PartCost, error) {
+	entries, err := s.costTable()
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.client.Orders.analysisOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := map[string][]pricedLine{}
+	for _, order := range orders {
+		if order.Status == OrderStatusCancelled {
+			continue
+		}
+		for _, item := range order.Items {
+			if item.PartID == "" || item.UnitPrice <= 0 || item.Quantity <= 0 {
+				continue
+			}
+			factor, unit, err := s.client.units.BaseFactor(item.PartID, item.Unit)
+			if err != nil {
+				continue
+			}
+			line := pricedLine{price: item.UnitPrice / factor, quantity: item.Quantity * factor, unit: unit}
+			date := item.ReceivedDate
+			if date == "" {
+				date = order.DueDate
+			}
+			if d, err := dates.Parse(date); err == nil {
+				line.date = d
+			}
+			lines[item.PartID] = append(lines[item.PartID], line)
+		}
+	}
+
+	costs := map[string]*PartCost{}
+	for _, e := range entries {
+		factor, unit, err := s.client.units.BaseFactor(e.PartID, e.Unit)
+		if err != nil {
+			return nil, fmt.Errorf("cost table entry for part %s: %w", e.PartID, err)
+		}
+		costs[e.PartID] = &PartCost{
+			PartID:         e.PartID,
+			Unit:           unit,
+			StandardCost:   roundCost(e.StandardCost / factor),
+			StandardSource: CostSourceTable,
+		}
+	}
+	for partID, partLines := range lines {
+		// The most recent line sets the standard cost of a part without a
+		// cost table entry; undated lines count as older than dated ones
+		latest := partLines[0]
+		for _, line := range partLines[1:] {
+			if !line.date.Before(latest.date) {
+				latest = line
+			}
+		}
+		cost := costs[partID]
+		if cost == nil {
+			cost = &PartCost{
+				PartID:         partID,
+				Unit:           latest.unit,
+				StandardCost:   roundCost(latest.price),
+				StandardSource: CostSourceLastOrder,
+			}
+			costs[partID] = cost
+		}
+
+		var spend, quantity float64
+		for _, line := range partLines {
+			if line.unit != cost.Unit {
+				continue
+			}
+			spend += line.price * line.quantity
+			quantity += line.quantity
+			cost.OrderLines++
+		}
+		if quantity > 0 {
+			average := roundCost(spend / quantity)
+			cost.AverageCost = &average
+		}
+	}
+
+	return costs, nil
+}
+
+// List returns the costs of every costed part, or of one part, by part ID
+func (s *CostsService) List(ctx context.Context, partID string) ([]*PartCost, error) {
+	costs, err := s.Costs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*PartCost, 0, len(costs))
+	for _, cost := range costs {
+		if partID == "" || cost.PartID == partID {
+			list = append(list, cost)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].PartID < list[j].PartID
+	})
+	return list, nil
+}
+
+// costBasis validates a cost basis, by default standard
+func costBasis(basis string) (string, error) {
+	switch basis {
+	case "":
+		return CostBasisStandard, nil
+	case CostBasisStandard, CostBasisAverage:
+		return basis, nil
+	default:
+		return "", fmt.Errorf("%w: cost_basis must be %s or %s, not %q", ErrInvalidCostBasis, CostBasisStandard, CostBasisAverage, basis)
+	}
+}
+
+// unitCost returns a part's cost per base unit on a basis. A part with no
+// priced order lines is costed at its standard cost on either basis.
+func unitCost(costs map[string]*PartCost, partID, basis string) (float64, bool) {
+	cost, ok := costs[partID]
+	if !ok {
+		return 0, false
+	}
+	if basis == CostBasisAverage && cost.AverageCost != nil {
+		return *cost.AverageCost, true
+	}
+	return cost.StandardCost, true
+}
+
+// stockUnitCost returns a part's cost per unit of its stock on a basis,
+// given the unit StockQuantity counts its stock in. A cost in another unit,
+// such as one per kilogram of a part counted in each, can't cost the stock.
+func (s *CostsService) stockUnitCost(costs map[string]*PartCost, partID, unit, basis string) (float64, bool) {
+	cost, ok := unitCost(costs, partID, basis)
+	if !ok {
+		return 0, false
+	}
+	if _, costUnit, err := s.client.Units.StockQuantity(partID, 0, costs[partID].Unit); err != nil || costUnit != unit {
+		return 0, false
+	}
+	return cost, true
+}
+
+// roundCost keeps a unit cost to six decimal places
+func roundCost(cost float64) float64 {
+	return math.Round(cost*1e6) / 1e6
+}
+
+// roundMoney keeps an extended cost or value to whole cents
+func roundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
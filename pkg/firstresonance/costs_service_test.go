@@ -0,0 +1,50 @@
+package firstresonance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/uom"
+)
+
+func TestStockUnitCost(t *testing.T) {
+	client := NewClient("http://localhost", "token", nil)
+	units := uom.Default()
+	units.Parts = map[string]uom.PartUnits{"cable": {BaseUnit: "m"}}
+	require.NoError(t, units.Validate())
+	client.units = units
+
+	costs := map[string]*PartCost{
+		"washer":  {PartID: "washer", Unit: "ea", StandardCost: 0.1},
+		"nut":     {PartID: "nut", Unit: "", StandardCost: 0.2},
+		"epoxy":   {PartID: "epoxy", Unit: "kg", StandardCost: 30},
+		"cable":   {PartID: "cable", Unit: "m", StandardCost: 0.85},
+		"grommet": {PartID: "grommet", Unit: "ea", StandardCost: 0.3},
+	}
+
+	for _, tc := range []struct {
+		partID string
+		want   float64
+		ok     bool
+	}{
+		{partID: "washer", want: 0.1, ok: true},
+		{partID: "nut", want: 0.2, ok: true},
+		{partID: "epoxy"},
+		{partID: "cable", want: 0.85, ok: true},
+		{partID: "bolt"},
+	} {
+		_, unit, err := client.Units.StockQuantity(tc.partID, 10, "")
+		require.NoError(t, err, tc.partID)
+
+		cost, ok := client.Costs.stockUnitCost(costs, tc.partID, unit, CostBasisStandard)
+
+		assert.Equal(t, tc.ok, ok, tc.partID)
+		assert.Equal(t, tc.want, cost, tc.partID)
+	}
+
+	_, unit, err := client.Units.StockQuantity("washer", 10, "")
+	require.NoError(t, err)
+	assert.Equal(t, "ea", unit, "stock of a part without units is counted in each")
+}
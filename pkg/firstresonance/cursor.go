@@ -0,0 +1,246 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// defaultPerPage is the page size used when none is requested
+const defaultPerPage = 30
+
+// maxPerPage is the largest page size a cursor is issued or accepted for
+const maxPerPage = 100
+
+// ErrInvalidCursor is returned when a cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// errConnectionsUnsupported is returned when the API has no connection field for a list
+var errConnectionsUnsupported = errors.New("connection cursors are not supported")
+
+// Page is one page of a list, with what is needed to fetch the next
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// NextCursor is passed back as the cursor to fetch the next page
+	NextCursor string `json:"nextCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+	// Total is the size of the full result set, when the API reports it
+	Total *int `json:"total,omitempty"`
+}
+
+// completePage wraps a full result set as a single page
+func completePage[T any](items []T) *Page[T] {
+	total := len(items)
+	return &Page[T]{Items: items, Total: &total}
+}
+
+// pageCursor is the decoded form of an opaque cursor. It holds either a
+// GraphQL connection cursor or, when the API has no connection for the list,
+// a page offset, along with a key of the filters it was issued for.
+type pageCursor struct {
+	After   string `json:"a,omitempty"`
+	Page    int    `json:"p,omitempty"`
+	PerPage int    `json:"n"`
+	Filters string `json:"f,omitempty"`
+}
+
+// filtersKey returns a short key of a list's query filters and the filter
+// expression its pages are then filtered by, so a cursor can't be reused with
+// different ones. Lists with no filters have an empty key.
+func filtersKey(filters map[string]interface{}, expr string) string {
+	if len(filters) == 0 && expr == "" {
+		return ""
+	}
+	// Map keys are marshaled in sorted order, so equal filters have equal keys
+	data, _ := json.Marshal(struct {
+		Filters map[string]interface{} `json:"f,omitempty"`
+		Expr    string                 `json:"e,omitempty"`
+	}{filters, expr})
+	h := fnv.New64a()
+	h.Write(data)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// encodeCursor encodes a cursor for returning to the client
+func encodeCursor(c pageCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor decodes a cursor returned by encodeCursor
+func decodeCursor(s string) (pageCursor, error) {
+	var c pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	if c.PerPage < 1 || c.PerPage > maxPerPage || c.Page < 0 || (c.After == "" && c.Page == 0) {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// connection is one page of a GraphQL connection
+type connection[T any] struct {
+	TotalCount *int `json:"totalCount"`
+	PageInfo   struct {
+		HasNextPage bool   `json:"hasNextPage"`
+		EndCursor   string `json:"endCursor"`
+	} `json:"pageInfo"`
+	Nodes []T `json:"nodes"`
+}
+
+// connectionFetcher fetches the page of a connection after the given cursor
+type connectionFetcher[T any] func(ctx context.Context, first int, after string) (*connection[T], error)
+
+// listPage fetches the page of a list that the cursor points to. Without a
+// cursor it starts at the page in opts. Connection cursors are preferred,
+// since they stay stable when rows are added or removed mid-scan; lists
+// without a connection in the API fall back to page offsets. Pages hold at
+// most maxPerPage items. A cursor issued for other filters is rejected.
+func listPage[T any](ctx context.Context, cursor string, opts ListOptions, filters map[string]interface{}, conn connectionFetcher[T], offset pageFetcher[T]) (*Page[T], error) {
+	key := filtersKey(filters, opts.Filter)
+	pc := pageCursor{Page: max(opts.Page, 1), PerPage: min(opts.PerPage, maxPerPage), Filters: key}
+	if pc.PerPage <= 0 {
+		pc.PerPage = defaultPerPage
+	}
+	if cursor != "" {
+		var err error
+		if pc, err = decodeCursor(cursor); err != nil {
+			return nil, err
+		}
+		if pc.Filters != key {
+			return nil, fmt.Errorf("%w: it was returned for different filters", ErrInvalidCursor)
+		}
+	}
+
+	// Connections can only start from the first page or continue from a connection cursor
+	if pc.After != "" || pc.Page == 1 {
+		c, err := conn(ctx, pc.PerPage, pc.After)
+		switch {
+		case err == nil:
+			page := &Page[T]{Items: c.Nodes, HasMore: c.PageInfo.HasNextPage, Total: c.TotalCount}
+			if page.Items == nil {
+				page.Items = []T{}
+			}
+			if page.HasMore {
+				page.NextCursor = encodeCursor(pageCursor{After: c.PageInfo.EndCursor, PerPage: pc.PerPage, Filters: key})
+			}
+			return page, nil
+		case !errors.Is(err, errConnectionsUnsupported):
+			return nil, err
+		case pc.After != "":
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	items, err := offset(ctx, pc.Page, pc.PerPage)
+	if err != nil {
+		return nil, err
+	}
+	page := &Page[T]{Items: items, HasMore: len(items) == pc.PerPage}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if page.HasMore {
+		page.NextCursor = encodeCursor(pageCursor{Page: pc.Page + 1, PerPage: pc.PerPage, Filters: key})
+	}
+	return page, nil
+}
+
+// fetchConnection runs a connection query and returns the connection at
+// field. It returns errConnectionsUnsupported if the API has no such field,
+// and remembers this so the query is not retried.
+func fetchConnection[T any](ctx context.Context, c *Client, field, query string, filters map[string]interface{}, first int, after string) (*connection[T], error) {
+	if _, unsupported := c.connectionsUnsupported.Load(field); unsupported {
+		return nil, errConnectionsUnsupported
+	}
+
+	// Create variables for the query
+	variables := map[string]interface{}{
+		"first": first,
+	}
+	for k, v := range filters {
+		variables[k] = v
+	}
+	if after != "" {
+		variables["after"] = after
+	}
+
+	// Create the request body
+	requestBody := map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	}
+
+	// Marshal the request body to JSON
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request body: %w", err)
+	}
+
+	// Create the HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/graphql", strings.NewReader(string(jsonBody)))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+
+	// Send the request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read the response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	// Check for non-200 status codes
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	// Parse the response
+	var result struct {
+		Data   map[string]*connection[T] `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+
+	// Check for GraphQL errors, telling a missing connection field apart
+	if len(result.Errors) > 0 {
+		if strings.Contains(result.Errors[0].Message, "Cannot query field \""+field+"\"") {
+			c.connectionsUnsupported.Store(field, true)
+			return nil, errConnectionsUnsupported
+		}
+		return nil, fmt.Errorf("GraphQL error: %s", result.Errors[0].Message)
+	}
+
+	conn := result.Data[field]
+	if conn == nil {
+		return nil, fmt.Errorf("missing %s in response", field)
+	}
+
+	return conn, nil
+}
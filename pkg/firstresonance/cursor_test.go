@@ -0,0 +1,289 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	t.Run("round trips", func(t *testing.T) {
+		for _, c := range []pageCursor{
+			{After: "Y3Vyc29yOjQy", PerPage: 30},
+			{Page: 3, PerPage: 100, Filters: filtersKey(map[string]interface{}{"status": "active"}, "")},
+		} {
+			decoded, err := decodeCursor(encodeCursor(c))
+			require.NoError(t, err)
+			assert.Equal(t, c, decoded)
+		}
+	})
+
+	t.Run("rejects invalid cursors", func(t *testing.T) {
+		for _, cursor := range []string{
+			"not base64!",
+			encodeCursor(pageCursor{PerPage: 30}),
+			encodeCursor(pageCursor{Page: 2, PerPage: 0}),
+			encodeCursor(pageCursor{Page: 2, PerPage: 101}),
+			encodeCursor(pageCursor{Page: -1, After: "x", PerPage: 30}),
+		} {
+			_, err := decodeCursor(cursor)
+			assert.ErrorIs(t, err, ErrInvalidCursor, cursor)
+		}
+	})
+
+	t.Run("keys filters by value", func(t *testing.T) {
+		assert.Empty(t, filtersKey(nil, ""))
+		a := filtersKey(map[string]interface{}{"status": "active", "type": "mechanical"}, "")
+		b := filtersKey(map[string]interface{}{"type": "mechanical", "status": "active"}, "")
+		assert.Equal(t, a, b)
+		assert.NotEqual(t, a, filtersKey(map[string]interface{}{"status": "draft", "type": "mechanical"}, ""))
+		assert.NotEqual(t, a, filtersKey(map[string]interface{}{"status": "active", "type": "mechanical"}, "name ~ bolt"), "keys the filter expression")
+		assert.NotEmpty(t, filtersKey(nil, "name ~ bolt"))
+	})
+}
+
+// fakeList serves the items 1..total as a connection, or as page offsets
+// when connections are unsupported
+type fakeList struct {
+	total       int
+	unsupported bool
+	connCalls   int
+	offsetCalls int
+}
+
+func (f *fakeList) conn(_ context.Context, first int, after string) (*connection[int], error) {
+	f.connCalls++
+	if f.unsupported {
+		return nil, errConnectionsUnsupported
+	}
+	start := 0
+	if after != "" {
+		if err := json.Unmarshal([]byte(after), &start); err != nil {
+			return nil, err
+		}
+	}
+	c := &connection[int]{TotalCount: &f.total, Nodes: []int{}}
+	for i := start + 1; i <= min(start+first, f.total); i++ {
+		c.Nodes = append(c.Nodes, i)
+	}
+	end := start + len(c.Nodes)
+	c.PageInfo.HasNextPage = end < f.total
+	c.PageInfo.EndCursor = string(mustJSON(end))
+	return c, nil
+}
+
+func (f *fakeList) offset(_ context.Context, page, perPage int) ([]int, error) {
+	f.offsetCalls++
+	items := []int{}
+	for i := (page-1)*perPage + 1; i <= min(page*perPage, f.total); i++ {
+		items = append(items, i)
+	}
+	return items, nil
+}
+
+func mustJSON(v interface{}) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// allPages follows cursors from the first page to the last
+func allPages(t *testing.T, f *fakeList, opts ListOptions, filters map[string]interface{}) ([]int, []*Page[int]) {
+	var items []int
+	var pages []*Page[int]
+	cursor := ""
+	for {
+		page, err := listPage(context.Background(), cursor, opts, filters, f.conn, f.offset)
+		require.NoError(t, err)
+		items = append(items, page.Items...)
+		pages = append(pages, page)
+		if !page.HasMore {
+			return items, pages
+		}
+		require.NotEmpty(t, page.NextCursor)
+		cursor = page.NextCursor
+	}
+}
+
+func TestListPage(t *testing.T) {
+	ctx := context.Background()
+	filters := map[string]interface{}{"status": "active"}
+
+	tests := []struct {
+		name        string
+		unsupported bool
+		total       int
+		opts        ListOptions
+		wantPages   int
+		wantTotal   bool
+	}{
+		{name: "connection", total: 25, opts: ListOptions{PerPage: 10}, wantPages: 3, wantTotal: true},
+		{name: "connection with default page size", total: 25, wantPages: 1, wantTotal: true},
+		{name: "page offsets", unsupported: true, total: 25, opts: ListOptions{PerPage: 10}, wantPages: 3},
+		{name: "page offsets with a full last page", unsupported: true, total: 20, opts: ListOptions{PerPage: 10}, wantPages: 3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &fakeList{total: tc.total, unsupported: tc.unsupported}
+			items, pages := allPages(t, f, tc.opts, filters)
+
+			require.Len(t, items, tc.total)
+			for i, item := range items {
+				assert.Equal(t, i+1, item)
+			}
+			assert.Len(t, pages, tc.wantPages)
+			for _, page := range pages {
+				assert.Equal(t, tc.wantTotal, page.Total != nil)
+			}
+			if tc.unsupported {
+				assert.Equal(t, tc.wantPages, f.offsetCalls)
+				assert.Equal(t, 1, f.connCalls, "connections are tried from the first page only")
+			} else {
+				assert.Zero(t, f.offsetCalls)
+			}
+		})
+	}
+
+	t.Run("starts page offsets at the requested page", func(t *testing.T) {
+		f := &fakeList{total: 25}
+		page, err := listPage(ctx, "", ListOptions{Page: 2, PerPage: 10}, nil, f.conn, f.offset)
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, page.Items)
+		assert.Zero(t, f.connCalls, "a connection can't start mid-list")
+	})
+
+	t.Run("rejects a cursor reused with other filters", func(t *testing.T) {
+		for _, unsupported := range []bool{false, true} {
+			f := &fakeList{total: 25, unsupported: unsupported}
+			page, err := listPage(ctx, "", ListOptions{PerPage: 10}, filters, f.conn, f.offset)
+			require.NoError(t, err)
+
+			_, err = listPage(ctx, page.NextCursor, ListOptions{}, map[string]interface{}{"status": "draft"}, f.conn, f.offset)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+			_, err = listPage(ctx, page.NextCursor, ListOptions{}, nil, f.conn, f.offset)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+			_, err = listPage(ctx, page.NextCursor, ListOptions{}, filters, f.conn, f.offset)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("rejects a cursor reused with another filter expression", func(t *testing.T) {
+		f := &fakeList{total: 25}
+		page, err := listPage(ctx, "", ListOptions{PerPage: 10, Filter: "status = active"}, filters, f.conn, f.offset)
+		require.NoError(t, err)
+
+		_, err = listPage(ctx, page.NextCursor, ListOptions{Filter: "status = active and name ~ bolt"}, filters, f.conn, f.offset)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+		_, err = listPage(ctx, page.NextCursor, ListOptions{Filter: "status = active"}, filters, f.conn, f.offset)
+		assert.NoError(t, err)
+	})
+
+	t.Run("issues cursors for at most the largest page size", func(t *testing.T) {
+		for _, unsupported := range []bool{false, true} {
+			f := &fakeList{total: 250, unsupported: unsupported}
+			page, err := listPage(ctx, "", ListOptions{PerPage: 500}, nil, f.conn, f.offset)
+			require.NoError(t, err)
+			assert.Len(t, page.Items, maxPerPage)
+
+			page, err = listPage(ctx, page.NextCursor, ListOptions{}, nil, f.conn, f.offset)
+			require.NoError(t, err, "the cursor is accepted")
+			assert.Len(t, page.Items, maxPerPage)
+		}
+	})
+
+	t.Run("rejects a connection cursor once connections are unsupported", func(t *testing.T) {
+		f := &fakeList{total: 25}
+		page, err := listPage(ctx, "", ListOptions{PerPage: 10}, nil, f.conn, f.offset)
+		require.NoError(t, err)
+
+		f.unsupported = true
+		_, err = listPage(ctx, page.NextCursor, ListOptions{}, nil, f.conn, f.offset)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("returns other connection errors", func(t *testing.T) {
+		failed := errors.New("connection failed")
+		conn := func(context.Context, int, string) (*connection[int], error) { return nil, failed }
+		f := &fakeList{total: 25}
+
+		_, err := listPage(ctx, "", ListOptions{}, nil, conn, f.offset)
+		assert.ErrorIs(t, err, failed)
+		assert.Zero(t, f.offsetCalls)
+	})
+}
+
+func TestFetchConnection(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sends filters and the cursor and returns the connection", func(t *testing.T) {
+		var variables map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			var body struct {
+				Variables map[string]interface{} `json:"variables"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			variables = body.Variables
+			_, _ = w.Write([]byte(`{"data":{"partsConnection":{"totalCount":3,"pageInfo":{"hasNextPage":true,"endCursor":"c2"},"nodes":[{"id":"1"},{"id":"2"}]}}}`))
+		}))
+		defer server.Close()
+		client := NewClient(server.URL, "token", nil)
+
+		c, err := fetchConnection[*Part](ctx, client, "partsConnection", "query", map[string]interface{}{"status": "active"}, 2, "c0")
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"status": "active", "first": float64(2), "after": "c0"}, variables)
+		require.Len(t, c.Nodes, 2)
+		assert.Equal(t, "2", c.Nodes[1].ID)
+		assert.Equal(t, 3, *c.TotalCount)
+		assert.True(t, c.PageInfo.HasNextPage)
+		assert.Equal(t, "c2", c.PageInfo.EndCursor)
+	})
+
+	t.Run("falls back to page offsets when the field is missing", func(t *testing.T) {
+		var graphqlCalls, restCalls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			graphqlCalls.Add(1)
+			_, _ = w.Write([]byte(`{"errors":[{"message":"Cannot query field \"partsConnection\" on type \"Query\"."}]}`))
+		}))
+		defer server.Close()
+		client := NewClient(server.URL, "token", nil)
+		conn := func(ctx context.Context, first int, after string) (*connection[*Part], error) {
+			return fetchConnection[*Part](ctx, client, "partsConnection", "query", nil, first, after)
+		}
+		offset := func(_ context.Context, page, perPage int) ([]*Part, error) {
+			restCalls.Add(1)
+			return []*Part{{ID: "1"}}, nil
+		}
+
+		for range 2 {
+			page, err := listPage(ctx, "", ListOptions{PerPage: 10}, nil, conn, offset)
+			require.NoError(t, err)
+			assert.Len(t, page.Items, 1)
+			assert.Nil(t, page.Total)
+			assert.False(t, page.HasMore)
+		}
+		assert.Equal(t, int32(1), graphqlCalls.Load(), "a missing field is remembered")
+		assert.Equal(t, int32(2), restCalls.Load())
+	})
+
+	t.Run("returns other GraphQL errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"errors":[{"message":"Cannot query field \"name\" on type \"Part\"."}]}`))
+		}))
+		defer server.Close()
+		client := NewClient(server.URL, "token", nil)
+
+		_, err := fetchConnection[*Part](ctx, client, "partsConnection", "query", nil, 10, "")
+
+		assert.EqualError(t, err, `GraphQL error: Cannot query field "name" on type "Part".`)
+		assert.NotErrorIs(t, err, errConnectionsUnsupported)
+	})
+}
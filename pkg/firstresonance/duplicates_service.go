@@ -0,0 +1,67 @@
+package firstresonance
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/dedupe"
+)
+
+// FindDuplicates scans the parts matching opts and groups those that are
+// likely duplicates, with at least minConfidence similarity, into merge
+// candidates, most confident first
+func (s *PartsService) FindDuplicates(ctx context.Context, opts *ListPartsOptions, minConfidence float64) ([]*DuplicatePartCluster, error) {
+	parts, err := s.all(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := map[string]*Part{}
+	records := make([]dedupe.Record, 0, len(parts))
+	for _, part := range parts {
+		byID[part.ID] = part
+		records = append(records, dedupe.Record{ID: part.ID, Name: part.Name, Description: part.Description})
+	}
+
+	clusters := []*DuplicatePartCluster{}
+	for _, c := range dedupe.Find(records, minConfidence) {
+		cluster := &DuplicatePartCluster{Confidence: c.Confidence, Matches: c.Matches}
+		for _, id := range c.IDs {
+			cluster.Parts = append(cluster.Parts, byID[id])
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters, nil
+}
+
+// SimilarTo returns the existing parts that are likely duplicates of part,
+// with at least minConfidence similarity, most similar first
+func (s *PartsService) SimilarTo(ctx context.Context, part *Part, minConfidence float64) ([]*SimilarPart, error) {
+	parts, err := s.all(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := map[string]*Part{}
+	records := make([]dedupe.Record, 0, len(parts))
+	for _, p := range parts {
+		byID[p.ID] = p
+		records = append(records, dedupe.Record{ID: p.ID, Name: p.Name, Description: p.Description})
+	}
+
+	similar := []*SimilarPart{}
+	candidate := dedupe.Record{ID: part.ID, Name: part.Name, Description: part.Description}
+	for _, m := range dedupe.Similar(candidate, records, minConfidence) {
+		similar = append(similar, &SimilarPart{Part: byID[m.B], Confidence: m.Confidence})
+	}
+	return similar, nil
+}
+
+// all lists every part matching opts, up to fetchAllLimit
+func (s *PartsService) all(ctx context.Context, opts *ListPartsOptions) ([]*Part, error) {
+	parts, err := collect(s.Iter(ctx, opts, fetchAllIterOptions()), fetchAllLimit)
+	if err != nil {
+		return nil, fmt.Errorf("error listing parts: %w", err)
+	}
+	return parts, nil
+}
@@ -0,0 +1,232 @@
+package firstresonance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/exporter"
+	"github.com/firstresonance/fr-mcp-server/pkg/filter"
+)
+
+// Entities that can be exported
+const (
+	ExportEntityPart          = "part"
+	ExportEntityOrder         = "order"
+	ExportEntitySupplier      = "supplier"
+	ExportEntityInventoryItem = "inventory_item"
+	ExportEntityABom          = "abom"
+)
+
+// ExportEntities are the entities that can be exported
+var ExportEntities = []string{ExportEntityPart, ExportEntityOrder, ExportEntitySupplier, ExportEntityInventoryItem, ExportEntityABom}
+
+// Layouts of exported ABOMs
+const (
+	// ABomLayoutNested writes a record per ABOM with its items as a list
+	ABomLayoutNested = "nested"
+	// ABomLayoutFlat writes a record per ABOM item with the ABOM's fields repeated
+	ABomLayoutFlat = "flat"
+)
+
+// exportColumns are the columns of each entity, in output order
+var exportColumns = map[string][]exporter.Column{
+	ExportEntityPart: {
+		{Name: "id"}, {Name: "name"}, {Name: "description"}, {Name: "type"}, {Name: "status"},
+	},
+	ExportEntityOrder: {
+		{Name: "id"}, {Name: "customer_id"}, {Name: "supplier_id"}, {Name: "priority"}, {Name: "due_date"}, {Name: "status"}, {Name: "items"},
+	},
+	ExportEntitySupplier: {
+		{Name: "id"}, {Name: "name"}, {Name: "status"}, {Name: "contact_info"},
+	},
+	ExportEntityInventoryItem: {
+		{Name: "id"}, {Name: "part_id"}, {Name: "quantity", Type: exporter.Float}, {Name: "location"}, {Name: "status"},
+		{Name: "lot_number"}, {Name: "serial_number"}, {Name: "expiry_date"},
+	},
+	ExportEntityABom: {
+		{Name: "id"}, {Name: "name"}, {Name: "description"}, {Name: "version"}, {Name: "status"}, {Name: "created_at"}, {Name: "updated_at"}, {Name: "items"},
+		{Name: "part_id"}, {Name: "serial_number"},
+	},
+}
+
+// abomFlatColumns are the columns of ABOMs in the flat layout
+var abomFlatColumns = []exporter.Column{
+	{Name: "abom_id"}, {Name: "abom_name"}, {Name: "abom_version"}, {Name: "abom_status"},
+	{Name: "abom_part_id"}, {Name: "abom_serial_number"},
+	{Name: "item_id"}, {Name: "part_id"}, {Name: "quantity", Type: exporter.Float}, {Name: "unit"}, {Name: "notes"},
+	{Name: "lot_number"}, {Name: "serial_number"},
+}
+
+// ErrInvalidExport is returned when an export cannot start, such as for an unknown entity or field
+var ErrInvalidExport = errors.New("invalid export")
+
+// ExportOptions controls an export
+type ExportOptions struct {
+	// Entity is the type of record to export
+	Entity string
+	// Format is one of exporter.Formats
+	Format string
+	// Fields are the columns to write, in order; all columns if empty
+	Fields []string
+	// Filter is a filter expression records must match, as for the list tools
+	Filter string
+	// ABomLayout is ABomLayoutNested (the default) or ABomLayoutFlat
+	ABomLayout string
+}
+
+// ExportColumns returns the columns an export of an entity can write
+func ExportColumns(entity, abomLayout string) []exporter.Column {
+	if entity == ExportEntityABom && abomLayout == ABomLayoutFlat {
+		return abomFlatColumns
+	}
+	return exportColumns[entity]
+}
+
+// Export streams every record of opts.Entity matching opts.Filter to w,
+// returning how many records were written. The filter applies to ABOMs
+// before they are flattened. Supplier contact info is masked when the
+// client has contact redaction on.
+func (s *ExportService) Export(ctx context.Context, w io.Writer, opts *ExportOptions) (int, error) {
+	columns := ExportColumns(opts.Entity, opts.ABomLayout)
+	if columns == nil {
+		return 0, fmt.Errorf("%w: unknown entity %q, expected one of %s", ErrInvalidExport, opts.Entity, strings.Join(ExportEntities, ", "))
+	}
+	if opts.ABomLayout != "" && opts.ABomLayout != ABomLayoutNested && opts.ABomLayout != ABomLayoutFlat {
+		return 0, fmt.Errorf("%w: unknown ABOM layout %q, expected %s or %s", ErrInvalidExport, opts.ABomLayout, ABomLayoutNested, ABomLayoutFlat)
+	}
+	columns, err := exporter.Select(columns, opts.Fields)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidExport, err)
+	}
+
+	var fields filter.Fields
+	switch opts.Entity {
+	case ExportEntityPart:
+		fields = partFilterFields
+	case ExportEntityOrder:
+		fields = orderFilterFields
+	case ExportEntitySupplier:
+		fields = supplierFilterFields
+	case ExportEntityInventoryItem:
+		fields = inventoryItemFilterFields
+	case ExportEntityABom:
+		fields = abomFilterFields
+	}
+	var f *filter.Filter
+	eq := map[string]string{}
+	if opts.Filter != "" {
+		if f, err = filter.Parse(opts.Filter, fields); err != nil {
+			return 0, fmt.Errorf("%w: invalid filter: %w", ErrInvalidExport, err)
+		}
+		eq = pushdownEqualities(f)
+	}
+
+	out, err := exporter.NewWriter(w, opts.Format, columns)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidExport, err)
+	}
+	count := 0
+	write := func(record map[string]interface{}) error {
+		if err := out.Write(record); err != nil {
+			return fmt.Errorf("failed to write record %d: %w", count+1, err)
+		}
+		count++
+		return nil
+	}
+
+	// Narrow the queries by the filter's equality conditions
+	switch opts.Entity {
+	case ExportEntityPart:
+		err = exportSeq(s.client.Parts.Iter(ctx, &ListPartsOptions{Status: eq["status"], Type: eq["type"]}, nil), f, write)
+	case ExportEntityOrder:
+		err = exportSeq(s.client.Orders.Iter(ctx, &ListOrdersOptions{Status: eq["status"]}, nil), f, write)
+	case ExportEntitySupplier:
+		emit := write
+		if s.client.redactContacts {
+			// Mask contact info after filtering, so filters still see it
+			emit = func(record map[string]interface{}) error {
+				redactContactValues(record)
+				return write(record)
+			}
+		}
+		err = exportSeq(s.client.Suppliers.Iter(ctx, &ListSuppliersOptions{Status: eq["status"]}, nil), f, emit)
+	case ExportEntityInventoryItem:
+		err = exportSeq(s.client.Inventory.Iter(ctx, &ListInventoryItemsOptions{Status: eq["status"]}, nil), f, write)
+	case ExportEntityABom:
+		emit := write
+		if opts.ABomLayout == ABomLayoutFlat {
+			emit = func(record map[string]interface{}) error {
+				for _, row := range flattenABom(record) {
+					if err := write(row); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+		}
+		err = exportSeq(s.client.ABom.Iter(ctx, &ListABomsOptions{Status: eq["status"]}, nil), f, emit)
+	}
+	if err != nil {
+		return count, err
+	}
+	if err := out.Close(); err != nil {
+		return count, fmt.Errorf("failed to finish export: %w", err)
+	}
+	return count, nil
+}
+
+// exportSeq passes every item of seq matching the filter to write as a record
+func exportSeq[T any](seq iter.Seq2[T, error], f *filter.Filter, write func(map[string]interface{}) error) error {
+	for item, err := range seq {
+		if err != nil {
+			return err
+		}
+		record, err := recordOf(item)
+		if err != nil {
+			return err
+		}
+		if f != nil && !f.Match(record) {
+			continue
+		}
+		if err := write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flattenABom returns a record per item of an ABOM record, or a single
+// record without item fields if it has no items
+func flattenABom(abom map[string]interface{}) []map[string]interface{} {
+	base := map[string]interface{}{
+		"abom_id":            abom["id"],
+		"abom_name":          abom["name"],
+		"abom_version":       abom["version"],
+		"abom_status":        abom["status"],
+		"abom_part_id":       abom["part_id"],
+		"abom_serial_number": abom["serial_number"],
+	}
+	items, _ := abom["items"].([]interface{})
+	if len(items) == 0 {
+		return []map[string]interface{}{base}
+	}
+
+	rows := make([]map[string]interface{}, 0, len(items))
+	for _, raw := range items {
+		item, _ := raw.(map[string]interface{})
+		row := make(map[string]interface{}, len(abomFlatColumns))
+		for k, v := range base {
+			row[k] = v
+		}
+		row["item_id"] = item["id"]
+		for _, k := range []string{"part_id", "quantity", "unit", "notes", "lot_number", "serial_number"} {
+			row[k] = item[k]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
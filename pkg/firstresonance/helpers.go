@@ -1,6 +1,7 @@
 package firstresonance
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -22,12 +23,31 @@ func WithPagination() mcp.ToolOption {
 	}
 }
 
+// WithCursorPagination returns a ToolOption that adds "page", "perPage" and "cursor" parameters to the tool.
+func WithCursorPagination() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		WithPagination()(tool)
+
+		mcp.WithString("cursor",
+			mcp.Description("Opaque cursor from the nextCursor of a previous call, to fetch the following page; page and perPage are ignored when set"),
+		)(tool)
+	}
+}
+
+// WithFetchAll returns a ToolOption that adds a "fetch_all" parameter to the tool.
+func WithFetchAll() mcp.ToolOption {
+	return mcp.WithBoolean("fetch_all",
+		mcp.Description(fmt.Sprintf("Fetch every page and return the complete result set, up to %d items; page and perPage are ignored", fetchAllLimit)),
+	)
+}
+
 type paginationParams struct {
 	page    int
 	perPage int
+	cursor  string
 }
 
-// OptionalPaginationParams returns the "page" and "perPage" parameters from the request,
+// OptionalPaginationParams returns the "page", "perPage" and "cursor" parameters from the request,
 // or their default values if not present.
 func OptionalPaginationParams(r mcp.CallToolRequest) (paginationParams, error) {
 	page, err := OptionalIntParamWithDefault(r, "page", 1)
@@ -38,9 +58,14 @@ func OptionalPaginationParams(r mcp.CallToolRequest) (paginationParams, error) {
 	if err != nil {
 		return paginationParams{}, err
 	}
+	cursor, err := OptionalParam[string](r, "cursor")
+	if err != nil {
+		return paginationParams{}, err
+	}
 	return paginationParams{
 		page:    page,
 		perPage: perPage,
+		cursor:  cursor,
 	}, nil
 }
 
@@ -105,6 +130,24 @@ func OptionalParamOK[T any](r mcp.CallToolRequest, p string) (value T, ok bool,
 	return
 }
 
+// OptionalStringArrayParam is a helper function that can be used to fetch an optional string array parameter from the request.
+func OptionalStringArrayParam(r mcp.CallToolRequest, p string) ([]string, error) {
+	v, err := OptionalParam[[]interface{}](r, p)
+	if err != nil {
+		return nil, err
+	}
+
+	strs := make([]string, 0, len(v))
+	for _, item := range v {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("parameter %s is not of type []string", p)
+		}
+		strs = append(strs, s)
+	}
+	return strs, nil
+}
+
 // OptionalIntParam is a helper function that can be used to fetch an optional integer parameter from the request.
 func OptionalIntParam(r mcp.CallToolRequest, p string) (int, error) {
 	v, err := OptionalParam[float64](r, p)
@@ -126,3 +169,21 @@ func OptionalIntParamWithDefault(r mcp.CallToolRequest, p string, d int) (int, e
 	}
 	return v, nil
 }
+
+// auditWarnings splits a failure to record a mutation that succeeded in the
+// audit log off the mutation's error, as warnings to return with its result,
+// so the caller does not retry a change that was made
+func auditWarnings(err error) ([]string, error) {
+	if errors.Is(err, ErrAuditNotRecorded) {
+		return []string{err.Error()}, nil
+	}
+	return nil, err
+}
+
+// withWarnings adds a text content item to a tool result for every warning
+func withWarnings(result *mcp.CallToolResult, warnings []string) *mcp.CallToolResult {
+	for _, w := range warnings {
+		result.Content = append(result.Content, mcp.NewTextContent("Warning: "+w))
+	}
+	return result
+}
@@ -0,0 +1,152 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/importer"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxImportConcurrency caps how many rows an import applies at once
+const maxImportConcurrency = 16
+
+// ImportRecords creates a tool to bulk create or update records from a CSV or XLSX file.
+func ImportRecords(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("import_records",
+			mcp.WithDescription(t("TOOL_IMPORT_RECORDS_DESCRIPTION", "Bulk create or update parts, suppliers or inventory items from a CSV or XLSX file in the server's import directory. Rows with an id update that record; others are created. Runs as a dry run unless dry_run is false; a real run applies nothing if any row is invalid, and can be rerun to resume after failures")),
+			mcp.WithString("entity",
+				mcp.Required(),
+				mcp.Description("Type of record in every row"),
+				mcp.Enum(ImportEntities...),
+			),
+			mcp.WithString("file",
+				mcp.Required(),
+				mcp.Description("Path of the .csv or .xlsx file, relative to the import directory"),
+			),
+			mcp.WithString("sheet",
+				mcp.Description("Worksheet to read from an XLSX file (default the first)"),
+			),
+			mcp.WithObject("mapping",
+				mcp.Description(fmt.Sprintf("Column for each field, where not named the same. Part fields: %s. Supplier fields: %s. Inventory item fields: %s",
+					strings.Join(ImportFields(ImportEntityPart), ", "),
+					strings.Join(ImportFields(ImportEntitySupplier), ", "),
+					strings.Join(ImportFields(ImportEntityInventoryItem), ", "))),
+				mcp.AdditionalProperties(map[string]interface{}{"type": "string"}),
+			),
+			mcp.WithBoolean("dry_run",
+				mcp.Description("Only validate the rows and report what would change (default true)"),
+			),
+			mcp.WithNumber("concurrency",
+				mcp.Description(fmt.Sprintf("Rows applied at once (default %d, max %d)", importer.DefaultConcurrency, maxImportConcurrency)),
+				mcp.Min(1),
+				mcp.Max(maxImportConcurrency),
+			),
+			mcp.WithNumber("batch_size",
+				mcp.Description(fmt.Sprintf("Rows applied before checking whether the import was cancelled (default %d)", importer.DefaultBatchSize)),
+				mcp.Min(1),
+			),
+			mcp.WithString("results_file",
+				mcp.Description("JSON Lines file recording the result of every row, used to resume, relative to the import directory; may not be the imported file (default the file's path with .results.jsonl appended)"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			entity, err := requiredParam[string](request, "entity")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			file, err := requiredParam[string](request, "file")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			sheet, err := OptionalParam[string](request, "sheet")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			rawMapping, err := OptionalParam[map[string]interface{}](request, "mapping")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			mapping := map[string]string{}
+			for field, column := range rawMapping {
+				s, ok := column.(string)
+				if !ok {
+					return mcp.NewToolResultError(fmt.Sprintf("mapping for %s is not a column name", field)), nil
+				}
+				mapping[field] = s
+			}
+			dryRun, ok, err := OptionalParamOK[bool](request, "dry_run")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if !ok {
+				dryRun = true
+			}
+			concurrency, err := OptionalIntParamWithDefault(request, "concurrency", importer.DefaultConcurrency)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if concurrency < 1 || concurrency > maxImportConcurrency {
+				return mcp.NewToolResultError(fmt.Sprintf("concurrency must be between 1 and %d", maxImportConcurrency)), nil
+			}
+			batchSize, err := OptionalIntParamWithDefault(request, "batch_size", importer.DefaultBatchSize)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			resultsFile, err := OptionalParam[string](request, "results_file")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if resultsFile == "" {
+				resultsFile = file + ".results.jsonl"
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			// Files are confined to the import directory
+			if file, err = client.Import.Path(file); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if resultsFile, err = client.Import.Path(resultsFile); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			table, err := importer.ReadFile(file, sheet)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			report, err := client.Import.Import(ctx, table, &ImportOptions{
+				Entity:  entity,
+				Mapping: mapping,
+				Options: importer.Options{
+					DryRun:      dryRun,
+					Concurrency: concurrency,
+					BatchSize:   batchSize,
+					ResultsPath: resultsFile,
+					InputPath:   file,
+				},
+			})
+			if errors.Is(err, ErrInvalidImport) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil && report == nil {
+				return nil, fmt.Errorf("failed to import records: %w", err)
+			}
+
+			r, merr := json.Marshal(report)
+			if merr != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", merr)
+			}
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("%s: %s", err, string(r))), nil
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
@@ -0,0 +1,269 @@
+package firstresonance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/dates"
+	"github.com/firstresonance/fr-mcp-server/pkg/importer"
+)
+
+// Entities that can be imported
+const (
+	ImportEntityPart          = "part"
+	ImportEntitySupplier      = "supplier"
+	ImportEntityInventoryItem = "inventory_item"
+)
+
+// ImportEntities are the entities that can be imported
+var ImportEntities = []string{ImportEntityPart, ImportEntitySupplier, ImportEntityInventoryItem}
+
+// importFields are the fields of each entity that columns can map to. Rows
+// with an id update that record; rows without one create a record.
+var importFields = map[string][]string{
+	ImportEntityPart:          {"id", "name", "description", "type", "status"},
+	ImportEntitySupplier:      {"id", "name", "status", "contact_name", "contact_email", "contact_phone"},
+	ImportEntityInventoryItem: {"id", "part_id", "location", "quantity", "status", "lot_number", "serial_number", "expiry_date"},
+}
+
+// ErrInvalidImport is returned when an import cannot start, such as for an unknown entity or bad mapping
+var ErrInvalidImport = errors.New("invalid import")
+
+// ErrImportPath is returned for a file outside the import directory, or when
+// no import directory is set
+var ErrImportPath = errors.New("invalid import path")
+
+// ImportOptions controls an import
+type ImportOptions struct {
+	// Entity is the type of record in every row
+	Entity string
+	// Mapping gives the column for a field, where they are not named the same
+	Mapping map[string]string
+	importer.Options
+}
+
+// ImportFields returns the fields of an entity that columns can map to
+func ImportFields(entity string) []string {
+	return importFields[entity]
+}
+
+// Path returns the path of a file in the import directory. The name must be
+// relative to the directory and stay within it.
+func (s *ImportService) Path(name string) (string, error) {
+	if s.client.importDir == "" {
+		return "", fmt.Errorf("%w: imports are disabled, no import directory is set", ErrImportPath)
+	}
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("%w: %q must be a relative path within the import directory", ErrImportPath, name)
+	}
+	return filepath.Join(s.client.importDir, name), nil
+}
+
+// Import validates every row of the table as a record of opts.Entity and,
+// unless it is a dry run, creates or updates them
+func (s *ImportService) Import(ctx context.Context, table *importer.Table, opts *ImportOptions) (*importer.Report, error) {
+	fields, ok := importFields[opts.Entity]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown entity %q", ErrInvalidImport, opts.Entity)
+	}
+	rows, err := table.MapRows(fields, opts.Mapping)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+	}
+
+	var validate importer.Validator
+	switch opts.Entity {
+	case ImportEntityPart:
+		validate = s.validatePart
+	case ImportEntitySupplier:
+		validate = s.validateSupplier
+	case ImportEntityInventoryItem:
+		validate = s.validateInventoryItem
+	}
+	report, err := importer.Run(ctx, rows, validate, opts.Options)
+	if errors.Is(err, importer.ErrResultsPath) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+	}
+	return report, err
+}
+
+// validatePart checks a part row and returns the create or update it makes
+func (s *ImportService) validatePart(row importer.Row) (*importer.Op, error) {
+	v := row.Values
+	if id := v["id"]; id != "" {
+		update := &PartUpdateRequest{
+			Name:        optionalValue(v, "name"),
+			Description: optionalValue(v, "description"),
+			Type:        optionalValue(v, "type"),
+			Status:      optionalValue(v, "status"),
+		}
+		if *update == (PartUpdateRequest{}) {
+			return nil, errors.New("no fields to update")
+		}
+		return &importer.Op{Action: importer.ActionUpdate, Apply: func(ctx context.Context) (string, error) {
+			_, err := s.client.Parts.Update(ctx, id, update)
+			return importResult(id, err)
+		}}, nil
+	}
+
+	part := &Part{Name: v["name"], Description: v["description"], Type: v["type"], Status: v["status"]}
+	if part.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if part.Type == "" {
+		return nil, errors.New("type is required")
+	}
+	return &importer.Op{Action: importer.ActionCreate, Apply: func(ctx context.Context) (string, error) {
+		created, err := s.client.Parts.Create(ctx, part)
+		if created == nil {
+			return "", err
+		}
+		return importResult(created.ID, err)
+	}}, nil
+}
+
+// validateSupplier checks a supplier row and returns the create or update it makes
+func (s *ImportService) validateSupplier(row importer.Row) (*importer.Op, error) {
+	v := row.Values
+	var contact *ContactInfo
+	if v["contact_name"] != "" || v["contact_email"] != "" || v["contact_phone"] != "" {
+		contact = &ContactInfo{Contacts: []Contact{{
+			Name:  v["contact_name"],
+			Email: v["contact_email"],
+			Phone: v["contact_phone"],
+		}}}
+		if err := contact.Normalize(); err != nil {
+			return nil, err
+		}
+	}
+
+	if id := v["id"]; id != "" {
+		update := &SupplierUpdateRequest{
+			Name:   optionalValue(v, "name"),
+			Status: optionalValue(v, "status"),
+		}
+		if contact != nil {
+			update.ContactInfo = contact
+		}
+		if update.Name == nil && update.Status == nil && update.ContactInfo == nil {
+			return nil, errors.New("no fields to update")
+		}
+		return &importer.Op{Action: importer.ActionUpdate, Apply: func(ctx context.Context) (string, error) {
+			_, err := s.client.Suppliers.Update(ctx, id, update)
+			return importResult(id, err)
+		}}, nil
+	}
+
+	supplier := &Supplier{Name: v["name"], Status: v["status"], ContactInfo: contact}
+	if supplier.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	return &importer.Op{Action: importer.ActionCreate, Apply: func(ctx context.Context) (string, error) {
+		created, err := s.client.Suppliers.Create(ctx, supplier)
+		if created == nil {
+			return "", err
+		}
+		return importResult(created.ID, err)
+	}}, nil
+}
+
+// validateInventoryItem checks an inventory item row and returns the create or update it makes
+func (s *ImportService) validateInventoryItem(row importer.Row) (*importer.Op, error) {
+	v := row.Values
+	var quantity *float64
+	if raw := v["quantity"]; raw != "" {
+		q, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(q) || math.IsInf(q, 0) {
+			return nil, fmt.Errorf("quantity %q is not a number", raw)
+		}
+		if q < 0 {
+			return nil, fmt.Errorf("quantity %q is negative", raw)
+		}
+		quantity = &q
+	}
+	// Expiry dates are read in any format dates.Parse knows and stored as dates
+	var expiryDate *string
+	if raw := v["expiry_date"]; raw != "" {
+		expiry, err := dates.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("expiry_date: %w", err)
+		}
+		date := expiry.Format(time.DateOnly)
+		expiryDate = &date
+	}
+
+	if id := v["id"]; id != "" {
+		if v["part_id"] != "" {
+			return nil, errors.New("part_id cannot be changed on an existing item")
+		}
+		update := &InventoryItemUpdateRequest{
+			Quantity:     quantity,
+			Location:     optionalValue(v, "location"),
+			Status:       optionalValue(v, "status"),
+			LotNumber:    optionalValue(v, "lot_number"),
+			SerialNumber: optionalValue(v, "serial_number"),
+			ExpiryDate:   expiryDate,
+		}
+		if *update == (InventoryItemUpdateRequest{}) {
+			return nil, errors.New("no fields to update")
+		}
+		return &importer.Op{Action: importer.ActionUpdate, Apply: func(ctx context.Context) (string, error) {
+			_, err := s.client.Inventory.Update(ctx, id, update)
+			return importResult(id, err)
+		}}, nil
+	}
+
+	if v["part_id"] == "" {
+		return nil, errors.New("part_id is required")
+	}
+	if quantity == nil {
+		return nil, errors.New("quantity is required")
+	}
+	if _, _, err := s.client.Units.StockQuantity(v["part_id"], *quantity, ""); err != nil {
+		return nil, fmt.Errorf("quantity: %w", err)
+	}
+	item := &InventoryItem{
+		PartID:       v["part_id"],
+		Location:     v["location"],
+		Quantity:     *quantity,
+		Status:       v["status"],
+		LotNumber:    v["lot_number"],
+		SerialNumber: v["serial_number"],
+	}
+	if expiryDate != nil {
+		item.ExpiryDate = *expiryDate
+	}
+	if err := validateTracking(item); err != nil {
+		return nil, err
+	}
+	return &importer.Op{Action: importer.ActionCreate, Apply: func(ctx context.Context) (string, error) {
+		created, err := s.client.Inventory.Create(ctx, item)
+		if created == nil {
+			return "", err
+		}
+		return importResult(created.ID, err)
+	}}, nil
+}
+
+// importResult returns the ID of an imported row. A change that was made
+// but not recorded in the audit log is done, with the audit failure as a
+// warning, so the row is not applied again.
+func importResult(id string, err error) (string, error) {
+	if errors.Is(err, ErrAuditNotRecorded) {
+		return id, &importer.Warning{Err: err}
+	}
+	return id, err
+}
+
+// optionalValue returns a pointer to a row value, or nil if it is empty
+func optionalValue(values map[string]string, field string) *string {
+	if v, ok := values[field]; ok && v != "" {
+		return &v
+	}
+	return nil
+}
@@ -3,9 +3,13 @@ package firstresonance
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/dates"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -57,6 +61,9 @@ func GetInventoryItem(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.
 func ListInventoryItems(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("list_inventory_items",
 			mcp.WithDescription(t("TOOL_LIST_INVENTORY_ITEMS_DESCRIPTION", "List and filter inventory items")),
+			mcp.WithString("part_id",
+				mcp.Description("Filter by part ID"),
+			),
 			mcp.WithString("location",
 				mcp.Description("Filter by location"),
 			),
@@ -69,9 +76,15 @@ func ListInventoryItems(getClient GetClientFn, t TranslationHelperFunc) (tool mc
 			mcp.WithString("direction",
 				mcp.Description("Sort direction"),
 			),
-			WithPagination(),
+			WithFilter(inventoryItemFilterFields),
+			WithCursorPagination(),
+			WithFetchAll(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			partID, err := OptionalParam[string](request, "part_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 			location, err := OptionalParam[string](request, "location")
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
@@ -92,8 +105,17 @@ func ListInventoryItems(getClient GetClientFn, t TranslationHelperFunc) (tool mc
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
+			fetchAll, err := OptionalParam[bool](request, "fetch_all")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			listFilter, err := OptionalFilterParam(request, inventoryItemFilterFields)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 
 			opts := &ListInventoryItemsOptions{
+				PartID:    partID,
 				Location:  location,
 				Status:    status,
 				Sort:     sort,
@@ -104,25 +126,49 @@ func ListInventoryItems(getClient GetClientFn, t TranslationHelperFunc) (tool mc
 				},
 			}
 
+			// Narrow the query by the filter's equality conditions
+			if listFilter != nil {
+				opts.Filter = listFilter.String()
+				eq := pushdownEqualities(listFilter)
+				if opts.Status == "" {
+					opts.Status = eq["status"]
+				}
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
 			}
-			items, resp, err := client.Inventory.List(ctx, opts)
+			if fetchAll {
+				all, err := collect(filterSeq(listFilter, client.Inventory.Iter(ctx, opts, fetchAllIterOptions())), fetchAllLimit)
+				if errors.Is(err, ErrTooManyResults) {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				if err != nil {
+					return nil, fmt.Errorf("failed to list inventory items: %w", err)
+				}
+
+				r, err := json.Marshal(completePage(all))
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal response: %w", err)
+				}
+
+				return mcp.NewToolResultText(string(r)), nil
+			}
+			page, err := client.Inventory.ListPage(ctx, opts, pagination.cursor)
+			if errors.Is(err, ErrInvalidCursor) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 			if err != nil {
 				return nil, fmt.Errorf("failed to list inventory items: %w", err)
 			}
-			defer func() { _ = resp.Body.Close() }()
 
-			if resp.StatusCode != http.StatusOK {
-				body, err := io.ReadAll(resp.Body)
-				if err != nil {
-					return nil, fmt.Errorf("failed to read response body: %w", err)
-				}
-				return mcp.NewToolResultError(fmt.Sprintf("failed to list inventory items: %s", string(body))), nil
+			page, err = filterPage(listFilter, page)
+			if err != nil {
+				return nil, err
 			}
 
-			r, err := json.Marshal(items)
+			r, err := json.Marshal(page)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
@@ -140,7 +186,7 @@ func UpdateInventoryItem(getClient GetClientFn, t TranslationHelperFunc) (tool m
 				mcp.Description("Inventory item ID to update"),
 			),
 			mcp.WithNumber("quantity",
-				mcp.Description("New quantity"),
+				mcp.Description("New quantity, in the part's base unit; may be fractional"),
 			),
 			mcp.WithString("location",
 				mcp.Description("New location"),
@@ -148,6 +194,15 @@ func UpdateInventoryItem(getClient GetClientFn, t TranslationHelperFunc) (tool m
 			mcp.WithString("status",
 				mcp.Description("New status"),
 			),
+			mcp.WithString("lot_number",
+				mcp.Description("New lot number"),
+			),
+			mcp.WithString("serial_number",
+				mcp.Description("New serial number; a serialized item holds a quantity of at most one"),
+			),
+			mcp.WithString("expiry_date",
+				mcp.Description("New expiry date (YYYY-MM-DD)"),
+			),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			itemID, err := requiredParam[string](request, "item_id")
@@ -161,8 +216,7 @@ func UpdateInventoryItem(getClient GetClientFn, t TranslationHelperFunc) (tool m
 			if quantity, ok, err := OptionalParamOK[float64](request, "quantity"); err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			} else if ok {
-				q := int(quantity)
-				update.Quantity = &q
+				update.Quantity = &quantity
 				updateNeeded = true
 			}
 
@@ -180,6 +234,27 @@ func UpdateInventoryItem(getClient GetClientFn, t TranslationHelperFunc) (tool m
 				updateNeeded = true
 			}
 
+			if lotNumber, ok, err := OptionalParamOK[string](request, "lot_number"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			} else if ok {
+				update.LotNumber = &lotNumber
+				updateNeeded = true
+			}
+
+			if serialNumber, ok, err := OptionalParamOK[string](request, "serial_number"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			} else if ok {
+				update.SerialNumber = &serialNumber
+				updateNeeded = true
+			}
+
+			if expiryDate, ok, err := OptionalParamOK[string](request, "expiry_date"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			} else if ok {
+				update.ExpiryDate = &expiryDate
+				updateNeeded = true
+			}
+
 			if !updateNeeded {
 				return mcp.NewToolResultError("No update parameters provided."), nil
 			}
@@ -189,6 +264,10 @@ func UpdateInventoryItem(getClient GetClientFn, t TranslationHelperFunc) (tool m
 				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
 			}
 			updatedItem, resp, err := client.Inventory.Update(ctx, itemID, update)
+			warnings, err := auditWarnings(err)
+			if errors.Is(err, ErrInvalidTracking) || isUnitError(err) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 			if err != nil {
 				return nil, fmt.Errorf("failed to update inventory item: %w", err)
 			}
@@ -207,6 +286,311 @@ func UpdateInventoryItem(getClient GetClientFn, t TranslationHelperFunc) (tool m
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
+			return withWarnings(mcp.NewToolResultText(string(r)), warnings), nil
+		}
+}
+
+// AdjustInventory creates a tool to adjust an inventory item's quantity by a signed delta with a reason code.
+func AdjustInventory(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("adjust_inventory",
+			mcp.WithDescription(t("TOOL_ADJUST_INVENTORY_DESCRIPTION", "Adjust an inventory item's quantity by a signed delta, recording the reason. Refuses the change if the quantity moved concurrently or would go negative")),
+			mcp.WithString("item_id",
+				mcp.Required(),
+				mcp.Description("Inventory item ID to adjust"),
+			),
+			mcp.WithNumber("delta",
+				mcp.Required(),
+				mcp.Description("Signed change in quantity in the part's base unit, e.g. -3 to remove three units or 2.5 to add 2.5 m"),
+			),
+			mcp.WithString("reason",
+				mcp.Required(),
+				mcp.Description("Reason for the adjustment"),
+				mcp.Enum(validAdjustmentReasons...),
+			),
+			mcp.WithString("note",
+				mcp.Description("Free-text note explaining the adjustment"),
+			),
+			mcp.WithNumber("expected_quantity",
+				mcp.Description("Quantity the caller last saw; the adjustment is refused if the item no longer has this quantity"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			itemID, err := requiredParam[string](request, "item_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			delta, err := requiredParam[float64](request, "delta")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			reason, err := requiredParam[string](request, "reason")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			note, err := OptionalParam[string](request, "note")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			adj := &InventoryAdjustment{
+				Delta:  delta,
+				Reason: reason,
+				Note:   note,
+			}
+
+			if expected, ok, err := OptionalParamOK[float64](request, "expected_quantity"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			} else if ok {
+				adj.ExpectedQuantity = &expected
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			adjustedItem, err := client.Inventory.Adjust(ctx, itemID, adj)
+			warnings, err := auditWarnings(err)
+			if errors.Is(err, ErrInvalidAdjustment) || errors.Is(err, ErrQuantityChanged) || errors.Is(err, ErrNegativeQuantity) || isUnitError(err) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to adjust inventory item: %w", err)
+			}
+
+			r, err := json.Marshal(adjustedItem)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return withWarnings(mcp.NewToolResultText(string(r)), warnings), nil
+		}
+}
+
+// TransferInventory creates a tool to move stock between inventory items or locations.
+func TransferInventory(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("transfer_inventory",
+			mcp.WithDescription(t("TOOL_TRANSFER_INVENTORY_DESCRIPTION", "Move stock from one inventory item or location to another of the same part and lot. The destination is incremented or created, and the source is restored if the destination cannot be updated. Serialized items move whole, one at a time, to a destination location. Returns the resulting balances of both records")),
+			mcp.WithString("source_item_id",
+				mcp.Description("Inventory item ID to take stock from"),
+			),
+			mcp.WithString("part_id",
+				mcp.Description("Part ID, used with source_location when source_item_id is not given"),
+			),
+			mcp.WithString("source_location",
+				mcp.Description("Location to take stock from, used with part_id"),
+			),
+			mcp.WithString("lot_number",
+				mcp.Description("Lot to take stock from, used with part_id and source_location for lot-tracked stock"),
+			),
+			mcp.WithString("destination_item_id",
+				mcp.Description("Inventory item ID to put stock into"),
+			),
+			mcp.WithString("destination_location",
+				mcp.Description("Location to put stock into when destination_item_id is not given; the item for the same part and lot there is incremented or created"),
+			),
+			mcp.WithNumber("quantity",
+				mcp.Required(),
+				mcp.Description("Quantity to move, in the part's base unit; may be fractional"),
+				mcp.Min(0),
+			),
+			mcp.WithString("note",
+				mcp.Description("Free-text note explaining the transfer"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			sourceItemID, err := OptionalParam[string](request, "source_item_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			partID, err := OptionalParam[string](request, "part_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			sourceLocation, err := OptionalParam[string](request, "source_location")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			lotNumber, err := OptionalParam[string](request, "lot_number")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			destinationItemID, err := OptionalParam[string](request, "destination_item_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			destinationLocation, err := OptionalParam[string](request, "destination_location")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			quantity, err := requiredParam[float64](request, "quantity")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			note, err := OptionalParam[string](request, "note")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			transfer := &InventoryTransfer{
+				SourceItemID:        sourceItemID,
+				PartID:              partID,
+				SourceLocation:      sourceLocation,
+				LotNumber:           lotNumber,
+				DestinationItemID:   destinationItemID,
+				DestinationLocation: destinationLocation,
+				Quantity:            quantity,
+				Note:                note,
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			result, err := client.Inventory.Transfer(ctx, transfer)
+			warnings, err := auditWarnings(err)
+			if errors.Is(err, ErrInvalidTransfer) || errors.Is(err, ErrQuantityChanged) || errors.Is(err, ErrNegativeQuantity) || isUnitError(err) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to transfer inventory: %w", err)
+			}
+
+			r, err := json.Marshal(result)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return withWarnings(mcp.NewToolResultText(string(r)), warnings), nil
+		}
+}
+
+// defaultExpiryWindowDays is how far ahead expiring_lots looks by default
+const defaultExpiryWindowDays = 30
+
+// ListInventoryByLot creates a tool to list the inventory items of a lot.
+func ListInventoryByLot(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("list_inventory_by_lot",
+			mcp.WithDescription(t("TOOL_LIST_INVENTORY_BY_LOT_DESCRIPTION", "List every inventory item of a lot, across locations")),
+			mcp.WithString("lot_number",
+				mcp.Required(),
+				mcp.Description("Lot number"),
+			),
+			mcp.WithString("part_id",
+				mcp.Description("Only items of this part, for lot numbers shared between parts"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			lotNumber, err := requiredParam[string](request, "lot_number")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			partID, err := OptionalParam[string](request, "part_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			items, err := client.Inventory.ListByLot(ctx, lotNumber, partID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list inventory by lot: %w", err)
+			}
+
+			r, err := json.Marshal(items)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
+
+// TraceSerial creates a tool to find where a serial number is and how it got there.
+func TraceSerial(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("trace_serial",
+			mcp.WithDescription(t("TOOL_TRACE_SERIAL_DESCRIPTION", "Find the inventory item holding a serial number and, if the audit log is enabled, every change made to it through this server, oldest first")),
+			mcp.WithString("serial_number",
+				mcp.Required(),
+				mcp.Description("Serial number"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			serialNumber, err := requiredParam[string](request, "serial_number")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			trace, err := client.Inventory.TraceSerial(ctx, serialNumber)
+			if errors.Is(err, ErrSerialNotFound) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to trace serial number: %w", err)
+			}
+
+			r, err := json.Marshal(trace)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
 			return mcp.NewToolResultText(string(r)), nil
 		}
-} 
\ No newline at end of file
+}
+
+// ExpiringLots creates a tool to find lots in stock that have expired or expire soon.
+func ExpiringLots(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("expiring_lots",
+			mcp.WithDescription(t("TOOL_EXPIRING_LOTS_DESCRIPTION", "Find lots in stock that have expired or expire within a number of days, soonest first, with the quantity held at each location")),
+			mcp.WithNumber("within_days",
+				mcp.Description("How many days ahead to look (default 30)"),
+				mcp.Min(0),
+			),
+			mcp.WithString("as_of",
+				mcp.Description("Date to report as of (default today)"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			withinDays, err := OptionalIntParamWithDefault(request, "within_days", defaultExpiryWindowDays)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if withinDays < 0 {
+				return mcp.NewToolResultError("within_days cannot be negative"), nil
+			}
+			asOfParam, err := OptionalParam[string](request, "as_of")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			asOf := time.Now().UTC()
+			if asOfParam != "" {
+				if asOf, err = dates.Parse(asOfParam); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid as_of: %s", err)), nil
+				}
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			lots, err := client.Inventory.ExpiringLots(ctx, asOf, withinDays)
+			if errors.Is(err, ErrTooManyResults) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to find expiring lots: %w", err)
+			}
+
+			r, err := json.Marshal(lots)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
@@ -3,10 +3,15 @@ package firstresonance
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
+	"slices"
 	"strings"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/audit"
 )
 
 // Get retrieves an inventory item by its ID
@@ -20,6 +25,9 @@ func (s *InventoryService) Get(ctx context.Context, id string) (*InventoryItem,
 				location
 				quantity
 				status
+				lot_number
+				serial_number
+				expiry_date
 				last_updated
 			}
 		}
@@ -101,13 +109,16 @@ func (s *InventoryService) Get(ctx context.Context, id string) (*InventoryItem,
 func (s *InventoryService) List(ctx context.Context, opts *ListInventoryItemsOptions) ([]*InventoryItem, error) {
 	// GraphQL query to fetch a list of inventory items
 	query := `
-		query ListInventoryItems($part_id: ID, $location: String, $status: String, $page: Int, $perPage: Int) {
-			inventoryItems(part_id: $part_id, location: $location, status: $status, page: $page, perPage: $perPage) {
+		query ListInventoryItems($part_id: ID, $location: String, $status: String, $lot_number: String, $serial_number: String, $page: Int, $perPage: Int) {
+			inventoryItems(part_id: $part_id, location: $location, status: $status, lot_number: $lot_number, serial_number: $serial_number, page: $page, perPage: $perPage) {
 				id
 				part_id
 				location
 				quantity
 				status
+				lot_number
+				serial_number
+				expiry_date
 				last_updated
 			}
 		}
@@ -116,12 +127,21 @@ func (s *InventoryService) List(ctx context.Context, opts *ListInventoryItemsOpt
 	// Create variables for the query
 	variables := map[string]interface{}{}
 	if opts != nil {
+		if opts.PartID != "" {
+			variables["part_id"] = opts.PartID
+		}
 		if opts.Location != "" {
 			variables["location"] = opts.Location
 		}
 		if opts.Status != "" {
 			variables["status"] = opts.Status
 		}
+		if opts.LotNumber != "" {
+			variables["lot_number"] = opts.LotNumber
+		}
+		if opts.SerialNumber != "" {
+			variables["serial_number"] = opts.SerialNumber
+		}
 		if opts.Page > 0 {
 			variables["page"] = opts.Page
 		}
@@ -192,8 +212,204 @@ func (s *InventoryService) List(ctx context.Context, opts *ListInventoryItemsOpt
 	return result.Data.InventoryItems, nil
 }
 
-// Update updates an existing inventory item
+// Iter returns an iterator over every inventory item matching opts, fetching pages as needed
+func (s *InventoryService) Iter(ctx context.Context, opts *ListInventoryItemsOptions, iterOpts *IterOptions) iter.Seq2[*InventoryItem, error] {
+	return paginate(ctx, func(ctx context.Context, page, perPage int) ([]*InventoryItem, error) {
+		pageOpts := ListInventoryItemsOptions{}
+		if opts != nil {
+			pageOpts = *opts
+		}
+		pageOpts.Page, pageOpts.PerPage = page, perPage
+		return s.List(ctx, &pageOpts)
+	}, iterOpts)
+}
+
+// ListPage retrieves the page of inventory items that the cursor points to, or the
+// page in opts if the cursor is empty
+func (s *InventoryService) ListPage(ctx context.Context, opts *ListInventoryItemsOptions, cursor string) (*Page[*InventoryItem], error) {
+	if opts == nil {
+		opts = &ListInventoryItemsOptions{}
+	}
+
+	// GraphQL query to fetch a page of the inventory items connection
+	query := `
+		query InventoryItemsConnection($part_id: ID, $location: String, $status: String, $lot_number: String, $serial_number: String, $first: Int, $after: String) {
+			inventoryItemsConnection(part_id: $part_id, location: $location, status: $status, lot_number: $lot_number, serial_number: $serial_number, first: $first, after: $after) {
+				totalCount
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+				nodes {
+					id
+					part_id
+					location
+					quantity
+					status
+					lot_number
+					serial_number
+					expiry_date
+					last_updated
+				}
+			}
+		}
+	`
+
+	// Create filter variables for the query
+	filters := map[string]interface{}{}
+	if opts.PartID != "" {
+		filters["part_id"] = opts.PartID
+	}
+	if opts.Location != "" {
+		filters["location"] = opts.Location
+	}
+	if opts.Status != "" {
+		filters["status"] = opts.Status
+	}
+	if opts.LotNumber != "" {
+		filters["lot_number"] = opts.LotNumber
+	}
+	if opts.SerialNumber != "" {
+		filters["serial_number"] = opts.SerialNumber
+	}
+
+	return listPage(ctx, cursor, opts.ListOptions, filters,
+		func(ctx context.Context, first int, after string) (*connection[*InventoryItem], error) {
+			return fetchConnection[*InventoryItem](ctx, s.client, "inventoryItemsConnection", query, filters, first, after)
+		},
+		func(ctx context.Context, page, perPage int) ([]*InventoryItem, error) {
+			pageOpts := *opts
+			pageOpts.Page, pageOpts.PerPage = page, perPage
+			return s.List(ctx, &pageOpts)
+		})
+}
+
+// Create creates a new inventory item and records it in the audit log. Lot
+// and serial tracking, and the quantity's precision, are validated first.
+func (s *InventoryService) Create(ctx context.Context, item *InventoryItem) (*InventoryItem, error) {
+	if err := validateTracking(item); err != nil {
+		return nil, err
+	}
+	if _, _, err := s.client.Units.StockQuantity(item.PartID, item.Quantity, ""); err != nil {
+		return nil, err
+	}
+	created, err := s.create(ctx, item)
+	entry := &audit.Entry{Entity: auditEntityInventoryItem, Action: audit.ActionCreate, After: created}
+	if created != nil {
+		entry.EntityID = created.ID
+	}
+	return created, s.client.Audit.record(ctx, entry, err)
+}
+
+// create sends the createInventoryItem mutation
+func (s *InventoryService) create(ctx context.Context, item *InventoryItem) (*InventoryItem, error) {
+	// GraphQL mutation to create a new inventory item
+	query := `
+		mutation CreateInventoryItem($input: CreateInventoryItemInput!) {
+			createInventoryItem(input: $input) {
+				id
+				part_id
+				location
+				quantity
+				status
+				lot_number
+				serial_number
+				expiry_date
+				last_updated
+			}
+		}
+	`
+
+	// Create variables for the mutation
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"part_id":       item.PartID,
+			"location":      item.Location,
+			"quantity":      item.Quantity,
+			"status":        item.Status,
+			"lot_number":    item.LotNumber,
+			"serial_number": item.SerialNumber,
+			"expiry_date":   item.ExpiryDate,
+		},
+	}
+
+	// Create the request body
+	requestBody := map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	}
+
+	// Marshal the request body to JSON
+	jsonBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request body: %w", err)
+	}
+
+	// Create the HTTP request
+	req, err := http.NewRequestWithContext(ctx, "POST", s.client.baseURL+"/graphql", strings.NewReader(string(jsonBody)))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.client.apiToken)
+
+	// Send the request
+	resp, err := s.client.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read the response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	// Check for non-200 status codes
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	// Parse the response
+	var result struct {
+		Data struct {
+			CreateInventoryItem *InventoryItem `json:"createInventoryItem"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("error unmarshaling response: %w", err)
+	}
+
+	// Check for GraphQL errors
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("GraphQL error: %s", result.Errors[0].Message)
+	}
+
+	return result.Data.CreateInventoryItem, nil
+}
+
+// Update updates an existing inventory item and records the change in the
+// audit log. Changes to quantity or lot and serial tracking are validated
+// against the item first.
 func (s *InventoryService) Update(ctx context.Context, id string, update *InventoryItemUpdateRequest) (*InventoryItem, error) {
+	if err := s.checkTrackingUpdate(ctx, id, update); err != nil {
+		return nil, err
+	}
+	before := auditSnapshot(ctx, s.client, s.Get, id)
+	updated, err := s.update(ctx, id, update)
+	entry := &audit.Entry{Entity: auditEntityInventoryItem, EntityID: id, Action: audit.ActionUpdate, Before: before, After: updated}
+	return updated, s.client.Audit.record(ctx, entry, err)
+}
+
+// update sends the updateInventoryItem mutation
+func (s *InventoryService) update(ctx context.Context, id string, update *InventoryItemUpdateRequest) (*InventoryItem, error) {
 	// GraphQL mutation to update an existing inventory item
 	query := `
 		mutation UpdateInventoryItem($id: ID!, $input: UpdateInventoryItemInput!) {
@@ -203,6 +419,9 @@ func (s *InventoryService) Update(ctx context.Context, id string, update *Invent
 				location
 				quantity
 				status
+				lot_number
+				serial_number
+				expiry_date
 				last_updated
 			}
 		}
@@ -212,9 +431,12 @@ func (s *InventoryService) Update(ctx context.Context, id string, update *Invent
 	variables := map[string]interface{}{
 		"id": id,
 		"input": map[string]interface{}{
-			"location": update.Location,
-			"quantity": update.Quantity,
-			"status":   update.Status,
+			"location":      update.Location,
+			"quantity":      update.Quantity,
+			"status":        update.Status,
+			"lot_number":    update.LotNumber,
+			"serial_number": update.SerialNumber,
+			"expiry_date":   update.ExpiryDate,
 		},
 	}
 
@@ -279,3 +501,305 @@ func (s *InventoryService) Update(ctx context.Context, id string, update *Invent
 
 	return result.Data.UpdateInventoryItem, nil
 }
+
+// ErrQuantityChanged is returned when an inventory item's quantity moves while an adjustment is being made
+var ErrQuantityChanged = errors.New("inventory quantity changed concurrently")
+
+// ErrNegativeQuantity is returned when an adjustment would take an inventory item below zero
+var ErrNegativeQuantity = errors.New("inventory quantity cannot go negative")
+
+// ErrInvalidAdjustment is returned when an adjustment is malformed
+var ErrInvalidAdjustment = errors.New("invalid inventory adjustment")
+
+// validAdjustmentReasons lists the accepted reason codes for adjustments
+var validAdjustmentReasons = []string{
+	AdjustmentReasonScrap,
+	AdjustmentReasonCycleCount,
+	AdjustmentReasonReceipt,
+	AdjustmentReasonConsumption,
+}
+
+// Adjust changes an inventory item's quantity by a signed delta and records
+// the reason in the audit log. The adjustment is refused if the quantity is
+// not the expected one, moves while the adjustment is being made, would go
+// negative or would take a serialized item above one.
+func (s *InventoryService) Adjust(ctx context.Context, id string, adj *InventoryAdjustment) (*InventoryItem, error) {
+	if adj.Delta == 0 {
+		return nil, fmt.Errorf("%w: delta must be non-zero", ErrInvalidAdjustment)
+	}
+	if !slices.Contains(validAdjustmentReasons, adj.Reason) {
+		return nil, fmt.Errorf("%w: reason must be one of %s", ErrInvalidAdjustment, strings.Join(validAdjustmentReasons, ", "))
+	}
+
+	current, quantity, err := s.prepareAdjustment(ctx, id, adj.Delta, adj.ExpectedQuantity)
+	if err != nil {
+		return nil, err
+	}
+	if current.SerialNumber != "" && quantity != 0 && quantity != 1 {
+		return nil, fmt.Errorf("%w: item %s is serial number %s, which can only hold a quantity of zero or one", ErrInvalidAdjustment, id, current.SerialNumber)
+	}
+
+	updated, err := s.update(ctx, id, &InventoryItemUpdateRequest{Quantity: &quantity})
+	entry := &audit.Entry{
+		Entity:   auditEntityInventoryItem,
+		EntityID: id,
+		Action:   audit.ActionAdjust,
+		Before:   current,
+		After:    updated,
+		Details: map[string]interface{}{
+			"delta":  adj.Delta,
+			"reason": adj.Reason,
+			"note":   adj.Note,
+		},
+	}
+	return updated, s.client.Audit.record(ctx, entry, err)
+}
+
+// prepareAdjustment reads an inventory item and works out its quantity after
+// applying delta, which must be within the precision of the part's base
+// unit. The item is checked against the expected quantity if one is given
+// and read again just before returning, so a concurrent edit is not
+// overwritten.
+func (s *InventoryService) prepareAdjustment(ctx context.Context, id string, delta float64, expected *float64) (*InventoryItem, float64, error) {
+	current, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, _, err := s.client.Units.StockQuantity(current.PartID, delta, ""); err != nil {
+		return nil, 0, err
+	}
+	if expected != nil && s.client.Units.round(current.PartID, *expected) != current.Quantity {
+		return nil, 0, fmt.Errorf("%w: expected %g, found %g", ErrQuantityChanged, *expected, current.Quantity)
+	}
+
+	quantity := s.client.Units.round(current.PartID, current.Quantity+delta)
+	if quantity < 0 {
+		return nil, 0, fmt.Errorf("%w: %g %+g = %g", ErrNegativeQuantity, current.Quantity, delta, quantity)
+	}
+
+	latest, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if latest.Quantity != current.Quantity {
+		return nil, 0, fmt.Errorf("%w: was %g, now %g", ErrQuantityChanged, current.Quantity, latest.Quantity)
+	}
+
+	return current, quantity, nil
+}
+
+// ErrInvalidTransfer is returned when a transfer is malformed or its records cannot be resolved
+var ErrInvalidTransfer = errors.New("invalid inventory transfer")
+
+// Transfer moves stock from one inventory item to another of the same part
+// and lot. The source is decremented first, then the destination is
+// incremented or created. If the destination step fails the source is
+// restored, so stock is never lost or created from nothing. Serialized items
+// are not split or merged but moved whole, see moveSerialized.
+func (s *InventoryService) Transfer(ctx context.Context, transfer *InventoryTransfer) (*InventoryTransferResult, error) {
+	if transfer.Quantity <= 0 {
+		return nil, fmt.Errorf("%w: quantity must be positive", ErrInvalidTransfer)
+	}
+
+	source, err := s.transferSource(ctx, transfer)
+	if err != nil {
+		return nil, err
+	}
+	if source.SerialNumber != "" {
+		return s.moveSerialized(ctx, transfer, source)
+	}
+	destination, err := s.transferDestination(ctx, transfer, source)
+	if err != nil {
+		return nil, err
+	}
+
+	// Failures to write the audit log are reported alongside the outcome
+	var auditErrs []error
+	fail := func(err error) (*InventoryTransferResult, error) {
+		return nil, errors.Join(append([]error{err}, auditErrs...)...)
+	}
+	entry := func(id, leg string, before, after *InventoryItem) *audit.Entry {
+		details := map[string]interface{}{
+			"leg":            leg,
+			"source_item_id": source.ID,
+			"quantity":       transfer.Quantity,
+			"note":           transfer.Note,
+		}
+		if destination != nil {
+			details["destination_item_id"] = destination.ID
+		} else {
+			details["destination_location"] = transfer.DestinationLocation
+		}
+		return &audit.Entry{
+			Entity:   auditEntityInventoryItem,
+			EntityID: id,
+			Action:   audit.ActionTransfer,
+			Before:   before,
+			After:    after,
+			Details:  details,
+		}
+	}
+
+	// Take the stock out of the source first
+	sourceBefore, quantity, err := s.prepareAdjustment(ctx, source.ID, -transfer.Quantity, &source.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	sourceAfter, err := s.update(ctx, source.ID, &InventoryItemUpdateRequest{Quantity: &quantity})
+	if auditErr := s.client.Audit.append(ctx, entry(source.ID, "source", sourceBefore, sourceAfter), err); auditErr != nil {
+		auditErrs = append(auditErrs, auditErr)
+	}
+	if err != nil {
+		return fail(fmt.Errorf("failed to decrement source inventory item %s: %w", source.ID, err))
+	}
+
+	// Then put it into the destination
+	result := &InventoryTransferResult{Source: sourceAfter}
+	if destination == nil {
+		item := &InventoryItem{
+			PartID:     source.PartID,
+			Location:   transfer.DestinationLocation,
+			Quantity:   transfer.Quantity,
+			Status:     source.Status,
+			LotNumber:  source.LotNumber,
+			ExpiryDate: source.ExpiryDate,
+		}
+		result.Destination, err = s.create(ctx, item)
+		result.CreatedDestination = true
+		if auditErr := s.client.Audit.append(ctx, entry(idOf(result.Destination), "destination", nil, result.Destination), err); auditErr != nil {
+			auditErrs = append(auditErrs, auditErr)
+		}
+	} else {
+		var destinationBefore *InventoryItem
+		destinationBefore, quantity, err = s.prepareAdjustment(ctx, destination.ID, transfer.Quantity, nil)
+		if err == nil {
+			result.Destination, err = s.update(ctx, destination.ID, &InventoryItemUpdateRequest{Quantity: &quantity})
+			if auditErr := s.client.Audit.append(ctx, entry(destination.ID, "destination", destinationBefore, result.Destination), err); auditErr != nil {
+				auditErrs = append(auditErrs, auditErr)
+			}
+		}
+	}
+	if err == nil {
+		return result, auditOutcome(nil, auditErrs...)
+	}
+
+	// The destination step failed, so restore the source
+	destinationErr := err
+	restoreBefore, quantity, err := s.prepareAdjustment(ctx, source.ID, transfer.Quantity, &sourceAfter.Quantity)
+	var restored *InventoryItem
+	if err == nil {
+		restored, err = s.update(ctx, source.ID, &InventoryItemUpdateRequest{Quantity: &quantity})
+		if auditErr := s.client.Audit.append(ctx, entry(source.ID, "rollback", restoreBefore, restored), err); auditErr != nil {
+			auditErrs = append(auditErrs, auditErr)
+		}
+	}
+	if err != nil {
+		return fail(fmt.Errorf("failed to update destination (%v) and failed to restore %g to source inventory item %s, manual correction required: %w",
+			destinationErr, transfer.Quantity, source.ID, err))
+	}
+	return fail(fmt.Errorf("failed to update destination, source inventory item %s restored to %g: %w",
+		source.ID, restored.Quantity, destinationErr))
+}
+
+// transferSource resolves the inventory item stock is transferred out of
+func (s *InventoryService) transferSource(ctx context.Context, transfer *InventoryTransfer) (*InventoryItem, error) {
+	if transfer.SourceItemID != "" {
+		return s.Get(ctx, transfer.SourceItemID)
+	}
+	if transfer.PartID == "" || transfer.SourceLocation == "" {
+		return nil, fmt.Errorf("%w: either source_item_id or both part_id and source_location are required", ErrInvalidTransfer)
+	}
+
+	source, err := s.findByPartAndLocation(ctx, transfer.PartID, transfer.SourceLocation, transfer.LotNumber)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil && transfer.LotNumber != "" {
+		return nil, fmt.Errorf("%w: no inventory item for lot %s of part %s at %s", ErrInvalidTransfer, transfer.LotNumber, transfer.PartID, transfer.SourceLocation)
+	}
+	if source == nil {
+		return nil, fmt.Errorf("%w: no inventory item for part %s at %s", ErrInvalidTransfer, transfer.PartID, transfer.SourceLocation)
+	}
+	return source, nil
+}
+
+// transferDestination resolves the inventory item stock is transferred into.
+// It returns nil if the destination does not exist yet and should be created.
+func (s *InventoryService) transferDestination(ctx context.Context, transfer *InventoryTransfer, source *InventoryItem) (*InventoryItem, error) {
+	if transfer.DestinationItemID != "" {
+		if transfer.DestinationItemID == source.ID {
+			return nil, fmt.Errorf("%w: source and destination are the same item", ErrInvalidTransfer)
+		}
+		destination, err := s.Get(ctx, transfer.DestinationItemID)
+		if err != nil {
+			return nil, err
+		}
+		if source.PartID != "" && destination.PartID != "" && source.PartID != destination.PartID {
+			return nil, fmt.Errorf("%w: source holds part %s but destination holds part %s", ErrInvalidTransfer, source.PartID, destination.PartID)
+		}
+		if destination.SerialNumber != "" {
+			return nil, fmt.Errorf("%w: destination is serial number %s, which cannot take more stock", ErrInvalidTransfer, destination.SerialNumber)
+		}
+		if source.LotNumber != destination.LotNumber {
+			return nil, fmt.Errorf("%w: source is lot %q but destination is lot %q", ErrInvalidTransfer, source.LotNumber, destination.LotNumber)
+		}
+		return destination, nil
+	}
+
+	if transfer.DestinationLocation == "" {
+		return nil, fmt.Errorf("%w: either destination_item_id or destination_location is required", ErrInvalidTransfer)
+	}
+	if transfer.DestinationLocation == source.Location {
+		return nil, fmt.Errorf("%w: source is already at %s", ErrInvalidTransfer, source.Location)
+	}
+	if source.PartID == "" {
+		return nil, fmt.Errorf("%w: source inventory item %s has no part, specify destination_item_id", ErrInvalidTransfer, source.ID)
+	}
+	return s.findByPartAndLocation(ctx, source.PartID, transfer.DestinationLocation, source.LotNumber)
+}
+
+// findByPartAndLocation returns the single unserialized inventory item
+// holding a lot of a part at a location, or nil if there is none. An empty
+// lot finds stock not tracked by lot. Every page of the part's items at the
+// location is read, so an item past the first page is still found.
+func (s *InventoryService) findByPartAndLocation(ctx context.Context, partID, location, lot string) (*InventoryItem, error) {
+	var items []*InventoryItem
+	for item, err := range s.Iter(ctx, &ListInventoryItemsOptions{PartID: partID, Location: location, LotNumber: lot}, nil) {
+		if err != nil {
+			return nil, err
+		}
+		if item.SerialNumber == "" && item.LotNumber == lot {
+			items = append(items, item)
+		}
+	}
+	switch len(items) {
+	case 0:
+		return nil, nil
+	case 1:
+		return items[0], nil
+	default:
+		return nil, fmt.Errorf("%w: %d inventory items hold part %s at %s, specify the item ID", ErrInvalidTransfer, len(items), partID, location)
+	}
+}
+
+// idOf returns the ID of an inventory item, or an empty string for nil
+func idOf(item *InventoryItem) string {
+	if item == nil {
+		return ""
+	}
+	return item.ID
+}
+
+// inventoryAnalysisLimit caps how many inventory items a report reads
+const inventoryAnalysisLimit = 10000
+
+// analysisInventory returns every inventory item matching opts for a report,
+// failing with ErrTooManyResults beyond inventoryAnalysisLimit
+func (s *InventoryService) analysisInventory(ctx context.Context, opts *ListInventoryItemsOptions) ([]*InventoryItem, error) {
+	iterOpts := &IterOptions{PerPage: defaultIterPerPage, MaxItems: inventoryAnalysisLimit + 1}
+	items, err := collect(s.Iter(ctx, opts, iterOpts), inventoryAnalysisLimit)
+	if err != nil {
+		return nil, fmt.Errorf("error listing inventory items: %w", err)
+	}
+	return items, nil
+}
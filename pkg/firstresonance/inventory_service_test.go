@@ -0,0 +1,74 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeInventoryServer serves items in pages to ListInventoryItems queries,
+// filtered by lot if the query names one
+func fakeInventoryServer(t *testing.T, items []*InventoryItem) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables struct {
+				LotNumber string `json:"lot_number"`
+				Page      int    `json:"page"`
+				PerPage   int    `json:"perPage"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		v := body.Variables
+		var matching []*InventoryItem
+		for _, item := range items {
+			if v.LotNumber == "" || item.LotNumber == v.LotNumber {
+				matching = append(matching, item)
+			}
+		}
+		page := []*InventoryItem{}
+		for i := (v.Page - 1) * v.PerPage; i < min(v.Page*v.PerPage, len(matching)); i++ {
+			page = append(page, matching[i])
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"inventoryItems": page}})
+	}))
+	t.Cleanup(server.Close)
+	return NewClient(server.URL, "token", nil)
+}
+
+func TestFindByPartAndLocation(t *testing.T) {
+	ctx := context.Background()
+	var items []*InventoryItem
+	for i := range 250 {
+		items = append(items, &InventoryItem{ID: fmt.Sprint(i), PartID: "p1", Location: "A1", Quantity: 1, SerialNumber: fmt.Sprint("SN", i)})
+	}
+	items = append(items,
+		&InventoryItem{ID: "loose", PartID: "p1", Location: "A1", Quantity: 40},
+		&InventoryItem{ID: "lot7", PartID: "p1", Location: "A1", Quantity: 5, LotNumber: "L7"},
+	)
+	client := fakeInventoryServer(t, items)
+
+	item, err := client.Inventory.findByPartAndLocation(ctx, "p1", "A1", "")
+	require.NoError(t, err)
+	require.NotNil(t, item, "an item past the first page is found")
+	assert.Equal(t, "loose", item.ID)
+
+	item, err = client.Inventory.findByPartAndLocation(ctx, "p1", "A1", "L7")
+	require.NoError(t, err)
+	require.NotNil(t, item)
+	assert.Equal(t, "lot7", item.ID)
+
+	item, err = client.Inventory.findByPartAndLocation(ctx, "p1", "A1", "L8")
+	require.NoError(t, err)
+	assert.Nil(t, item)
+
+	client = fakeInventoryServer(t, append(items, &InventoryItem{ID: "loose2", PartID: "p1", Location: "A1", Quantity: 1}))
+	_, err = client.Inventory.findByPartAndLocation(ctx, "p1", "A1", "")
+	assert.ErrorIs(t, err, ErrInvalidTransfer)
+}
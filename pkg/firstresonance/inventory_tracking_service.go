@@ -0,0 +1,209 @@
+package firstresonance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/audit"
+	"github.com/firstresonance/fr-mcp-server/pkg/dates"
+)
+
+// ErrInvalidTracking is returned when an inventory item's lot or serial tracking is inconsistent
+var ErrInvalidTracking = errors.New("invalid lot or serial tracking")
+
+// ErrSerialNotFound is returned when no inventory item has a serial number
+var ErrSerialNotFound = errors.New("serial number not found")
+
+// serialHistoryLimit caps how many audit log entries a serial trace reads per item
+const serialHistoryLimit = 1000
+
+// validateTracking checks that a serialized item holds a quantity of zero
+// or one and that an expiry date is a date
+func validateTracking(item *InventoryItem) error {
+	if item.SerialNumber != "" && item.Quantity != 0 && item.Quantity != 1 {
+		return fmt.Errorf("%w: serial number %s can only hold a quantity of zero or one, not %g", ErrInvalidTracking, item.SerialNumber, item.Quantity)
+	}
+	if item.ExpiryDate != "" {
+		if _, err := time.Parse(time.DateOnly, item.ExpiryDate); err != nil {
+			return fmt.Errorf("%w: expiry_date %q is not a date (YYYY-MM-DD)", ErrInvalidTracking, item.ExpiryDate)
+		}
+	}
+	return nil
+}
+
+// checkTrackingUpdate validates an inventory item as it would be after an
+// update that changes its quantity or tracking, including that a new
+// quantity is within the precision of the part's base unit
+func (s *InventoryService) checkTrackingUpdate(ctx context.Context, id string, update *InventoryItemUpdateRequest) error {
+	if update.Quantity == nil && update.SerialNumber == nil && update.ExpiryDate == nil {
+		return nil
+	}
+	current, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	item := *current
+	if update.Quantity != nil {
+		if _, _, err := s.client.Units.StockQuantity(item.PartID, *update.Quantity, ""); err != nil {
+			return err
+		}
+		item.Quantity = *update.Quantity
+	}
+	if update.SerialNumber != nil {
+		item.SerialNumber = *update.SerialNumber
+	}
+	if update.ExpiryDate != nil {
+		item.ExpiryDate = *update.ExpiryDate
+	}
+	return validateTracking(&item)
+}
+
+// moveSerialized moves a serialized item whole to the transfer's destination
+// location, as serialized stock is never split or merged. The transfer must
+// be of a quantity of one, to a location rather than into another item. The
+// moved item is reported as both source and destination.
+func (s *InventoryService) moveSerialized(ctx context.Context, transfer *InventoryTransfer, source *InventoryItem) (*InventoryTransferResult, error) {
+	switch {
+	case transfer.Quantity != 1:
+		return nil, fmt.Errorf("%w: serial number %s moves in a quantity of one", ErrInvalidTransfer, source.SerialNumber)
+	case source.Quantity != 1:
+		return nil, fmt.Errorf("%w: serial number %s has a quantity of %g and cannot be moved", ErrInvalidTransfer, source.SerialNumber, source.Quantity)
+	case transfer.DestinationItemID != "":
+		return nil, fmt.Errorf("%w: serial number %s cannot be merged into another item, give a destination_location", ErrInvalidTransfer, source.SerialNumber)
+	case transfer.DestinationLocation == "":
+		return nil, fmt.Errorf("%w: destination_location is required", ErrInvalidTransfer)
+	case transfer.DestinationLocation == source.Location:
+		return nil, fmt.Errorf("%w: source is already at %s", ErrInvalidTransfer, source.Location)
+	}
+
+	location := transfer.DestinationLocation
+	moved, err := s.update(ctx, source.ID, &InventoryItemUpdateRequest{Location: &location})
+	entry := &audit.Entry{
+		Entity:   auditEntityInventoryItem,
+		EntityID: source.ID,
+		Action:   audit.ActionTransfer,
+		Before:   source,
+		After:    moved,
+		Details: map[string]interface{}{
+			"leg":                  "move",
+			"serial_number":        source.SerialNumber,
+			"destination_location": location,
+			"quantity":             1,
+			"note":                 transfer.Note,
+		},
+	}
+	err = s.client.Audit.record(ctx, entry, err)
+	if moved == nil {
+		return nil, err
+	}
+	return &InventoryTransferResult{Source: moved, Destination: moved}, err
+}
+
+// ListByLot returns every inventory item of a lot, optionally of one part
+func (s *InventoryService) ListByLot(ctx context.Context, lotNumber, partID string) ([]*InventoryItem, error) {
+	items := []*InventoryItem{}
+	for item, err := range s.Iter(ctx, &ListInventoryItemsOptions{LotNumber: lotNumber, PartID: partID}, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("error listing inventory items: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// TraceSerial returns the inventory items with a serial number and, if the
+// audit log is enabled, every change recorded to them, oldest first
+func (s *InventoryService) TraceSerial(ctx context.Context, serialNumber string) (*SerialTrace, error) {
+	trace := &SerialTrace{SerialNumber: serialNumber, Items: []*InventoryItem{}, History: []*audit.Entry{}}
+	for item, err := range s.Iter(ctx, &ListInventoryItemsOptions{SerialNumber: serialNumber}, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("error listing inventory items: %w", err)
+		}
+		trace.Items = append(trace.Items, item)
+	}
+	if len(trace.Items) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSerialNotFound, serialNumber)
+	}
+
+	if !s.client.Audit.Enabled() {
+		return trace, nil
+	}
+	for _, item := range trace.Items {
+		entries, err := s.client.Audit.Query(ctx, &audit.QueryOptions{
+			Entity:   auditEntityInventoryItem,
+			EntityID: item.ID,
+			Limit:    serialHistoryLimit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query audit log: %w", err)
+		}
+		trace.History = append(trace.History, entries...)
+	}
+	sort.SliceStable(trace.History, func(i, j int) bool {
+		return trace.History[i].Timestamp.Before(trace.History[j].Timestamp)
+	})
+	return trace, nil
+}
+
+// ExpiringLots returns the lots in stock that have expired as of a date or
+// expire within the given number of days after it, soonest first. Stock is
+// totalled per part, lot and expiry date across locations; items with no
+// stock or no expiry date are left out.
+func (s *InventoryService) ExpiringLots(ctx context.Context, asOf time.Time, withinDays int) ([]*LotExpiry, error) {
+	type lotKey struct{ partID, lot, expiry string }
+	lots := map[lotKey]*LotExpiry{}
+	items, err := s.analysisInventory(ctx, &ListInventoryItemsOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if item.ExpiryDate == "" || item.Quantity <= 0 {
+			continue
+		}
+		expiry, err := time.Parse(time.DateOnly, item.ExpiryDate)
+		if err != nil {
+			continue
+		}
+		days := dates.DaysBetween(asOf, expiry)
+		if days > withinDays {
+			continue
+		}
+
+		key := lotKey{item.PartID, item.LotNumber, item.ExpiryDate}
+		lot := lots[key]
+		if lot == nil {
+			lot = &LotExpiry{
+				PartID:          item.PartID,
+				LotNumber:       item.LotNumber,
+				ExpiryDate:      item.ExpiryDate,
+				DaysUntilExpiry: days,
+				Expired:         days < 0,
+				Locations:       map[string]float64{},
+				ItemIDs:         []string{},
+			}
+			lots[key] = lot
+		}
+		lot.Quantity = s.client.Units.round(item.PartID, lot.Quantity+item.Quantity)
+		lot.Locations[item.Location] = s.client.Units.round(item.PartID, lot.Locations[item.Location]+item.Quantity)
+		lot.ItemIDs = append(lot.ItemIDs, item.ID)
+	}
+
+	expiring := make([]*LotExpiry, 0, len(lots))
+	for _, lot := range lots {
+		expiring = append(expiring, lot)
+	}
+	sort.Slice(expiring, func(i, j int) bool {
+		a, b := expiring[i], expiring[j]
+		if a.ExpiryDate != b.ExpiryDate {
+			return a.ExpiryDate < b.ExpiryDate
+		}
+		if a.PartID != b.PartID {
+			return a.PartID < b.PartID
+		}
+		return a.LotNumber < b.LotNumber
+	})
+	return expiring, nil
+}
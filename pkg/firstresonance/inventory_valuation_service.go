@@ -0,0 +1,170 @@
+package firstresonance
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// uncategorized is the category of parts with no type
+const uncategorized = "uncategorized"
+
+// Valuation values the on-hand inventory at each part's unit cost on a cost
+// basis, with a line per part and location and totals by location and by
+// part type. Items with no stock are left out, and stock of parts with no
+// cost, or with a cost in another unit than their stock, is listed with no
+// value.
+func (s *InventoryService) Valuation(ctx context.Context, opts *InventoryValuationOptions) (*InventoryValuation, error) {
+	basis, err := costBasis(opts.CostBasis)
+	if err != nil {
+		return nil, err
+	}
+	costs, err := s.client.Costs.Costs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type lineKey struct{ partID, location string }
+	lines := map[lineKey]*ValuationLine{}
+	listOpts := &ListInventoryItemsOptions{PartID: opts.PartID, Location: opts.Location}
+	items, err := s.analysisInventory(ctx, listOpts)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if item.PartID == "" || item.Quantity <= 0 {
+			continue
+		}
+		key := lineKey{item.PartID, item.Location}
+		line := lines[key]
+		if line == nil {
+			line = &ValuationLine{PartID: item.PartID, Location: item.Location}
+			lines[key] = line
+		}
+		line.Quantity = s.client.Units.round(item.PartID, line.Quantity+item.Quantity)
+	}
+
+	valuation := &InventoryValuation{
+		CostBasis:     basis,
+		Lines:         make([]*ValuationLine, 0, len(lines)),
+		UncostedParts: []string{},
+	}
+	parts := map[string]*Part{}
+	uncosted := map[string]bool{}
+	for _, line := range lines {
+		part, ok := parts[line.PartID]
+		if !ok {
+			// A part that can't be read is valued without a name or type
+			part, _ = s.client.Parts.Get(ctx, line.PartID)
+			parts[line.PartID] = part
+		}
+		line.Category = uncategorized
+		if part != nil {
+			line.PartName = part.Name
+			if part.Type != "" {
+				line.Category = part.Type
+			}
+		}
+		if _, unit, err := s.client.Units.StockQuantity(line.PartID, line.Quantity, ""); err == nil {
+			line.Unit = unit
+		}
+		if cost, ok := s.client.Costs.stockUnitCost(costs, line.PartID, line.Unit, basis); ok {
+			line.UnitCost = &cost
+			line.Value = roundMoney(cost * line.Quantity)
+		} else {
+			uncosted[line.PartID] = true
+		}
+		valuation.TotalValue = roundMoney(valuation.TotalValue + line.Value)
+		valuation.Lines = append(valuation.Lines, line)
+	}
+
+	// Most valuable first
+	sort.Slice(valuation.Lines, func(i, j int) bool {
+		a, b := valuation.Lines[i], valuation.Lines[j]
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		if a.PartID != b.PartID {
+			return a.PartID < b.PartID
+		}
+		return a.Location < b.Location
+	})
+	valuation.ByLocation = valuationTotals(valuation.Lines, func(line *ValuationLine) string { return line.Location })
+	valuation.ByCategory = valuationTotals(valuation.Lines, func(line *ValuationLine) string { return line.Category })
+	for partID := range uncosted {
+		valuation.UncostedParts = append(valuation.UncostedParts, partID)
+	}
+	sort.Strings(valuation.UncostedParts)
+	return valuation, nil
+}
+
+// valuationTotals totals the value of lines by a key, most valuable first
+func valuationTotals(lines []*ValuationLine, key func(*ValuationLine) string) []*ValuationTotal {
+	byKey := map[string]*ValuationTotal{}
+	totals := []*ValuationTotal{}
+	for _, line := range lines {
+		name := key(line)
+		total := byKey[name]
+		if total == nil {
+			total = &ValuationTotal{Name: name}
+			byKey[name] = total
+			totals = append(totals, total)
+		}
+		total.Value = roundMoney(total.Value + line.Value)
+		total.Lines++
+	}
+	sort.SliceStable(totals, func(i, j int) bool {
+		if totals[i].Value != totals[j].Value {
+			return totals[i].Value > totals[j].Value
+		}
+		return totals[i].Name < totals[j].Name
+	})
+	return totals
+}
+
+// ValuationMarkdown renders an inventory valuation as markdown tables
+func ValuationMarkdown(v *InventoryValuation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Inventory valuation at %s cost: %.2f\n", v.CostBasis, v.TotalValue)
+	for _, section := range []struct {
+		title  string
+		totals []*ValuationTotal
+	}{{"Location", v.ByLocation}, {"Category", v.ByCategory}} {
+		fmt.Fprintf(&b, "\n| %s | Lines | Value |\n| --- | ---: | ---: |\n", section.title)
+		for _, total := range section.totals {
+			fmt.Fprintf(&b, "| %s | %d | %.2f |\n", markdownCell(total.Name), total.Lines, total.Value)
+		}
+	}
+
+	b.WriteString("\n| Part | Category | Location | Quantity | Unit cost | Value |\n")
+	b.WriteString("| --- | --- | --- | ---: | ---: | ---: |\n")
+	for _, line := range v.Lines {
+		name := line.PartID
+		if line.PartName != "" {
+			name = fmt.Sprintf("%s (%s)", line.PartName, line.PartID)
+		}
+		quantity := fmt.Sprintf("%g", line.Quantity)
+		if line.Unit != "" {
+			quantity += " " + line.Unit
+		}
+		unitCost := "n/a"
+		if line.UnitCost != nil {
+			unitCost = fmt.Sprintf("%g", *line.UnitCost)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %.2f |\n",
+			markdownCell(name), markdownCell(line.Category), markdownCell(line.Location), quantity, unitCost, line.Value)
+	}
+	if len(v.Lines) == 0 {
+		b.WriteString("\nNo inventory is on hand.\n")
+	}
+	if len(v.UncostedParts) > 0 {
+		fmt.Fprintf(&b, "\nParts with no cost, left out of the total: %s\n", strings.Join(v.UncostedParts, ", "))
+	}
+	return b.String()
+}
+
+// markdownCell escapes a value for a markdown table cell
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
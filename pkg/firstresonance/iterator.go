@@ -0,0 +1,129 @@
+package firstresonance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// Defaults for automatic pagination
+const (
+	defaultIterPerPage = 100
+	// fetchAllLimit is the most items a list tool returns when fetch_all is set
+	fetchAllLimit = 5000
+)
+
+// ErrTooManyResults is returned when collecting a result set that exceeds the safety limit
+var ErrTooManyResults = errors.New("too many results")
+
+// IterOptions controls how an iterator fetches pages
+type IterOptions struct {
+	// PerPage is the page size to request (default 100)
+	PerPage int
+	// MaxItems stops iteration after this many items; 0 means no limit
+	MaxItems int
+	// Prefetch is how many pages to fetch ahead of the consumer, concurrently; 0 fetches one page at a time
+	Prefetch int
+}
+
+// pageFetcher fetches one page of a list
+type pageFetcher[T any] func(ctx context.Context, page, perPage int) ([]T, error)
+
+// pageResult is the outcome of fetching one page
+type pageResult[T any] struct {
+	items []T
+	err   error
+}
+
+// paginate returns an iterator over every item of a paged list. Pages are
+// fetched lazily as the consumer advances, up to opts.Prefetch pages ahead,
+// until a short page signals the end. Iteration stops at the first error,
+// which is yielded with a zero item.
+func paginate[T any](ctx context.Context, fetch pageFetcher[T], opts *IterOptions) iter.Seq2[T, error] {
+	perPage, maxItems, prefetch := defaultIterPerPage, 0, 0
+	if opts != nil {
+		if opts.PerPage > 0 {
+			perPage = opts.PerPage
+		}
+		maxItems = max(opts.MaxItems, 0)
+		prefetch = max(opts.Prefetch, 0)
+	}
+
+	return func(yield func(T, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		// Each in-flight page delivers to its own buffered channel so
+		// abandoned fetches never block
+		var inFlight []chan pageResult[T]
+		next := 1
+		start := func() {
+			ch := make(chan pageResult[T], 1)
+			inFlight = append(inFlight, ch)
+			go func(page int) {
+				items, err := fetch(ctx, page, perPage)
+				ch <- pageResult[T]{items: items, err: err}
+			}(next)
+			next++
+		}
+
+		yielded := 0
+		for start(); len(inFlight) > 0; {
+			for len(inFlight) <= prefetch {
+				start()
+			}
+
+			result := <-inFlight[0]
+			inFlight = inFlight[1:]
+			if result.err != nil {
+				var zero T
+				yield(zero, result.err)
+				return
+			}
+
+			for _, item := range result.items {
+				if !yield(item, nil) {
+					return
+				}
+				yielded++
+				if maxItems > 0 && yielded >= maxItems {
+					return
+				}
+			}
+
+			if len(result.items) < perPage {
+				return
+			}
+			if len(inFlight) == 0 {
+				start()
+			}
+		}
+	}
+}
+
+// collect gathers every item from an iterator, failing with ErrTooManyResults
+// if there are more than limit items.
+func collect[T any](seq iter.Seq2[T, error], limit int) ([]T, error) {
+	items := []T{}
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == limit {
+			return nil, fmt.Errorf("%w: more than %d items, narrow the filters or paginate", ErrTooManyResults, limit)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// fetchAllIterOptions are the iterator options used by list tools when
+// fetch_all is set. They set no MaxItems: collect caps the items kept, which
+// a filter may thin out.
+func fetchAllIterOptions() *IterOptions {
+	return &IterOptions{
+		PerPage:  defaultIterPerPage,
+		Prefetch: 2,
+	}
+}
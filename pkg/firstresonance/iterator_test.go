@@ -0,0 +1,142 @@
+package firstresonance
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePages serves the items 1..total in pages, failing on failPage if it is
+// set, and counts the fetches still running
+type fakePages struct {
+	total    int
+	failPage int
+	running  atomic.Int32
+	fetched  atomic.Int32
+}
+
+func (f *fakePages) fetch(ctx context.Context, page, perPage int) ([]int, error) {
+	f.running.Add(1)
+	defer f.running.Add(-1)
+	f.fetched.Add(1)
+	if page == f.failPage {
+		return nil, errors.New("page failed")
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	items := []int{}
+	for i := (page-1)*perPage + 1; i <= min(page*perPage, f.total); i++ {
+		items = append(items, i)
+	}
+	return items, nil
+}
+
+func TestPaginate(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		total    int
+		failPage int
+		opts     *IterOptions
+		want     int
+		wantErr  bool
+	}{
+		{name: "short last page", total: 25, opts: &IterOptions{PerPage: 10}, want: 25},
+		{name: "empty last page", total: 20, opts: &IterOptions{PerPage: 10}, want: 20},
+		{name: "no items", total: 0, opts: &IterOptions{PerPage: 10}, want: 0},
+		{name: "prefetch", total: 95, opts: &IterOptions{PerPage: 10, Prefetch: 3}, want: 95},
+		{name: "max items", total: 95, opts: &IterOptions{PerPage: 10, MaxItems: 42}, want: 42},
+		{name: "error mid-stream", total: 95, failPage: 3, opts: &IterOptions{PerPage: 10}, want: 20, wantErr: true},
+		{name: "error mid-stream with prefetch", total: 95, failPage: 3, opts: &IterOptions{PerPage: 10, Prefetch: 2}, want: 20, wantErr: true},
+		{name: "default options", total: 150, want: 150},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &fakePages{total: tc.total, failPage: tc.failPage}
+			var got []int
+			var errs int
+			for item, err := range paginate(ctx, f.fetch, tc.opts) {
+				if err != nil {
+					errs++
+					continue
+				}
+				got = append(got, item)
+			}
+
+			require.Len(t, got, tc.want)
+			for i, item := range got {
+				assert.Equal(t, i+1, item, "items in order")
+			}
+			if tc.wantErr {
+				assert.Equal(t, 1, errs, "iteration stops at the first error")
+			} else {
+				assert.Zero(t, errs)
+			}
+		})
+	}
+
+	t.Run("stopping early leaves no fetch running", func(t *testing.T) {
+		f := &fakePages{total: 1000}
+		var got []int
+		for item, err := range paginate(ctx, f.fetch, &IterOptions{PerPage: 10, Prefetch: 4}) {
+			require.NoError(t, err)
+			got = append(got, item)
+			if len(got) == 15 {
+				break
+			}
+		}
+
+		assert.Len(t, got, 15)
+		assert.Eventually(t, func() bool { return f.running.Load() == 0 }, time.Second, time.Millisecond)
+		assert.LessOrEqual(t, f.fetched.Load(), int32(7), "fetches no more than the prefetch window")
+	})
+}
+
+func TestCollect(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		total   int
+		limit   int
+		want    int
+		wantErr error
+	}{
+		{name: "under the limit", total: 25, limit: 30, want: 25},
+		{name: "at the limit", total: 30, limit: 30, want: 30},
+		{name: "over the limit", total: 31, limit: 30, wantErr: ErrTooManyResults},
+		{name: "no items", total: 0, limit: 30, want: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &fakePages{total: tc.total}
+			items, err := collect(paginate(ctx, f.fetch, &IterOptions{PerPage: 10, MaxItems: tc.limit + 1}), tc.limit)
+
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Nil(t, items)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, items, tc.want)
+			assert.NotNil(t, items, "an empty result is an empty slice")
+		})
+	}
+
+	t.Run("returns the first error", func(t *testing.T) {
+		f := &fakePages{total: 95, failPage: 2}
+		items, err := collect(paginate(ctx, f.fetch, &IterOptions{PerPage: 10}), 100)
+
+		assert.EqualError(t, err, "page failed")
+		assert.Nil(t, items)
+	})
+}
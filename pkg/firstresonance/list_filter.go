@@ -0,0 +1,176 @@
+package firstresonance
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"sort"
+	"strings"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/filter"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Fields that filter expressions can use, by entity
+var (
+	partFilterFields = filter.Fields{
+		"id":          filter.String,
+		"name":        filter.String,
+		"description": filter.String,
+		"type":        filter.String,
+		"status":      filter.String,
+	}
+	orderFilterFields = filter.Fields{
+		"id":          filter.String,
+		"customer_id": filter.String,
+		"supplier_id": filter.String,
+		"priority":    filter.String,
+		"due_date":    filter.Date,
+		"status":      filter.String,
+	}
+	supplierFilterFields = filter.Fields{
+		"id":     filter.String,
+		"name":   filter.String,
+		"status": filter.String,
+	}
+	inventoryItemFilterFields = filter.Fields{
+		"id":            filter.String,
+		"part_id":       filter.String,
+		"location":      filter.String,
+		"quantity":      filter.Number,
+		"status":        filter.String,
+		"lot_number":    filter.String,
+		"serial_number": filter.String,
+		"expiry_date":   filter.Date,
+	}
+	abomFilterFields = filter.Fields{
+		"id":            filter.String,
+		"name":          filter.String,
+		"description":   filter.String,
+		"version":       filter.String,
+		"status":        filter.String,
+		"part_id":       filter.String,
+		"serial_number": filter.String,
+		"created_at":    filter.Date,
+		"updated_at":    filter.Date,
+	}
+)
+
+// lowerCaseFields are the fields the API stores in lower case
+var lowerCaseFields = map[string]bool{"status": true, "type": true}
+
+// WithFilter returns a ToolOption that adds a "filter" parameter for an entity with the given fields.
+func WithFilter(fields filter.Fields) mcp.ToolOption {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return mcp.WithString("filter",
+		mcp.Description(fmt.Sprintf("Filter expression, e.g. `status in (active,draft) and priority = high`. "+
+			"Operators: =, !=, <, <=, >, >=, in (...), not in (...), contains; combine with and, or, not and parentheses. "+
+			"Fields: %s", strings.Join(names, ", "))),
+	)
+}
+
+// OptionalFilterParam parses the "filter" parameter from the request against
+// an entity's fields. It returns nil if the parameter is not present.
+func OptionalFilterParam(r mcp.CallToolRequest, fields filter.Fields) (*filter.Filter, error) {
+	expr, err := OptionalParam[string](r, "filter")
+	if err != nil || expr == "" {
+		return nil, err
+	}
+	f, err := filter.Parse(expr, fields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	return f, nil
+}
+
+// pushdownEqualities returns the filter's equality values that can narrow the
+// query. The API matches them exactly while the filter ignores case, so only
+// values of fields stored in lower case, given in lower case, are sent;
+// pushing down others could drop records the filter matches.
+func pushdownEqualities(f *filter.Filter) map[string]string {
+	values := map[string]string{}
+	for field, v := range f.Equalities() {
+		if lowerCaseFields[field] && v == strings.ToLower(v) {
+			values[field] = v
+		}
+	}
+	return values
+}
+
+// filterSeq returns an iterator over the items of seq matching the filter,
+// or seq itself if the filter is nil
+func filterSeq[T any](f *filter.Filter, seq iter.Seq2[T, error]) iter.Seq2[T, error] {
+	if f == nil {
+		return seq
+	}
+	return func(yield func(T, error) bool) {
+		for item, err := range seq {
+			if err != nil {
+				yield(item, err)
+				return
+			}
+			record, err := recordOf(item)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			if f.Match(record) && !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+// applyFilter returns the items matching the filter, or all items if it is nil
+func applyFilter[T any](f *filter.Filter, items []T) ([]T, error) {
+	if f == nil {
+		return items, nil
+	}
+
+	matched := []T{}
+	for _, item := range items {
+		record, err := recordOf(item)
+		if err != nil {
+			return nil, err
+		}
+		if f.Match(record) {
+			matched = append(matched, item)
+		}
+	}
+	return matched, nil
+}
+
+// recordOf returns an item as a record keyed by its JSON field names
+func recordOf(item interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal item: %w", err)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal item: %w", err)
+	}
+	return record, nil
+}
+
+// filterPage removes the items not matching the filter from a page. The
+// cursor still points past the whole unfiltered page, so a filtered page may
+// have fewer items than requested, or none, while more remain. Total is left
+// out, since the API counts the unfiltered list.
+func filterPage[T any](f *filter.Filter, page *Page[T]) (*Page[T], error) {
+	if f == nil {
+		return page, nil
+	}
+
+	items, err := applyFilter(f, page.Items)
+	if err != nil {
+		return nil, err
+	}
+	return &Page[T]{Items: items, NextCursor: page.NextCursor, HasMore: page.HasMore}, nil
+}
@@ -0,0 +1,28 @@
+package firstresonance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/filter"
+)
+
+func TestABomFilterFields(t *testing.T) {
+	f, err := filter.Parse(`part_id = "P-100" and serial_number = "SN-7"`, abomFilterFields)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		abom *ABom
+		want bool
+	}{
+		{abom: &ABom{ID: "a1", PartID: "P-100", SerialNumber: "SN-7"}, want: true},
+		{abom: &ABom{ID: "a2", PartID: "P-100", SerialNumber: "SN-8"}},
+		{abom: &ABom{ID: "a3", PartID: "P-200", SerialNumber: "SN-7"}},
+	} {
+		record, err := recordOf(tc.abom)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, f.Match(record), tc.abom.ID)
+	}
+}
@@ -0,0 +1,191 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidOrderItems is returned when an order's items fail validation
+var ErrInvalidOrderItems = errors.New("invalid order items")
+
+// orderItemInput is an order item as callers give it. Receipt fields are
+// left out, since only receiving an order may set them.
+type orderItemInput struct {
+	PartID        string  `json:"part_id"`
+	Quantity      float64 `json:"quantity"`
+	UnitPrice     float64 `json:"unit_price,omitempty"`
+	Unit          string  `json:"unit,omitempty"`
+	RequestedDate string  `json:"requested_date,omitempty"`
+}
+
+// ParseOrderItems converts order items decoded from JSON, such as tool
+// arguments, to OrderItems. Unknown fields, and the receipt fields only
+// receiving an order sets, are rejected.
+func ParseOrderItems(raw []interface{}) ([]OrderItem, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOrderItems, err)
+	}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.DisallowUnknownFields()
+	var inputs []orderItemInput
+	if err := dec.Decode(&inputs); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOrderItems, err)
+	}
+	items := make([]OrderItem, len(inputs))
+	for i, in := range inputs {
+		items[i] = OrderItem{
+			PartID:        in.PartID,
+			Quantity:      in.Quantity,
+			UnitPrice:     in.UnitPrice,
+			Unit:          in.Unit,
+			RequestedDate: in.RequestedDate,
+		}
+	}
+	return items, nil
+}
+
+// keepReceipts returns a copy of the items replacing an order's items, with
+// the receipts of the lines they replace carried over to items with no
+// receipt of their own for the same part and unit. The receipt status of a
+// line carried over follows its new quantity.
+func keepReceipts(before, after []OrderItem) []OrderItem {
+	items := append([]OrderItem{}, after...)
+	for i := range min(len(before), len(items)) {
+		old, item := before[i], &items[i]
+		if old.ReceivedQuantity == 0 || item.ReceivedQuantity != 0 || item.PartID != old.PartID || item.Unit != old.Unit {
+			continue
+		}
+		item.ReceivedQuantity = old.ReceivedQuantity
+		item.ReceiptStatus = ReceiptStatusPartial
+		item.ReceivedDate = ""
+		if item.ReceivedQuantity >= item.Quantity {
+			item.ReceiptStatus = ReceiptStatusReceived
+			item.ReceivedDate = old.ReceivedDate
+			if item.ReceivedDate == "" {
+				item.ReceivedDate = time.Now().UTC().Format(time.DateOnly)
+			}
+		}
+	}
+	return items
+}
+
+// checkReceiptsKept returns an error wrapping ErrInvalidOrderUpdate if
+// replacing an order's items would undo a receipt: every line received so
+// far must stay on its line, for the same part and unit, with at least the
+// quantity received
+func checkReceiptsKept(orderID string, before, after []OrderItem) error {
+	var problems []string
+	for i, item := range before {
+		if item.ReceivedQuantity == 0 {
+			continue
+		}
+		line := i + 1
+		switch {
+		case i >= len(after):
+			problems = append(problems, fmt.Sprintf("line %d has been received and can't be removed", line))
+		case after[i].PartID != item.PartID || after[i].Unit != item.Unit:
+			problems = append(problems, fmt.Sprintf("line %d has been received and can't change part or unit", line))
+		case after[i].ReceivedQuantity < item.ReceivedQuantity:
+			problems = append(problems, fmt.Sprintf("line %d has %v received, which only receive_order records", line, item.ReceivedQuantity))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: order %s: %s", ErrInvalidOrderUpdate, orderID, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ValidateItems checks that an order has items, that every item's part
+// exists, that quantities are positive and prices not negative, that
+// requested dates are dates, and that every line's unit converts to the unit
+// its part's stock is counted in, so the order can be received: the part's
+// base unit, or each for a part without one. All problems found are
+// reported together.
+func (s *OrdersService) ValidateItems(ctx context.Context, items []OrderItem) error {
+	if len(items) == 0 {
+		return fmt.Errorf("%w: an order must have at least one item", ErrInvalidOrderItems)
+	}
+
+	var problems []string
+	exists := map[string]bool{}
+	for i, item := range items {
+		line := i + 1
+		if item.PartID == "" {
+			problems = append(problems, fmt.Sprintf("line %d: part_id is required", line))
+		}
+		if item.Quantity <= 0 {
+			problems = append(problems, fmt.Sprintf("line %d: quantity must be positive", line))
+		}
+		if item.UnitPrice < 0 {
+			problems = append(problems, fmt.Sprintf("line %d: unit_price cannot be negative", line))
+		}
+		if item.RequestedDate != "" {
+			if _, err := time.Parse(time.DateOnly, item.RequestedDate); err != nil {
+				problems = append(problems, fmt.Sprintf("line %d: requested_date %q is not a date (YYYY-MM-DD)", line, item.RequestedDate))
+			}
+		}
+		if item.PartID == "" {
+			continue
+		}
+
+		if _, _, err := s.client.Units.StockQuantity(item.PartID, item.Quantity, item.Unit); err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %v", line, err))
+		}
+
+		found, checked := exists[item.PartID]
+		if !checked {
+			_, err := s.client.Parts.Get(ctx, item.PartID)
+			if err != nil && !errors.Is(err, ErrPartNotFound) {
+				return fmt.Errorf("failed to get part %s: %w", item.PartID, err)
+			}
+			found = err == nil
+			exists[item.PartID] = found
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("line %d: part %s does not exist", line, item.PartID))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidOrderItems, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// AddItem validates an item and appends it to an order's items
+func (s *OrdersService) AddItem(ctx context.Context, orderID string, item OrderItem) (*Order, error) {
+	order, err := s.Get(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	items := append(append([]OrderItem{}, order.Items...), item)
+	if err := s.ValidateItems(ctx, items); err != nil {
+		return nil, err
+	}
+	return s.Update(ctx, orderID, &OrderUpdateRequest{Items: &items})
+}
+
+// RemoveItem removes the item on a line, numbered from 1, of an order.
+// The last item of an order cannot be removed, nor can a line received or
+// followed by a received line.
+func (s *OrdersService) RemoveItem(ctx context.Context, orderID string, line int) (*Order, error) {
+	order, err := s.Get(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	if line < 1 || line > len(order.Items) {
+		return nil, fmt.Errorf("%w: order %s has no line %d, it has %d lines", ErrInvalidOrderItems, orderID, line, len(order.Items))
+	}
+	if len(order.Items) == 1 {
+		return nil, fmt.Errorf("%w: cannot remove the only item of an order", ErrInvalidOrderItems)
+	}
+	items := append(append([]OrderItem{}, order.Items[:line-1]...), order.Items[line:]...)
+	return s.Update(ctx, orderID, &OrderUpdateRequest{Items: &items})
+}
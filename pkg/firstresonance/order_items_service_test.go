@@ -0,0 +1,93 @@
+package firstresonance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOrderItems(t *testing.T) {
+	items, err := ParseOrderItems([]interface{}{
+		map[string]interface{}{"part_id": "p1", "quantity": 2.0, "unit_price": 5.0, "unit": "ea"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []OrderItem{{PartID: "p1", Quantity: 2, UnitPrice: 5, Unit: "ea"}}, items)
+
+	for _, field := range []string{"received_quantity", "receipt_status", "received_date", "colour"} {
+		_, err := ParseOrderItems([]interface{}{
+			map[string]interface{}{"part_id": "p1", "quantity": 2.0, field: "x"},
+		})
+		assert.ErrorIs(t, err, ErrInvalidOrderItems, field)
+	}
+}
+
+func TestKeepReceipts(t *testing.T) {
+	before := []OrderItem{
+		{PartID: "p1", Quantity: 4, ReceivedQuantity: 2, ReceiptStatus: ReceiptStatusPartial},
+		{PartID: "p2", Quantity: 1},
+	}
+
+	items := keepReceipts(before, []OrderItem{{PartID: "p1", Quantity: 6}, {PartID: "p2", Quantity: 3}})
+	assert.Equal(t, []OrderItem{
+		{PartID: "p1", Quantity: 6, ReceivedQuantity: 2, ReceiptStatus: ReceiptStatusPartial},
+		{PartID: "p2", Quantity: 3},
+	}, items)
+
+	items = keepReceipts(before, []OrderItem{{PartID: "p1", Quantity: 2}})
+	assert.Equal(t, ReceiptStatusReceived, items[0].ReceiptStatus, "a line cut to its received quantity is received")
+	assert.NotEmpty(t, items[0].ReceivedDate)
+
+	items = keepReceipts(before, []OrderItem{{PartID: "p3", Quantity: 4}})
+	assert.Zero(t, items[0].ReceivedQuantity, "receipts stay with their part")
+}
+
+func TestOrderUpdateKeepsReceipts(t *testing.T) {
+	ctx := context.Background()
+	order := &Order{
+		ID:         "o1",
+		SupplierID: "s1",
+		DueDate:    "2026-11-01",
+		Status:     OrderStatusApproved,
+		Items: []OrderItem{
+			{PartID: "p1", Quantity: 4, UnitPrice: 5},
+			{PartID: "p2", Quantity: 4, UnitPrice: 5, ReceivedQuantity: 2, ReceiptStatus: ReceiptStatusPartial},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		items   []OrderItem
+		wantErr bool
+	}{
+		{name: "keeps the receipt of a replaced line", items: []OrderItem{{PartID: "p1", Quantity: 4, UnitPrice: 5}, {PartID: "p2", Quantity: 5, UnitPrice: 5}}},
+		{name: "refuses to drop a received line", items: []OrderItem{{PartID: "p1", Quantity: 4, UnitPrice: 5}}, wantErr: true},
+		{name: "refuses to change the part of a received line", items: []OrderItem{{PartID: "p1", Quantity: 4, UnitPrice: 5}, {PartID: "p3", Quantity: 4, UnitPrice: 5}}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, mutations := fakeOrderServer(t, order)
+
+			_, err := client.Orders.Update(ctx, "o1", &OrderUpdateRequest{Items: &tc.items})
+
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidOrderUpdate)
+				assert.Zero(t, mutations.Load())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int32(1), mutations.Load())
+			assert.Zero(t, tc.items[1].ReceivedQuantity, "the caller's items are not changed")
+		})
+	}
+
+	t.Run("refuses to remove a line before a received one", func(t *testing.T) {
+		client, mutations := fakeOrderServer(t, order)
+
+		_, err := client.Orders.RemoveItem(ctx, "o1", 1)
+
+		assert.ErrorIs(t, err, ErrInvalidOrderUpdate)
+		assert.Zero(t, mutations.Load())
+	})
+}
@@ -0,0 +1,177 @@
+package firstresonance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/lifecycle"
+)
+
+// Order statuses of the default lifecycle
+const (
+	OrderStatusDraft     = "draft"
+	OrderStatusSubmitted = "submitted"
+	OrderStatusApproved  = "approved"
+	OrderStatusReceived  = "received"
+	OrderStatusClosed    = "closed"
+	OrderStatusCancelled = "cancelled"
+)
+
+// ErrInvalidTransition is returned when an order cannot move to a status
+var ErrInvalidTransition = errors.New("invalid order status transition")
+
+// ErrInvalidOrderUpdate is returned when an order cannot be edited as an
+// update would, because its status is final or locked or because the order
+// would lose a field its status requires
+var ErrInvalidOrderUpdate = errors.New("invalid order update")
+
+// DefaultOrderLifecycle returns the lifecycle orders follow unless another
+// is configured: draft → submitted → approved → received → closed, with
+// submitted orders able to return to draft and open orders able to be
+// cancelled. Received orders are locked against edits.
+func DefaultOrderLifecycle() *lifecycle.Lifecycle {
+	return &lifecycle.Lifecycle{
+		Initial: OrderStatusDraft,
+		States: map[string]lifecycle.State{
+			OrderStatusDraft:     {},
+			OrderStatusSubmitted: {Requires: []string{"supplier_id", "due_date"}},
+			OrderStatusApproved:  {Requires: []string{"supplier_id", "due_date"}},
+			OrderStatusReceived:  {Locked: true},
+			OrderStatusClosed:    {Final: true},
+			OrderStatusCancelled: {Final: true},
+		},
+		Transitions: []lifecycle.Transition{
+			{From: OrderStatusDraft, To: OrderStatusSubmitted, Guards: []string{"has_items"}},
+			{From: OrderStatusDraft, To: OrderStatusCancelled},
+			{From: OrderStatusSubmitted, To: OrderStatusApproved, Guards: []string{"items_priced"}},
+			{From: OrderStatusSubmitted, To: OrderStatusDraft},
+			{From: OrderStatusSubmitted, To: OrderStatusCancelled},
+			{From: OrderStatusApproved, To: OrderStatusReceived, Guards: []string{"fully_received"}},
+			{From: OrderStatusApproved, To: OrderStatusCancelled},
+			{From: OrderStatusReceived, To: OrderStatusClosed},
+		},
+	}
+}
+
+// orderGuards are the guards order lifecycle transitions can name
+var orderGuards = lifecycle.Guards{
+	"has_items": func(order map[string]interface{}) string {
+		if items, _ := order["items"].([]interface{}); len(items) == 0 {
+			return "the order has no items"
+		}
+		return ""
+	},
+	"items_priced": func(order map[string]interface{}) string {
+		items, _ := order["items"].([]interface{})
+		for i, raw := range items {
+			item, _ := raw.(map[string]interface{})
+			if price, _ := item["unit_price"].(float64); price <= 0 {
+				return fmt.Sprintf("line %d has no unit_price", i+1)
+			}
+		}
+		return ""
+	},
+	"fully_received": func(order map[string]interface{}) string {
+		items, _ := order["items"].([]interface{})
+		for i, raw := range items {
+			item, _ := raw.(map[string]interface{})
+			ordered, _ := item["quantity"].(float64)
+			received, _ := item["received_quantity"].(float64)
+			if received < ordered {
+				return fmt.Sprintf("line %d has %v of %v received, use receive_order", i+1, received, ordered)
+			}
+		}
+		return ""
+	},
+}
+
+// SetOrderLifecycleFile replaces the default order lifecycle with one read
+// from a JSON file
+func (c *Client) SetOrderLifecycleFile(path string) error {
+	l, err := lifecycle.Load(path, orderGuards)
+	if err != nil {
+		return err
+	}
+	c.orderLifecycle = l
+	return nil
+}
+
+// Transitions returns the status moves the order lifecycle has out of an
+// order's current status, and whether the order can make each
+func (s *OrdersService) Transitions(ctx context.Context, id string) (*OrderTransitions, error) {
+	order, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	record, err := recordOf(order)
+	if err != nil {
+		return nil, err
+	}
+
+	l := s.client.orderLifecycle
+	moves, err := l.Moves(order.Status, record, orderGuards)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTransition, err)
+	}
+	status, _ := l.Current(order.Status)
+	return &OrderTransitions{OrderID: order.ID, Status: status, Final: l.States[status].Final, Moves: moves}, nil
+}
+
+// checkUpdate returns an error wrapping ErrInvalidTransition if an update's
+// status change is not allowed, or ErrInvalidOrderUpdate if the order can't
+// be edited as the update would, such as by replacing items already
+// received. Required fields and guards are checked against the order as it
+// would be after the update.
+func (s *OrdersService) checkUpdate(ctx context.Context, id string, update *OrderUpdateRequest) error {
+	order, err := s.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+	return s.checkOrderUpdate(order, update)
+}
+
+// checkOrderUpdate checks an update of an order as checkUpdate does
+func (s *OrdersService) checkOrderUpdate(order *Order, update *OrderUpdateRequest) error {
+	id := order.ID
+	after := *order
+	if update.Priority != nil {
+		after.Priority = *update.Priority
+	}
+	if update.DueDate != nil {
+		after.DueDate = *update.DueDate
+	}
+	if update.Items != nil {
+		if err := checkReceiptsKept(id, order.Items, *update.Items); err != nil {
+			return err
+		}
+		after.Items = *update.Items
+	}
+	record, err := recordOf(&after)
+	if err != nil {
+		return err
+	}
+
+	l := s.client.orderLifecycle
+	edited := update.Priority != nil || update.DueDate != nil || update.Items != nil
+	from, err := l.Current(order.Status)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOrderUpdate, err)
+	}
+	if update.Status == nil || *update.Status == from {
+		// Staying in the status, the order must keep what it requires
+		if err := l.CheckEdit(from, record); err != nil {
+			return fmt.Errorf("%w: order %s: %w", ErrInvalidOrderUpdate, id, err)
+		}
+		return nil
+	}
+	if edited {
+		if err := l.CheckEditable(from); err != nil {
+			return fmt.Errorf("%w: order %s: %w", ErrInvalidOrderUpdate, id, err)
+		}
+	}
+	if err := l.Check(from, *update.Status, record, orderGuards); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidTransition, err)
+	}
+	return nil
+}
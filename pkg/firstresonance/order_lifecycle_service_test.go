@@ -0,0 +1,92 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOrderServer serves an order to GetOrder queries and echoes it back to
+// updateOrder mutations, counting the mutations
+func fakeOrderServer(t *testing.T, order *Order) (*Client, *atomic.Int32) {
+	t.Helper()
+	var mutations atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query string `json:"query"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		field := "order"
+		if strings.Contains(body.Query, "mutation") {
+			mutations.Add(1)
+			field = "updateOrder"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{field: order}})
+	}))
+	t.Cleanup(server.Close)
+	return NewClient(server.URL, "token", nil), &mutations
+}
+
+func TestOrderUpdateLifecycle(t *testing.T) {
+	ctx := context.Background()
+	approved := func(status string) *Order {
+		return &Order{
+			ID:         "o1",
+			SupplierID: "s1",
+			DueDate:    "2026-11-01",
+			Status:     status,
+			Items:      []OrderItem{{PartID: "p1", Quantity: 2, UnitPrice: 5}},
+		}
+	}
+	str := func(s string) *string { return &s }
+	items := []OrderItem{{PartID: "p1", Quantity: 3, UnitPrice: 5}}
+
+	tests := []struct {
+		name    string
+		status  string
+		update  *OrderUpdateRequest
+		wantErr error
+	}{
+		{name: "edit an approved order", status: OrderStatusApproved, update: &OrderUpdateRequest{Priority: str("high")}},
+		{name: "edit a closed order", status: OrderStatusClosed, update: &OrderUpdateRequest{Priority: str("high")}, wantErr: ErrInvalidOrderUpdate},
+		{name: "replace the items of a closed order", status: OrderStatusClosed, update: &OrderUpdateRequest{Items: &items}, wantErr: ErrInvalidOrderUpdate},
+		{name: "edit a received order", status: OrderStatusReceived, update: &OrderUpdateRequest{DueDate: str("2026-12-01")}, wantErr: ErrInvalidOrderUpdate},
+		{name: "close a received order", status: OrderStatusReceived, update: &OrderUpdateRequest{Status: str(OrderStatusClosed)}},
+		{name: "clear the due date of an approved order", status: OrderStatusApproved, update: &OrderUpdateRequest{DueDate: str("")}, wantErr: ErrInvalidOrderUpdate},
+		{name: "clear the due date while cancelling", status: OrderStatusApproved, update: &OrderUpdateRequest{DueDate: str(""), Status: str(OrderStatusCancelled)}},
+		{name: "skip a status", status: OrderStatusDraft, update: &OrderUpdateRequest{Status: str(OrderStatusReceived)}, wantErr: ErrInvalidTransition},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, mutations := fakeOrderServer(t, approved(tc.status))
+
+			_, err := client.Orders.Update(ctx, "o1", tc.update)
+
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Zero(t, mutations.Load(), "a refused update is not sent")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int32(1), mutations.Load())
+		})
+	}
+
+	t.Run("remove an item of a closed order", func(t *testing.T) {
+		order := approved(OrderStatusClosed)
+		order.Items = append(order.Items, OrderItem{PartID: "p2", Quantity: 1, UnitPrice: 3})
+		client, mutations := fakeOrderServer(t, order)
+
+		_, err := client.Orders.RemoveItem(ctx, "o1", 2)
+
+		assert.ErrorIs(t, err, ErrInvalidOrderUpdate)
+		assert.Zero(t, mutations.Load())
+	})
+}
@@ -0,0 +1,246 @@
+package firstresonance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/audit"
+)
+
+// DefaultReceivingLocation is where received stock is put unless a receipt names a location
+const DefaultReceivingLocation = "receiving"
+
+// ErrInvalidReceipt is returned when a receipt is malformed or the order cannot be received
+var ErrInvalidReceipt = errors.New("invalid order receipt")
+
+// Receive puts the quantities received of an order's lines into inventory at
+// the receipt's location, incrementing the item holding each part there or
+// creating one. Quantities are received in the unit of their line and put
+// into inventory in the part's base unit. Each line's received quantity and receipt status are updated
+// and, once every line has been received in full, the order moves to
+// OrderStatusReceived. Lines whose received quantity differs from the
+// quantity ordered are reported as discrepancies and recorded in the audit
+// log.
+//
+// The update of the order's lines, and the move to OrderStatusReceived, are
+// checked before any stock is put away.
+// If putting a line into inventory fails, the lines already put away are
+// still recorded on the order and the error is returned with the result; so
+// are the received quantities if the order then can't move to received.
+func (s *OrdersService) Receive(ctx context.Context, orderID string, receipt *OrderReceipt) (*OrderReceiptResult, error) {
+	location := receipt.Location
+	if location == "" {
+		location = DefaultReceivingLocation
+	}
+
+	order, err := s.Get(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if err := s.checkReceivable(order); err != nil {
+		return nil, err
+	}
+	if err := validateReceiptLines(order, receipt.Lines); err != nil {
+		return nil, err
+	}
+
+	// Stock is counted in each part's base unit, so convert every line
+	// before putting any away
+	stockQuantities := make([]float64, len(receipt.Lines))
+	for i, line := range receipt.Lines {
+		item := order.Items[line.Line-1]
+		quantity, _, err := s.client.Units.StockQuantity(item.PartID, line.Quantity, item.Unit)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidReceipt, line.Line, err)
+		}
+		stockQuantities[i] = quantity
+	}
+
+	// Check the order may take the received quantities, and move to
+	// received as it will once every line is in, before any stock is put
+	// away
+	items := append([]OrderItem{}, order.Items...)
+	result := &OrderReceiptResult{Inventory: []*InventoryItem{}, Discrepancies: []ReceiptDiscrepancy{}}
+	planned := append([]OrderItem{}, order.Items...)
+	s.recordReceipt(planned, receipt.Lines, nil)
+	check := &OrderUpdateRequest{Items: &planned}
+	if fullyReceived(planned) && order.Status != OrderStatusReceived {
+		status := OrderStatusReceived
+		check.Status = &status
+	}
+	if err := s.checkUpdate(ctx, orderID, check); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidReceipt, err)
+	}
+
+	// Put each line into inventory, stopping at the first failure. Changes
+	// that were made but not audited count as made.
+	var received []OrderReceiptLine
+	var receiveErr error
+	var auditErrs []error
+	for i, line := range receipt.Lines {
+		item := items[line.Line-1]
+		note := fmt.Sprintf("order %s line %d", orderID, line.Line)
+		if receipt.Note != "" {
+			note += ": " + receipt.Note
+		}
+		stocked, err := s.stock(ctx, item.PartID, location, stockQuantities[i], note)
+		if auditErr := unaudited(err); auditErr != nil {
+			auditErrs = append(auditErrs, auditErr)
+		} else if err != nil {
+			receiveErr = fmt.Errorf("failed to receive line %d into inventory: %w", line.Line, err)
+			break
+		}
+		result.Inventory = append(result.Inventory, stocked)
+		received = append(received, line)
+	}
+	if len(received) == 0 {
+		return nil, receiveErr
+	}
+
+	// Record what was received on the order's lines, then move the order to
+	// received on its own, so the quantities are kept even if the move fails
+	s.recordReceipt(items, received, result)
+	result.Complete = fullyReceived(items)
+	result.Order, err = s.Update(ctx, orderID, &OrderUpdateRequest{Items: &items})
+	if auditErr := unaudited(err); auditErr != nil {
+		auditErrs = append(auditErrs, auditErr)
+	} else if err != nil {
+		// Stock has already been put away, so say so alongside the failure
+		err = fmt.Errorf("stock for %d lines was put into inventory at %s but the order was not updated: %w", len(received), location, err)
+		return result, errors.Join(receiveErr, err)
+	}
+	if result.Complete && order.Status != OrderStatusReceived {
+		status := OrderStatusReceived
+		moved, err := s.Update(ctx, orderID, &OrderUpdateRequest{Status: &status})
+		if auditErr := unaudited(err); auditErr != nil {
+			auditErrs = append(auditErrs, auditErr)
+		} else if err != nil {
+			err = fmt.Errorf("the received quantities were recorded but the order was not moved to %s: %w", status, err)
+			return result, errors.Join(receiveErr, err)
+		}
+		result.Order = moved
+	}
+
+	entry := &audit.Entry{
+		Entity:   auditEntityOrder,
+		EntityID: orderID,
+		Action:   audit.ActionReceive,
+		Details: map[string]interface{}{
+			"location":      location,
+			"lines":         received,
+			"discrepancies": result.Discrepancies,
+			"complete":      result.Complete,
+			"note":          receipt.Note,
+		},
+	}
+	auditErrs = append(auditErrs, s.client.Audit.append(ctx, entry, receiveErr))
+	return result, auditOutcome(receiveErr, auditErrs...)
+}
+
+// recordReceipt adds the quantities received on lines to the order's items,
+// updating their receipt status and date. With a result, it also reports the
+// lines received in a different quantity than ordered.
+func (s *OrdersService) recordReceipt(items []OrderItem, lines []OrderReceiptLine, result *OrderReceiptResult) {
+	for _, line := range lines {
+		item := &items[line.Line-1]
+		item.ReceivedQuantity = s.client.Units.roundIn(item.Unit, item.ReceivedQuantity+line.Quantity)
+		item.ReceiptStatus = ReceiptStatusPartial
+		if item.ReceivedQuantity >= item.Quantity {
+			item.ReceiptStatus = ReceiptStatusReceived
+			if item.ReceivedDate == "" {
+				item.ReceivedDate = time.Now().UTC().Format(time.DateOnly)
+			}
+		}
+		if result == nil || item.ReceivedQuantity == item.Quantity {
+			continue
+		}
+		kind := "short"
+		if item.ReceivedQuantity > item.Quantity {
+			kind = "over"
+		}
+		result.Discrepancies = append(result.Discrepancies, ReceiptDiscrepancy{
+			Line:     line.Line,
+			PartID:   item.PartID,
+			Ordered:  item.Quantity,
+			Received: item.ReceivedQuantity,
+			Kind:     kind,
+		})
+	}
+}
+
+// checkReceivable returns an error wrapping ErrInvalidReceipt unless the
+// order lifecycle has a transition to OrderStatusReceived out of the
+// order's status
+func (s *OrdersService) checkReceivable(order *Order) error {
+	l := s.client.orderLifecycle
+	status, err := l.Current(order.Status)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidReceipt, err)
+	}
+	var receivable []string
+	for _, t := range l.Transitions {
+		if t.To != OrderStatusReceived {
+			continue
+		}
+		if t.From == status {
+			return nil
+		}
+		receivable = append(receivable, t.From)
+	}
+	return fmt.Errorf("%w: order %s is %s, only orders that are %s can be received", ErrInvalidReceipt, order.ID, status, strings.Join(receivable, " or "))
+}
+
+// validateReceiptLines checks that a receipt's lines are lines of the order,
+// each received once and in a positive quantity
+func validateReceiptLines(order *Order, lines []OrderReceiptLine) error {
+	if len(lines) == 0 {
+		return fmt.Errorf("%w: no lines received", ErrInvalidReceipt)
+	}
+	var problems []string
+	seen := map[int]bool{}
+	for _, line := range lines {
+		switch {
+		case line.Line < 1 || line.Line > len(order.Items):
+			problems = append(problems, fmt.Sprintf("order %s has no line %d, it has %d lines", order.ID, line.Line, len(order.Items)))
+		case seen[line.Line]:
+			problems = append(problems, fmt.Sprintf("line %d is received more than once", line.Line))
+		case order.Items[line.Line-1].PartID == "":
+			problems = append(problems, fmt.Sprintf("line %d has no part", line.Line))
+		}
+		if line.Quantity <= 0 {
+			problems = append(problems, fmt.Sprintf("line %d: quantity must be positive", line.Line))
+		}
+		seen[line.Line] = true
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidReceipt, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// stock adds a quantity of a part to the inventory item holding it at a
+// location, creating the item if there is none
+func (s *OrdersService) stock(ctx context.Context, partID, location string, quantity float64, note string) (*InventoryItem, error) {
+	inventory := s.client.Inventory
+	item, err := inventory.findByPartAndLocation(ctx, partID, location, "")
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return inventory.Create(ctx, &InventoryItem{PartID: partID, Location: location, Quantity: quantity})
+	}
+	return inventory.Adjust(ctx, item.ID, &InventoryAdjustment{Delta: quantity, Reason: AdjustmentReasonReceipt, Note: note})
+}
+
+// fullyReceived reports whether every item has been received in full
+func fullyReceived(items []OrderItem) bool {
+	for _, item := range items {
+		if item.ReceivedQuantity < item.Quantity {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,217 @@
+package firstresonance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/dates"
+)
+
+const (
+	// dueSoonDays is how many days ahead an order counts as due this week
+	dueSoonDays = 7
+	// minSupplierHistory is how many past orders a supplier needs before its
+	// lateness counts against its open orders
+	minSupplierHistory = 3
+	// atRiskLateRate is the share of late orders at which a supplier's open
+	// orders are at risk
+	atRiskLateRate = 0.25
+	// orderAnalysisLimit caps how many orders a report reads
+	orderAnalysisLimit = 10000
+)
+
+// orderPriorityRank orders priorities from most to least urgent; unknown
+// priorities rank with normal ones
+var orderPriorityRank = map[string]int{
+	"critical": 0,
+	"urgent":   0,
+	"high":     1,
+	"medium":   2,
+	"normal":   2,
+	"low":      3,
+}
+
+// supplierLateness counts a supplier's orders with a known outcome and how
+// many of them were late
+type supplierLateness struct {
+	Orders int
+	Late   int
+}
+
+// RiskReport classifies open orders as overdue, due within the next week or
+// at risk as of a date, and returns them most urgent first. Orders are at
+// risk if their supplier has delivered a quarter or more of its past orders
+// late, if they order parts below their minimum stock, or if their due date
+// cannot be parsed. Shortages are only checked if reorder points are
+// configured.
+func (s *OrdersService) RiskReport(ctx context.Context, asOf time.Time) ([]*OrderRisk, error) {
+	orders, err := s.analysisOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	history := s.supplierHistory(orders, asOf)
+
+	shortages := map[string]*LowStockItem{}
+	low, err := s.client.ReorderPoints.LowStock(ctx)
+	if err != nil && !errors.Is(err, ErrReorderPointsDisabled) {
+		return nil, fmt.Errorf("failed to check stock levels: %w", err)
+	}
+	for _, item := range low {
+		shortages[item.PartID] = item
+	}
+
+	risks := []*OrderRisk{}
+	for _, order := range orders {
+		if !s.isOpen(order) {
+			continue
+		}
+		risk := &OrderRisk{Order: order, Reasons: []string{}}
+
+		if order.DueDate != "" {
+			due, err := dates.Parse(order.DueDate)
+			if err != nil {
+				risk.Reasons = append(risk.Reasons, fmt.Sprintf("due date %q could not be parsed", order.DueDate))
+			} else {
+				days := dates.DaysBetween(asOf, due)
+				risk.DueDate = due.Format(time.DateOnly)
+				risk.DaysUntilDue = &days
+				switch {
+				case days < 0:
+					risk.Category = OrderRiskOverdue
+					risk.Reasons = append(risk.Reasons, fmt.Sprintf("%d days overdue", -days))
+				case days < dueSoonDays:
+					risk.Category = OrderRiskDueThisWeek
+					risk.Reasons = append(risk.Reasons, fmt.Sprintf("due in %d days", days))
+				}
+			}
+		}
+
+		if h := history[order.SupplierID]; order.SupplierID != "" && h != nil && h.Orders >= minSupplierHistory {
+			if rate := float64(h.Late) / float64(h.Orders); rate >= atRiskLateRate {
+				risk.Reasons = append(risk.Reasons, fmt.Sprintf("supplier %s delivered %d of %d orders late", order.SupplierID, h.Late, h.Orders))
+			}
+		}
+		seen := map[string]bool{}
+		for _, item := range order.Items {
+			if short, ok := shortages[item.PartID]; ok && !seen[item.PartID] {
+				seen[item.PartID] = true
+				risk.Reasons = append(risk.Reasons, fmt.Sprintf("part %s is short: %g on hand, minimum %g", item.PartID, short.OnHand, short.MinStock))
+			}
+		}
+
+		if risk.Category == "" {
+			if len(risk.Reasons) == 0 {
+				continue
+			}
+			risk.Category = OrderRiskAtRisk
+		}
+		risks = append(risks, risk)
+	}
+
+	categoryRank := map[string]int{OrderRiskOverdue: 0, OrderRiskDueThisWeek: 1, OrderRiskAtRisk: 2}
+	sort.SliceStable(risks, func(i, j int) bool {
+		a, b := risks[i], risks[j]
+		if categoryRank[a.Category] != categoryRank[b.Category] {
+			return categoryRank[a.Category] < categoryRank[b.Category]
+		}
+		if pa, pb := priorityRank(a.Order.Priority), priorityRank(b.Order.Priority); pa != pb {
+			return pa < pb
+		}
+		if (a.DaysUntilDue == nil) != (b.DaysUntilDue == nil) {
+			return a.DaysUntilDue != nil
+		}
+		return a.DaysUntilDue != nil && *a.DaysUntilDue < *b.DaysUntilDue
+	})
+	return risks, nil
+}
+
+// analysisOrders returns every order for a report, failing with
+// ErrTooManyResults beyond orderAnalysisLimit
+func (s *OrdersService) analysisOrders(ctx context.Context) ([]*Order, error) {
+	iterOpts := &IterOptions{PerPage: defaultIterPerPage, MaxItems: orderAnalysisLimit + 1}
+	orders, err := collect(s.Iter(ctx, &ListOrdersOptions{}, iterOpts), orderAnalysisLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+	return orders, nil
+}
+
+// supplierHistory counts each supplier's orders that were received in full,
+// and open orders already past due, and how many of them were late
+func (s *OrdersService) supplierHistory(orders []*Order, asOf time.Time) map[string]*supplierLateness {
+	history := map[string]*supplierLateness{}
+	for _, order := range orders {
+		if order.SupplierID == "" {
+			continue
+		}
+		due, err := dates.Parse(order.DueDate)
+		if err != nil {
+			continue
+		}
+
+		var late bool
+		if delivered, ok := orderDelivered(order); ok {
+			late = dates.DaysBetween(due, delivered) > 0
+		} else if s.isOpen(order) && dates.DaysBetween(due, asOf) > 0 {
+			late = true
+		} else {
+			continue
+		}
+
+		h := history[order.SupplierID]
+		if h == nil {
+			h = &supplierLateness{}
+			history[order.SupplierID] = h
+		}
+		h.Orders++
+		if late {
+			h.Late++
+		}
+	}
+	return history
+}
+
+// isOpen reports whether an order is still to be received: its status is
+// neither final nor received
+func (s *OrdersService) isOpen(order *Order) bool {
+	l := s.client.orderLifecycle
+	status, err := l.Current(order.Status)
+	if err != nil {
+		return false
+	}
+	return status != OrderStatusReceived && !l.States[status].Final
+}
+
+// orderDelivered returns the date an order was received in full, the latest
+// date any of its lines was, if every line has been
+func orderDelivered(order *Order) (time.Time, bool) {
+	if len(order.Items) == 0 {
+		return time.Time{}, false
+	}
+	var delivered time.Time
+	for _, item := range order.Items {
+		if item.ReceivedDate == "" {
+			return time.Time{}, false
+		}
+		t, err := dates.Parse(item.ReceivedDate)
+		if err != nil {
+			return time.Time{}, false
+		}
+		if t.After(delivered) {
+			delivered = t
+		}
+	}
+	return delivered, true
+}
+
+// priorityRank returns the urgency rank of an order priority
+func priorityRank(priority string) int {
+	if rank, ok := orderPriorityRank[strings.ToLower(priority)]; ok {
+		return rank
+	}
+	return orderPriorityRank["normal"]
+}
@@ -3,14 +3,31 @@ package firstresonance
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
+	"github.com/firstresonance/fr-mcp-server/pkg/dates"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// orderItemSchema is the JSON schema of an order item in tool parameters
+var orderItemSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"part_id":        map[string]interface{}{"type": "string", "description": "Part ordered"},
+		"quantity":       map[string]interface{}{"type": "number", "exclusiveMinimum": 0, "description": "Quantity ordered, in unit; may be fractional"},
+		"unit_price":     map[string]interface{}{"type": "number", "minimum": 0, "description": "Price per unit"},
+		"unit":           map[string]interface{}{"type": "string", "description": "Unit of measure of the quantity"},
+		"requested_date": map[string]interface{}{"type": "string", "description": "Date the line is wanted by (YYYY-MM-DD)"},
+	},
+	"required":             []string{"part_id", "quantity"},
+	"additionalProperties": false,
+}
+
 // GetOrder creates a tool to get details of a specific order.
 func GetOrder(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_order",
@@ -66,7 +83,9 @@ func ListOrders(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 			mcp.WithString("direction",
 				mcp.Description("Sort direction"),
 			),
-			WithPagination(),
+			WithFilter(orderFilterFields),
+			WithCursorPagination(),
+			WithFetchAll(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			status, err := OptionalParam[string](request, "status")
@@ -85,6 +104,14 @@ func ListOrders(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
+			fetchAll, err := OptionalParam[bool](request, "fetch_all")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			listFilter, err := OptionalFilterParam(request, orderFilterFields)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 
 			opts := &ListOrdersOptions{
 				Status:    status,
@@ -96,25 +123,49 @@ func ListOrders(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 				},
 			}
 
+			// Narrow the query by the filter's equality conditions
+			if listFilter != nil {
+				opts.Filter = listFilter.String()
+				eq := pushdownEqualities(listFilter)
+				if opts.Status == "" {
+					opts.Status = eq["status"]
+				}
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
 			}
-			orders, resp, err := client.Orders.List(ctx, opts)
+			if fetchAll {
+				all, err := collect(filterSeq(listFilter, client.Orders.Iter(ctx, opts, fetchAllIterOptions())), fetchAllLimit)
+				if errors.Is(err, ErrTooManyResults) {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				if err != nil {
+					return nil, fmt.Errorf("failed to list orders: %w", err)
+				}
+
+				r, err := json.Marshal(completePage(all))
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal response: %w", err)
+				}
+
+				return mcp.NewToolResultText(string(r)), nil
+			}
+			page, err := client.Orders.ListPage(ctx, opts, pagination.cursor)
+			if errors.Is(err, ErrInvalidCursor) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 			if err != nil {
 				return nil, fmt.Errorf("failed to list orders: %w", err)
 			}
-			defer func() { _ = resp.Body.Close() }()
 
-			if resp.StatusCode != http.StatusOK {
-				body, err := io.ReadAll(resp.Body)
-				if err != nil {
-					return nil, fmt.Errorf("failed to read response body: %w", err)
-				}
-				return mcp.NewToolResultError(fmt.Sprintf("failed to list orders: %s", string(body))), nil
+			page, err = filterPage(listFilter, page)
+			if err != nil {
+				return nil, err
 			}
 
-			r, err := json.Marshal(orders)
+			r, err := json.Marshal(page)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
@@ -131,9 +182,13 @@ func CreateOrder(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 				mcp.Required(),
 				mcp.Description("Customer ID"),
 			),
+			mcp.WithString("supplier_id",
+				mcp.Description("Supplier the order is placed with. A warning is returned for parts the supplier is not approved for"),
+			),
 			mcp.WithArray("items",
 				mcp.Required(),
-				mcp.Description("Order items"),
+				mcp.Description("Order items. Every part must exist, quantities must be positive, and every line's unit must convert to the unit its part's stock is counted in"),
+				mcp.Items(orderItemSchema),
 			),
 			mcp.WithString("priority",
 				mcp.Description("Order priority"),
@@ -148,7 +203,16 @@ func CreateOrder(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
-			items, err := requiredParam[[]interface{}](request, "items")
+			supplierID, err := OptionalParam[string](request, "supplier_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			rawItems, err := OptionalParam[[]interface{}](request, "items")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			items, err := ParseOrderItems(rawItems)
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
@@ -165,6 +229,7 @@ func CreateOrder(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 
 			order := &Order{
 				CustomerID: customerID,
+				SupplierID: supplierID,
 				Items:     items,
 				Priority:  priority,
 				DueDate:   dueDate,
@@ -174,7 +239,19 @@ func CreateOrder(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 			if err != nil {
 				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
 			}
+			if err := client.Orders.ValidateItems(ctx, items); errors.Is(err, ErrInvalidOrderItems) {
+				return mcp.NewToolResultError(err.Error()), nil
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to validate order items: %w", err)
+			}
+			// Ordering from a supplier that is not approved is allowed, but warned about
+			warnings, err := client.ApprovedVendors.CheckOrder(ctx, order)
+			if err != nil {
+				warnings = []string{fmt.Sprintf("could not check the approved vendor list: %v", err)}
+			}
 			createdOrder, resp, err := client.Orders.Create(ctx, order)
+			auditWarns, err := auditWarnings(err)
+			warnings = append(warnings, auditWarns...)
 			if err != nil {
 				return nil, fmt.Errorf("failed to create order: %w", err)
 			}
@@ -193,7 +270,7 @@ func CreateOrder(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return withWarnings(mcp.NewToolResultText(string(r)), warnings), nil
 		}
 }
 
@@ -206,7 +283,7 @@ func UpdateOrder(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 				mcp.Description("Order ID to update"),
 			),
 			mcp.WithString("status",
-				mcp.Description("New status"),
+				mcp.Description("New status; the order lifecycle must allow the move, see order_transitions"),
 			),
 			mcp.WithString("priority",
 				mcp.Description("New priority"),
@@ -214,6 +291,10 @@ func UpdateOrder(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 			mcp.WithString("due_date",
 				mcp.Description("New due date"),
 			),
+			mcp.WithArray("items",
+				mcp.Description("New order items, replacing all existing items. Validated as for create_order; lines already received must stay on their line with the same part and unit"),
+				mcp.Items(orderItemSchema),
+			),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			orderID, err := requiredParam[string](request, "order_id")
@@ -245,6 +326,17 @@ func UpdateOrder(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 				updateNeeded = true
 			}
 
+			if rawItems, ok, err := OptionalParamOK[[]interface{}](request, "items"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			} else if ok {
+				items, err := ParseOrderItems(rawItems)
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				update.Items = &items
+				updateNeeded = true
+			}
+
 			if !updateNeeded {
 				return mcp.NewToolResultError("No update parameters provided."), nil
 			}
@@ -253,7 +345,18 @@ func UpdateOrder(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 			if err != nil {
 				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
 			}
+			if update.Items != nil {
+				if err := client.Orders.ValidateItems(ctx, *update.Items); errors.Is(err, ErrInvalidOrderItems) {
+					return mcp.NewToolResultError(err.Error()), nil
+				} else if err != nil {
+					return nil, fmt.Errorf("failed to validate order items: %w", err)
+				}
+			}
 			updatedOrder, resp, err := client.Orders.Update(ctx, orderID, update)
+			warnings, err := auditWarnings(err)
+			if errors.Is(err, ErrInvalidTransition) || errors.Is(err, ErrInvalidOrderUpdate) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 			if err != nil {
 				return nil, fmt.Errorf("failed to update order: %w", err)
 			}
@@ -272,6 +375,290 @@ func UpdateOrder(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
+			return withWarnings(mcp.NewToolResultText(string(r)), warnings), nil
+		}
+} 
+
+// AddOrderItem creates a tool to add a line item to an order.
+func AddOrderItem(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("add_order_item",
+			mcp.WithDescription(t("TOOL_ADD_ORDER_ITEM_DESCRIPTION", "Add a line item to the end of an order")),
+			mcp.WithString("order_id",
+				mcp.Required(),
+				mcp.Description("Order ID"),
+			),
+			mcp.WithString("part_id",
+				mcp.Required(),
+				mcp.Description("Part to order; it must exist"),
+			),
+			mcp.WithNumber("quantity",
+				mcp.Required(),
+				mcp.Description("Quantity to order, in unit; may be fractional"),
+				mcp.Min(0),
+			),
+			mcp.WithNumber("unit_price",
+				mcp.Description("Price per unit"),
+				mcp.Min(0),
+			),
+			mcp.WithString("unit",
+				mcp.Description("Unit of measure of the quantity; must convert to the part's base unit, or match other lines of a part without one"),
+			),
+			mcp.WithString("requested_date",
+				mcp.Description("Date the line is wanted by (YYYY-MM-DD)"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			orderID, err := requiredParam[string](request, "order_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			partID, err := requiredParam[string](request, "part_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			quantity, err := requiredParam[float64](request, "quantity")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			unitPrice, err := OptionalParam[float64](request, "unit_price")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			unit, err := OptionalParam[string](request, "unit")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			requestedDate, err := OptionalParam[string](request, "requested_date")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			order, err := client.Orders.AddItem(ctx, orderID, OrderItem{
+				PartID:        partID,
+				Quantity:      quantity,
+				UnitPrice:     unitPrice,
+				Unit:          unit,
+				RequestedDate: requestedDate,
+			})
+			warnings, err := auditWarnings(err)
+			if errors.Is(err, ErrInvalidOrderItems) || errors.Is(err, ErrInvalidOrderUpdate) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to add order item: %w", err)
+			}
+
+			r, err := json.Marshal(order)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return withWarnings(mcp.NewToolResultText(string(r)), warnings), nil
+		}
+}
+
+// RemoveOrderItem creates a tool to remove a line item from an order.
+func RemoveOrderItem(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("remove_order_item",
+			mcp.WithDescription(t("TOOL_REMOVE_ORDER_ITEM_DESCRIPTION", "Remove a line item from an order. Later lines move up by one")),
+			mcp.WithString("order_id",
+				mcp.Required(),
+				mcp.Description("Order ID"),
+			),
+			mcp.WithNumber("line",
+				mcp.Required(),
+				mcp.Description("Line to remove, numbered from 1 in the order's items"),
+				mcp.Min(1),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			orderID, err := requiredParam[string](request, "order_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			line, err := requiredParam[float64](request, "line")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			order, err := client.Orders.RemoveItem(ctx, orderID, int(line))
+			warnings, err := auditWarnings(err)
+			if errors.Is(err, ErrInvalidOrderItems) || errors.Is(err, ErrInvalidOrderUpdate) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to remove order item: %w", err)
+			}
+
+			r, err := json.Marshal(order)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return withWarnings(mcp.NewToolResultText(string(r)), warnings), nil
+		}
+}
+
+// GetOrderTransitions creates a tool to list the status moves an order can make.
+func GetOrderTransitions(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("order_transitions",
+			mcp.WithDescription(t("TOOL_ORDER_TRANSITIONS_DESCRIPTION", "List the statuses an order can move to from its current status with update_order, and for each move that is not allowed yet, what blocks it")),
+			mcp.WithString("order_id",
+				mcp.Required(),
+				mcp.Description("Order ID"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			orderID, err := requiredParam[string](request, "order_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			transitions, err := client.Orders.Transitions(ctx, orderID)
+			if errors.Is(err, ErrInvalidTransition) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get order transitions: %w", err)
+			}
+
+			r, err := json.Marshal(transitions)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
+
+// ReceiveOrder creates a tool to receive an order's lines into inventory.
+func ReceiveOrder(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("receive_order",
+			mcp.WithDescription(t("TOOL_RECEIVE_ORDER_DESCRIPTION", "Receive goods against an order's lines: adds the quantities to inventory at the receiving location, marks lines partially or fully received, reports discrepancies with the quantities ordered, and moves the order to received once every line is received in full")),
+			mcp.WithString("order_id",
+				mcp.Required(),
+				mcp.Description("Order ID"),
+			),
+			mcp.WithArray("lines",
+				mcp.Required(),
+				mcp.Description("Quantities received, by line numbered from 1 in the order's items"),
+				mcp.Items(map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"line":     map[string]interface{}{"type": "integer", "minimum": 1, "description": "Order line"},
+						"quantity": map[string]interface{}{"type": "number", "exclusiveMinimum": 0, "description": "Quantity received, in the line's unit; may be fractional"},
+					},
+					"required":             []string{"line", "quantity"},
+					"additionalProperties": false,
+				}),
+			),
+			mcp.WithString("location",
+				mcp.Description(fmt.Sprintf("Location received stock is put (default %s)", DefaultReceivingLocation)),
+			),
+			mcp.WithString("note",
+				mcp.Description("Note recorded with the receipt, such as a packing slip number"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			orderID, err := requiredParam[string](request, "order_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			rawLines, err := OptionalParam[[]interface{}](request, "lines")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			receipt := &OrderReceipt{}
+			for i, raw := range rawLines {
+				m, _ := raw.(map[string]interface{})
+				line, lineOK := m["line"].(float64)
+				quantity, quantityOK := m["quantity"].(float64)
+				if !lineOK || !quantityOK || line != float64(int(line)) {
+					return mcp.NewToolResultError(fmt.Sprintf("lines[%d] must have a whole number line and a quantity", i)), nil
+				}
+				receipt.Lines = append(receipt.Lines, OrderReceiptLine{Line: int(line), Quantity: quantity})
+			}
+			if receipt.Location, err = OptionalParam[string](request, "location"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if receipt.Note, err = OptionalParam[string](request, "note"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			result, err := client.Orders.Receive(ctx, orderID, receipt)
+			warnings, err := auditWarnings(err)
+			if result == nil {
+				if errors.Is(err, ErrInvalidReceipt) || errors.Is(err, ErrInvalidTransfer) {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				return nil, fmt.Errorf("failed to receive order: %w", err)
+			}
+
+			r, merr := json.Marshal(result)
+			if merr != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", merr)
+			}
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("%s: %s", err, string(r))), nil
+			}
+
+			return withWarnings(mcp.NewToolResultText(string(r)), warnings), nil
+		}
+}
+
+// OrderRiskReport creates a tool to report overdue, soon due and at-risk open orders.
+func OrderRiskReport(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("order_risk_report",
+			mcp.WithDescription(t("TOOL_ORDER_RISK_REPORT_DESCRIPTION", "Report open orders that are overdue, due within the next 7 days, or at risk because their supplier is often late or they order parts below minimum stock, most urgent first with the reasons for each")),
+			mcp.WithString("as_of",
+				mcp.Description("Date to report as of (default today)"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			asOfParam, err := OptionalParam[string](request, "as_of")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			asOf := time.Now().UTC()
+			if asOfParam != "" {
+				if asOf, err = dates.Parse(asOfParam); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid as_of: %s", err)), nil
+				}
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			risks, err := client.Orders.RiskReport(ctx, asOf)
+			if errors.Is(err, ErrTooManyResults) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to build order risk report: %w", err)
+			}
+
+			r, err := json.Marshal(risks)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
 			return mcp.NewToolResultText(string(r)), nil
 		}
-} 
\ No newline at end of file
+}
@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"strings"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/audit"
 )
 
 // Get retrieves an order by its ID
@@ -17,6 +20,7 @@ func (s *OrdersService) Get(ctx context.Context, id string) (*Order, error) {
 			order(id: $id) {
 				id
 				customer_id
+				supplier_id
 				items
 				priority
 				due_date
@@ -105,6 +109,7 @@ func (s *OrdersService) List(ctx context.Context, opts *ListOrdersOptions) ([]*O
 			orders(status: $status, sort: $sort, direction: $direction, page: $page, perPage: $perPage) {
 				id
 				customer_id
+				supplier_id
 				items
 				priority
 				due_date
@@ -195,14 +200,89 @@ func (s *OrdersService) List(ctx context.Context, opts *ListOrdersOptions) ([]*O
 	return result.Data.Orders, nil
 }
 
-// Create creates a new order
+// Iter returns an iterator over every order matching opts, fetching pages as needed
+func (s *OrdersService) Iter(ctx context.Context, opts *ListOrdersOptions, iterOpts *IterOptions) iter.Seq2[*Order, error] {
+	return paginate(ctx, func(ctx context.Context, page, perPage int) ([]*Order, error) {
+		pageOpts := ListOrdersOptions{}
+		if opts != nil {
+			pageOpts = *opts
+		}
+		pageOpts.Page, pageOpts.PerPage = page, perPage
+		return s.List(ctx, &pageOpts)
+	}, iterOpts)
+}
+
+// ListPage retrieves the page of orders that the cursor points to, or the
+// page in opts if the cursor is empty
+func (s *OrdersService) ListPage(ctx context.Context, opts *ListOrdersOptions, cursor string) (*Page[*Order], error) {
+	if opts == nil {
+		opts = &ListOrdersOptions{}
+	}
+
+	// GraphQL query to fetch a page of the orders connection
+	query := `
+		query OrdersConnection($status: String, $sort: String, $direction: String, $first: Int, $after: String) {
+			ordersConnection(status: $status, sort: $sort, direction: $direction, first: $first, after: $after) {
+				totalCount
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+				nodes {
+					id
+					customer_id
+					supplier_id
+					items
+					priority
+					due_date
+					status
+				}
+			}
+		}
+	`
+
+	// Create filter variables for the query
+	filters := map[string]interface{}{}
+	if opts.Status != "" {
+		filters["status"] = opts.Status
+	}
+	if opts.Sort != "" {
+		filters["sort"] = opts.Sort
+	}
+	if opts.Direction != "" {
+		filters["direction"] = opts.Direction
+	}
+
+	return listPage(ctx, cursor, opts.ListOptions, filters,
+		func(ctx context.Context, first int, after string) (*connection[*Order], error) {
+			return fetchConnection[*Order](ctx, s.client, "ordersConnection", query, filters, first, after)
+		},
+		func(ctx context.Context, page, perPage int) ([]*Order, error) {
+			pageOpts := *opts
+			pageOpts.Page, pageOpts.PerPage = page, perPage
+			return s.List(ctx, &pageOpts)
+		})
+}
+
+// Create creates a new order and records it in the audit log
 func (s *OrdersService) Create(ctx context.Context, order *Order) (*Order, error) {
+	created, err := s.create(ctx, order)
+	entry := &audit.Entry{Entity: auditEntityOrder, Action: audit.ActionCreate, After: created}
+	if created != nil {
+		entry.EntityID = created.ID
+	}
+	return created, s.client.Audit.record(ctx, entry, err)
+}
+
+// create sends the createOrder mutation
+func (s *OrdersService) create(ctx context.Context, order *Order) (*Order, error) {
 	// GraphQL mutation to create a new order
 	query := `
 		mutation CreateOrder($input: CreateOrderInput!) {
 			createOrder(input: $input) {
 				id
 				customer_id
+				supplier_id
 				items
 				priority
 				due_date
@@ -215,6 +295,7 @@ func (s *OrdersService) Create(ctx context.Context, order *Order) (*Order, error
 	variables := map[string]interface{}{
 		"input": map[string]interface{}{
 			"customer_id": order.CustomerID,
+			"supplier_id": order.SupplierID,
 			"items":       order.Items,
 			"priority":    order.Priority,
 			"due_date":    order.DueDate,
@@ -284,14 +365,39 @@ func (s *OrdersService) Create(ctx context.Context, order *Order) (*Order, error
 	return result.Data.CreateOrder, nil
 }
 
-// Update updates an existing order
+// Update updates an existing order and records the change in the audit log.
+// A status change must be allowed by the order lifecycle, and any other
+// change by the order's status. Items replacing lines already received, for
+// the same part and unit, keep their receipts.
 func (s *OrdersService) Update(ctx context.Context, id string, update *OrderUpdateRequest) (*Order, error) {
+	order, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if update.Items != nil {
+		items := keepReceipts(order.Items, *update.Items)
+		replaced := *update
+		replaced.Items = &items
+		update = &replaced
+	}
+	if err := s.checkOrderUpdate(order, update); err != nil {
+		return nil, err
+	}
+	before := auditSnapshot(ctx, s.client, s.Get, id)
+	updated, err := s.update(ctx, id, update)
+	entry := &audit.Entry{Entity: auditEntityOrder, EntityID: id, Action: audit.ActionUpdate, Before: before, After: updated}
+	return updated, s.client.Audit.record(ctx, entry, err)
+}
+
+// update sends the updateOrder mutation
+func (s *OrdersService) update(ctx context.Context, id string, update *OrderUpdateRequest) (*Order, error) {
 	// GraphQL mutation to update an existing order
 	query := `
 		mutation UpdateOrder($id: ID!, $input: UpdateOrderInput!) {
 			updateOrder(id: $id, input: $input) {
 				id
 				customer_id
+				supplier_id
 				items
 				priority
 				due_date
@@ -307,6 +413,7 @@ func (s *OrdersService) Update(ctx context.Context, id string, update *OrderUpda
 			"status":   update.Status,
 			"priority": update.Priority,
 			"due_date": update.DueDate,
+			"items":    update.Items,
 		},
 	}
 
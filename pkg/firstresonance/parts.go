@@ -3,6 +3,7 @@ package firstresonance
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -55,6 +56,12 @@ func GetPart(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, han
 		}
 }
 
+// Similarity thresholds for duplicate parts
+const (
+	defaultDuplicateConfidence    = 0.8
+	createPartDuplicateConfidence = 0.85
+)
+
 // ListParts creates a tool to list and filter parts.
 func ListParts(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("list_parts",
@@ -65,7 +72,9 @@ func ListParts(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, h
 			mcp.WithString("type",
 				mcp.Description("Filter by type"),
 			),
-			WithPagination(),
+			WithFilter(partFilterFields),
+			WithCursorPagination(),
+			WithFetchAll(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			status, err := OptionalParam[string](request, "status")
@@ -80,6 +89,14 @@ func ListParts(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, h
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
+			fetchAll, err := OptionalParam[bool](request, "fetch_all")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			listFilter, err := OptionalFilterParam(request, partFilterFields)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 
 			opts := &ListPartsOptions{
 				Status: status,
@@ -90,25 +107,52 @@ func ListParts(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, h
 				},
 			}
 
+			// Narrow the query by the filter's equality conditions
+			if listFilter != nil {
+				opts.Filter = listFilter.String()
+				eq := pushdownEqualities(listFilter)
+				if opts.Status == "" {
+					opts.Status = eq["status"]
+				}
+				if opts.Type == "" {
+					opts.Type = eq["type"]
+				}
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
 			}
-			parts, resp, err := client.Parts.List(ctx, opts)
+			if fetchAll {
+				all, err := collect(filterSeq(listFilter, client.Parts.Iter(ctx, opts, fetchAllIterOptions())), fetchAllLimit)
+				if errors.Is(err, ErrTooManyResults) {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				if err != nil {
+					return nil, fmt.Errorf("failed to list parts: %w", err)
+				}
+
+				r, err := json.Marshal(completePage(all))
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal response: %w", err)
+				}
+
+				return mcp.NewToolResultText(string(r)), nil
+			}
+			page, err := client.Parts.ListPage(ctx, opts, pagination.cursor)
+			if errors.Is(err, ErrInvalidCursor) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 			if err != nil {
 				return nil, fmt.Errorf("failed to list parts: %w", err)
 			}
-			defer func() { _ = resp.Body.Close() }()
 
-			if resp.StatusCode != http.StatusOK {
-				body, err := io.ReadAll(resp.Body)
-				if err != nil {
-					return nil, fmt.Errorf("failed to read response body: %w", err)
-				}
-				return mcp.NewToolResultError(fmt.Sprintf("failed to list parts: %s", string(body))), nil
+			page, err = filterPage(listFilter, page)
+			if err != nil {
+				return nil, err
 			}
 
-			r, err := json.Marshal(parts)
+			r, err := json.Marshal(page)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
@@ -165,7 +209,25 @@ func CreatePart(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 			if err != nil {
 				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
 			}
+
+			// Creating a likely duplicate is allowed, but warned about, as is a scan that could not run
+			var warnings []string
+			similar, err := client.Parts.SimilarTo(ctx, part, createPartDuplicateConfidence)
+			switch {
+			case errors.Is(err, ErrTooManyResults):
+				warnings = append(warnings, fmt.Sprintf("duplicates were not checked: there are more than %d parts", fetchAllLimit))
+			case err != nil:
+				warnings = append(warnings, fmt.Sprintf("duplicates were not checked: %v", err))
+			case len(similar) > 0:
+				r, err := json.Marshal(similar)
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal similar parts: %w", err)
+				}
+				warnings = append(warnings, fmt.Sprintf("existing parts look like duplicates of this one: %s", string(r)))
+			}
 			createdPart, resp, err := client.Parts.Create(ctx, part)
+			auditWarns, err := auditWarnings(err)
+			warnings = append(warnings, auditWarns...)
 			if err != nil {
 				return nil, fmt.Errorf("failed to create part: %w", err)
 			}
@@ -184,7 +246,7 @@ func CreatePart(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return withWarnings(mcp.NewToolResultText(string(r)), warnings), nil
 		}
 }
 
@@ -255,6 +317,7 @@ func UpdatePart(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
 			}
 			updatedPart, resp, err := client.Parts.Update(ctx, partID, update)
+			warnings, err := auditWarnings(err)
 			if err != nil {
 				return nil, fmt.Errorf("failed to update part: %w", err)
 			}
@@ -273,6 +336,63 @@ func UpdatePart(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
+			return withWarnings(mcp.NewToolResultText(string(r)), warnings), nil
+		}
+} 
+
+// FindDuplicateParts creates a tool to find parts that are likely duplicates of each other.
+func FindDuplicateParts(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("find_duplicate_parts",
+			mcp.WithDescription(t("TOOL_FIND_DUPLICATE_PARTS_DESCRIPTION", "Find groups of parts that are likely duplicates, comparing names and descriptions after normalizing units, abbreviations and casing, as merge candidates")),
+			mcp.WithNumber("min_confidence",
+				mcp.Description(fmt.Sprintf("Minimum similarity, from 0 to 1, for two parts to be grouped (default %g)", defaultDuplicateConfidence)),
+				mcp.Min(0),
+				mcp.Max(1),
+			),
+			mcp.WithString("status",
+				mcp.Description("Only compare parts with this status"),
+			),
+			mcp.WithString("type",
+				mcp.Description("Only compare parts of this type"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			minConfidence, ok, err := OptionalParamOK[float64](request, "min_confidence")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if !ok {
+				minConfidence = defaultDuplicateConfidence
+			}
+			if minConfidence < 0 || minConfidence > 1 {
+				return mcp.NewToolResultError("min_confidence must be between 0 and 1"), nil
+			}
+			status, err := OptionalParam[string](request, "status")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			partType, err := OptionalParam[string](request, "type")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			clusters, err := client.Parts.FindDuplicates(ctx, &ListPartsOptions{Status: status, Type: partType}, minConfidence)
+			if errors.Is(err, ErrTooManyResults) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to find duplicate parts: %w", err)
+			}
+
+			r, err := json.Marshal(clusters)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
 			return mcp.NewToolResultText(string(r)), nil
 		}
-} 
\ No newline at end of file
+}
@@ -3,12 +3,19 @@ package firstresonance
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"strings"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/audit"
 )
 
+// ErrPartNotFound is returned when a part does not exist
+var ErrPartNotFound = errors.New("part not found")
+
 // Get retrieves a part by its ID
 func (s *PartsService) Get(ctx context.Context, id string) (*Part, error) {
 	// GraphQL query to fetch a part by ID
@@ -90,7 +97,7 @@ func (s *PartsService) Get(ctx context.Context, id string) (*Part, error) {
 
 	// Check if the part was found
 	if result.Data.Part == nil {
-		return nil, fmt.Errorf("part not found")
+		return nil, ErrPartNotFound
 	}
 
 	return result.Data.Part, nil
@@ -190,8 +197,99 @@ func (s *PartsService) List(ctx context.Context, opts *ListPartsOptions) ([]*Par
 	return result.Data.Parts, nil
 }
 
-// Create creates a new part
+// Iter returns an iterator over every part matching opts, fetching pages as needed
+func (s *PartsService) Iter(ctx context.Context, opts *ListPartsOptions, iterOpts *IterOptions) iter.Seq2[*Part, error] {
+	return paginate(ctx, func(ctx context.Context, page, perPage int) ([]*Part, error) {
+		pageOpts := ListPartsOptions{}
+		if opts != nil {
+			pageOpts = *opts
+		}
+		pageOpts.Page, pageOpts.PerPage = page, perPage
+		return s.List(ctx, &pageOpts)
+	}, iterOpts)
+}
+
+// names returns the names of the parts with the given IDs, listing parts
+// until every one is found rather than getting each. IDs of parts that do
+// not exist are left out.
+func (s *PartsService) names(ctx context.Context, ids map[string]bool) (map[string]string, error) {
+	names := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+	for part, err := range s.Iter(ctx, nil, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("error listing parts: %w", err)
+		}
+		if ids[part.ID] {
+			names[part.ID] = part.Name
+			if len(names) == len(ids) {
+				break
+			}
+		}
+	}
+	return names, nil
+}
+
+// ListPage retrieves the page of parts that the cursor points to, or the
+// page in opts if the cursor is empty
+func (s *PartsService) ListPage(ctx context.Context, opts *ListPartsOptions, cursor string) (*Page[*Part], error) {
+	if opts == nil {
+		opts = &ListPartsOptions{}
+	}
+
+	// GraphQL query to fetch a page of the parts connection
+	query := `
+		query PartsConnection($status: String, $type: String, $first: Int, $after: String) {
+			partsConnection(status: $status, type: $type, first: $first, after: $after) {
+				totalCount
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+				nodes {
+					id
+					name
+					description
+					type
+					status
+				}
+			}
+		}
+	`
+
+	// Create filter variables for the query
+	filters := map[string]interface{}{}
+	if opts.Status != "" {
+		filters["status"] = opts.Status
+	}
+	if opts.Type != "" {
+		filters["type"] = opts.Type
+	}
+
+	return listPage(ctx, cursor, opts.ListOptions, filters,
+		func(ctx context.Context, first int, after string) (*connection[*Part], error) {
+			return fetchConnection[*Part](ctx, s.client, "partsConnection", query, filters, first, after)
+		},
+		func(ctx context.Context, page, perPage int) ([]*Part, error) {
+			pageOpts := *opts
+			pageOpts.Page, pageOpts.PerPage = page, perPage
+			return s.List(ctx, &pageOpts)
+		})
+}
+
+// Create creates a new part and records it in the audit log
 func (s *PartsService) Create(ctx context.Context, part *Part) (*Part, error) {
+	created, err := s.create(ctx, part)
+	entry := &audit.Entry{Entity: auditEntityPart, Action: audit.ActionCreate, After: created}
+	if created != nil {
+		entry.EntityID = created.ID
+	}
+	return created, s.client.Audit.record(ctx, entry, err)
+}
+
+// create sends the createPart mutation
+func (s *PartsService) create(ctx context.Context, part *Part) (*Part, error) {
 	// GraphQL mutation to create a new part
 	query := `
 		mutation CreatePart($input: CreatePartInput!) {
@@ -277,8 +375,16 @@ func (s *PartsService) Create(ctx context.Context, part *Part) (*Part, error) {
 	return result.Data.CreatePart, nil
 }
 
-// Update updates an existing part
+// Update updates an existing part and records the change in the audit log
 func (s *PartsService) Update(ctx context.Context, id string, update *PartUpdateRequest) (*Part, error) {
+	before := auditSnapshot(ctx, s.client, s.Get, id)
+	updated, err := s.update(ctx, id, update)
+	entry := &audit.Entry{Entity: auditEntityPart, EntityID: id, Action: audit.ActionUpdate, Before: before, After: updated}
+	return updated, s.client.Audit.record(ctx, entry, err)
+}
+
+// update sends the updatePart mutation
+func (s *PartsService) update(ctx context.Context, id string, update *PartUpdateRequest) (*Part, error) {
 	// GraphQL mutation to update an existing part
 	query := `
 		mutation UpdatePart($id: ID!, $input: UpdatePartInput!) {
@@ -0,0 +1,38 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// LowStockReport creates a tool to report parts whose stock is below their reorder point.
+func LowStockReport(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("low_stock_report",
+			mcp.WithDescription(t("TOOL_LOW_STOCK_REPORT_DESCRIPTION", "Report parts whose on-hand quantity across all locations is below their minimum stock, with a suggested reorder quantity and the supplier last ordered from")),
+		),
+		func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			items, err := client.ReorderPoints.LowStock(ctx)
+			if errors.Is(err, ErrReorderPointsDisabled) || errors.Is(err, ErrTooManyResults) || isUnitError(err) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to build low stock report: %w", err)
+			}
+
+			r, err := json.Marshal(items)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
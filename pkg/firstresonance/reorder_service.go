@@ -0,0 +1,170 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+)
+
+// ErrReorderPointsDisabled is returned when reorder points are used but no file is configured
+var ErrReorderPointsDisabled = errors.New("reorder points are not configured")
+
+// lowStockOrderScanLimit caps how many orders are scanned for a part's last supplier
+const lowStockOrderScanLimit = 1000
+
+// List reads the reorder points from the configured file
+func (s *ReorderPointsService) List(_ context.Context) ([]*ReorderPoint, error) {
+	if s.client.reorderPointsPath == "" {
+		return nil, ErrReorderPointsDisabled
+	}
+
+	data, err := os.ReadFile(s.client.reorderPointsPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading reorder points: %w", err)
+	}
+
+	var points []*ReorderPoint
+	if err := json.Unmarshal(data, &points); err != nil {
+		return nil, fmt.Errorf("error parsing reorder points: %w", err)
+	}
+	for i, p := range points {
+		if p.PartID == "" {
+			return nil, fmt.Errorf("reorder point %d has no part_id", i)
+		}
+		if p.MinStock < 0 || p.ReorderQuantity < 0 {
+			return nil, fmt.Errorf("reorder point for part %s has a negative threshold", p.PartID)
+		}
+	}
+
+	return points, nil
+}
+
+// LowStock totals on-hand inventory for every part with a reorder point
+// across all locations and returns the parts below their minimum stock, with
+// a suggested reorder quantity and the supplier last ordered from. Reorder
+// points given in another unit are converted to the part's base unit.
+func (s *ReorderPointsService) LowStock(ctx context.Context) ([]*LowStockItem, error) {
+	points, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Total on-hand quantity per part and location
+	items, err := s.client.Inventory.analysisInventory(ctx, &ListInventoryItemsOptions{})
+	if err != nil {
+		return nil, err
+	}
+	onHand := map[string]map[string]float64{}
+	for _, item := range items {
+		if item.PartID == "" {
+			continue
+		}
+		if onHand[item.PartID] == nil {
+			onHand[item.PartID] = map[string]float64{}
+		}
+		onHand[item.PartID][item.Location] = s.client.Units.round(item.PartID, onHand[item.PartID][item.Location]+item.Quantity)
+	}
+
+	var low []*LowStockItem
+	for _, p := range points {
+		// Stock is counted in the part's base unit, so compare in it
+		minStock, unit, err := s.client.Units.StockQuantity(p.PartID, p.MinStock, p.Unit)
+		if err != nil {
+			return nil, fmt.Errorf("reorder point for part %s: %w", p.PartID, err)
+		}
+		reorderQuantity, _, err := s.client.Units.StockQuantity(p.PartID, p.ReorderQuantity, p.Unit)
+		if err != nil {
+			return nil, fmt.Errorf("reorder point for part %s: %w", p.PartID, err)
+		}
+
+		item := &LowStockItem{
+			PartID:          p.PartID,
+			Locations:       onHand[p.PartID],
+			MinStock:        minStock,
+			ReorderQuantity: reorderQuantity,
+			LastSupplierID:  p.SupplierID,
+			Unit:            unit,
+		}
+		for _, q := range item.Locations {
+			item.OnHand = s.client.Units.round(p.PartID, item.OnHand+q)
+		}
+		if item.OnHand >= minStock {
+			continue
+		}
+		item.Shortfall = s.client.Units.round(p.PartID, minStock-item.OnHand)
+		item.SuggestedQuantity = s.client.Units.round(p.PartID, suggestedReorderQuantity(item.Shortfall, reorderQuantity))
+		low = append(low, item)
+	}
+
+	if err := s.fillLastSuppliers(ctx, low); err != nil {
+		return nil, err
+	}
+	for _, item := range low {
+		if part, err := s.client.Parts.Get(ctx, item.PartID); err == nil {
+			item.PartName = part.Name
+		}
+	}
+
+	// Largest shortfall first
+	sort.SliceStable(low, func(i, j int) bool {
+		return low[i].Shortfall > low[j].Shortfall
+	})
+
+	return low, nil
+}
+
+// fillLastSuppliers sets the supplier of the most recent order containing
+// each part, keeping the configured supplier when no such order is found.
+func (s *ReorderPointsService) fillLastSuppliers(ctx context.Context, items []*LowStockItem) error {
+	pending := map[string]*LowStockItem{}
+	for _, item := range items {
+		pending[item.PartID] = item
+	}
+
+	opts := &ListOrdersOptions{Sort: "due_date", Direction: "desc"}
+	for order, err := range s.client.Orders.Iter(ctx, opts, &IterOptions{MaxItems: lowStockOrderScanLimit}) {
+		if err != nil {
+			return fmt.Errorf("error listing orders: %w", err)
+		}
+		if len(pending) == 0 {
+			break
+		}
+		if order.SupplierID == "" {
+			continue
+		}
+		for _, partID := range orderPartIDs(order) {
+			if item, ok := pending[partID]; ok {
+				item.LastSupplierID = order.SupplierID
+				delete(pending, partID)
+			}
+		}
+	}
+
+	return nil
+}
+
+// suggestedReorderQuantity returns the smallest multiple of the reorder
+// quantity that covers the shortfall, or the shortfall itself if no reorder
+// quantity is set.
+func suggestedReorderQuantity(shortfall, reorderQuantity float64) float64 {
+	if reorderQuantity <= 0 {
+		return shortfall
+	}
+	// Allow for floating point error in a shortfall that is an exact multiple
+	return math.Ceil(shortfall/reorderQuantity-1e-9) * reorderQuantity
+}
+
+// orderPartIDs returns the IDs of the parts on an order's items
+func orderPartIDs(order *Order) []string {
+	var ids []string
+	for _, item := range order.Items {
+		if item.PartID != "" {
+			ids = append(ids, item.PartID)
+		}
+	}
+	return ids
+}
@@ -3,14 +3,43 @@ package firstresonance
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"slices"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// searchTypes are the entity types that can be searched
+var searchTypes = []string{SearchTypePart, SearchTypeOrder, SearchTypeSupplier, SearchTypeInventoryItem}
+
+// Search creates a tool to search across parts, orders, suppliers and inventory items.
+func Search(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("search",
+			mcp.WithDescription(t("TOOL_SEARCH_DESCRIPTION", "Search across parts, orders, suppliers and inventory items, returning type-tagged results in rank order")),
+			mcp.WithString("query",
+				mcp.Required(),
+				mcp.Description("Search query"),
+			),
+			mcp.WithArray("types",
+				mcp.Description("Entity types to search; all types if omitted"),
+				mcp.Items(map[string]interface{}{
+					"type": "string",
+					"enum": searchTypes,
+				}),
+			),
+			mcp.WithString("sort",
+				mcp.Description("Sort field"),
+			),
+			mcp.WithString("order",
+				mcp.Description("Sort order"),
+			),
+			WithPagination(),
+		),
+		searchHandler(getClient, nil)
+}
+
 // SearchParts creates a tool to search for parts across First Resonance.
 func SearchParts(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("search_parts",
@@ -27,59 +56,7 @@ func SearchParts(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool,
 			),
 			WithPagination(),
 		),
-		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			query, err := requiredParam[string](request, "query")
-			if err != nil {
-				return mcp.NewToolResultError(err.Error()), nil
-			}
-			sort, err := OptionalParam[string](request, "sort")
-			if err != nil {
-				return mcp.NewToolResultError(err.Error()), nil
-			}
-			order, err := OptionalParam[string](request, "order")
-			if err != nil {
-				return mcp.NewToolResultError(err.Error()), nil
-			}
-			pagination, err := OptionalPaginationParams(request)
-			if err != nil {
-				return mcp.NewToolResultError(err.Error()), nil
-			}
-
-			opts := &SearchOptions{
-				Query: query,
-				Sort:  sort,
-				Order: order,
-				ListOptions: ListOptions{
-					Page:    pagination.page,
-					PerPage: pagination.perPage,
-				},
-			}
-
-			client, err := getClient(ctx)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
-			}
-			result, resp, err := client.Search.Parts(ctx, opts)
-			if err != nil {
-				return nil, fmt.Errorf("failed to search parts: %w", err)
-			}
-			defer func() { _ = resp.Body.Close() }()
-
-			if resp.StatusCode != http.StatusOK {
-				body, err := io.ReadAll(resp.Body)
-				if err != nil {
-					return nil, fmt.Errorf("failed to read response body: %w", err)
-				}
-				return mcp.NewToolResultError(fmt.Sprintf("failed to search parts: %s", string(body))), nil
-			}
-
-			r, err := json.Marshal(result)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal response: %w", err)
-			}
-
-			return mcp.NewToolResultText(string(r)), nil
-		}
+		searchHandler(getClient, []string{SearchTypePart})
 }
 
 // SearchOrders creates a tool to search for orders.
@@ -98,57 +75,168 @@ func SearchOrders(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool
 			),
 			WithPagination(),
 		),
+		searchHandler(getClient, []string{SearchTypeOrder})
+}
+
+// SearchSuppliers creates a tool to search for suppliers.
+func SearchSuppliers(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("search_suppliers",
+			mcp.WithDescription(t("TOOL_SEARCH_SUPPLIERS_DESCRIPTION", "Search for suppliers")),
+			mcp.WithString("query",
+				mcp.Required(),
+				mcp.Description("Search query"),
+			),
+			mcp.WithString("sort",
+				mcp.Description("Sort field"),
+			),
+			mcp.WithString("order",
+				mcp.Description("Sort order"),
+			),
+			WithPagination(),
+		),
+		searchHandler(getClient, []string{SearchTypeSupplier})
+}
+
+// SearchInventory creates a tool to search for inventory items.
+func SearchInventory(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("search_inventory",
+			mcp.WithDescription(t("TOOL_SEARCH_INVENTORY_DESCRIPTION", "Search for inventory items")),
+			mcp.WithString("query",
+				mcp.Required(),
+				mcp.Description("Search query"),
+			),
+			mcp.WithString("sort",
+				mcp.Description("Sort field"),
+			),
+			mcp.WithString("order",
+				mcp.Description("Sort order"),
+			),
+			WithPagination(),
+		),
+		searchHandler(getClient, []string{SearchTypeInventoryItem})
+}
+
+// localSearchTypes are the entity types in the local search index
+var localSearchTypes = []string{SearchTypePart, SearchTypeOrder, SearchTypeSupplier}
+
+// SearchLocal creates a tool to search the local full-text index.
+func SearchLocal(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("search_local",
+			mcp.WithDescription(t("TOOL_SEARCH_LOCAL_DESCRIPTION", "Search the local index of parts, suppliers and orders, tolerating typos and partial words, best matches first")),
+			mcp.WithString("query",
+				mcp.Required(),
+				mcp.Description("Search query"),
+			),
+			mcp.WithArray("types",
+				mcp.Description("Entity types to search; all types if omitted"),
+				mcp.Items(map[string]interface{}{
+					"type": "string",
+					"enum": localSearchTypes,
+				}),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Maximum number of results (default 20)"),
+				mcp.Min(1),
+			),
+		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			query, err := requiredParam[string](request, "query")
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
-			sort, err := OptionalParam[string](request, "sort")
+			entityTypes, err := OptionalStringArrayParam(request, "types")
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
-			order, err := OptionalParam[string](request, "order")
-			if err != nil {
-				return mcp.NewToolResultError(err.Error()), nil
+			for _, typ := range entityTypes {
+				if !slices.Contains(localSearchTypes, typ) {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid type %q: must be one of %v", typ, localSearchTypes)), nil
+				}
 			}
-			pagination, err := OptionalPaginationParams(request)
+			limit, err := OptionalIntParamWithDefault(request, "limit", 20)
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
-			opts := &SearchOptions{
-				Query: query,
-				Sort:  sort,
-				Order: order,
-				ListOptions: ListOptions{
-					Page:    pagination.page,
-					PerPage: pagination.perPage,
-				},
-			}
-
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
 			}
-			result, resp, err := client.Search.Orders(ctx, opts)
-			if err != nil {
-				return nil, fmt.Errorf("failed to search orders: %w", err)
+			results, err := client.SearchIndex.Search(ctx, query, entityTypes, limit)
+			if errors.Is(err, ErrSearchIndexDisabled) {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
-			defer func() { _ = resp.Body.Close() }()
-
-			if resp.StatusCode != http.StatusOK {
-				body, err := io.ReadAll(resp.Body)
-				if err != nil {
-					return nil, fmt.Errorf("failed to read response body: %w", err)
-				}
-				return mcp.NewToolResultError(fmt.Sprintf("failed to search orders: %s", string(body))), nil
+			if err != nil {
+				return nil, fmt.Errorf("failed to search local index: %w", err)
 			}
 
-			r, err := json.Marshal(result)
+			r, err := json.Marshal(results)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
 			return mcp.NewToolResultText(string(r)), nil
 		}
-} 
\ No newline at end of file
+}
+
+// searchHandler returns a handler that searches the given entity types, or
+// the types in the "types" parameter if none are given.
+func searchHandler(getClient GetClientFn, types []string) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		query, err := requiredParam[string](request, "query")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		entityTypes := types
+		if entityTypes == nil {
+			entityTypes, err = OptionalStringArrayParam(request, "types")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			for _, typ := range entityTypes {
+				if !slices.Contains(searchTypes, typ) {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid type %q: must be one of %v", typ, searchTypes)), nil
+				}
+			}
+		}
+		sort, err := OptionalParam[string](request, "sort")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		order, err := OptionalParam[string](request, "order")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		pagination, err := OptionalPaginationParams(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		opts := &SearchOptions{
+			Query: query,
+			Types: entityTypes,
+			Sort:  sort,
+			Order: order,
+			ListOptions: ListOptions{
+				Page:    pagination.page,
+				PerPage: pagination.perPage,
+			},
+		}
+
+		client, err := getClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+		}
+		results, err := client.Search.Search(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search: %w", err)
+		}
+
+		r, err := json.Marshal(results)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(r)), nil
+	}
+}
@@ -0,0 +1,122 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/index"
+)
+
+// ErrSearchIndexDisabled is returned when the local index is used but not enabled
+var ErrSearchIndexDisabled = errors.New("local search index is not enabled")
+
+// Enabled reports whether the local index is enabled
+func (s *SearchIndexService) Enabled() bool {
+	return s.client.searchIndex != nil
+}
+
+// Search searches the local index, returning the best matches first
+func (s *SearchIndexService) Search(_ context.Context, query string, types []string, limit int) ([]*SearchResult, error) {
+	if !s.Enabled() {
+		return nil, ErrSearchIndexDisabled
+	}
+
+	results := []*SearchResult{}
+	for i, r := range s.client.searchIndex.Search(query, &index.SearchOptions{Types: types, Limit: limit}) {
+		results = append(results, &SearchResult{
+			Type:  r.Document.Type,
+			Rank:  i + 1,
+			Score: r.Score,
+			Item:  r.Document.Item,
+		})
+	}
+	return results, nil
+}
+
+// Refresh rebuilds the local index from every part, supplier and order and
+// saves it to disk. The previous contents are kept if any list fails.
+func (s *SearchIndexService) Refresh(ctx context.Context) error {
+	if !s.Enabled() {
+		return ErrSearchIndexDisabled
+	}
+
+	var docs []*index.Document
+	for part, err := range s.client.Parts.Iter(ctx, nil, nil) {
+		if err != nil {
+			return fmt.Errorf("error listing parts: %w", err)
+		}
+		doc, err := searchDocument(SearchTypePart, part.ID, part, map[string]string{
+			"id": part.ID, "name": part.Name, "description": part.Description, "type": part.Type, "status": part.Status,
+		})
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	}
+	for supplier, err := range s.client.Suppliers.Iter(ctx, nil, nil) {
+		if err != nil {
+			return fmt.Errorf("error listing suppliers: %w", err)
+		}
+		doc, err := searchDocument(SearchTypeSupplier, supplier.ID, supplier, map[string]string{
+			"id": supplier.ID, "name": supplier.Name, "status": supplier.Status,
+		})
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	}
+	for order, err := range s.client.Orders.Iter(ctx, nil, nil) {
+		if err != nil {
+			return fmt.Errorf("error listing orders: %w", err)
+		}
+		doc, err := searchDocument(SearchTypeOrder, order.ID, order, map[string]string{
+			"id": order.ID, "customer_id": order.CustomerID, "supplier_id": order.SupplierID, "priority": order.Priority, "status": order.Status,
+		})
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	}
+
+	s.client.searchIndex.Replace(docs, time.Now().UTC())
+	if err := s.client.searchIndex.Save(s.client.searchIndexPath); err != nil {
+		return fmt.Errorf("error saving search index: %w", err)
+	}
+	return nil
+}
+
+// Run refreshes the local index every interval until ctx is done, starting
+// immediately unless the index on disk is newer than interval. Refresh
+// errors are passed to onError and do not stop the loop.
+func (s *SearchIndexService) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	if !s.Enabled() {
+		return
+	}
+
+	wait := time.Until(s.client.searchIndex.BuiltAt().Add(interval))
+	timer := time.NewTimer(max(wait, 0))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+				onError(err)
+			}
+			timer.Reset(interval)
+		}
+	}
+}
+
+// searchDocument builds an index document for a record from its searchable text
+func searchDocument(typ, id string, item interface{}, text map[string]string) (*index.Document, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling %s %s: %w", typ, id, err)
+	}
+	return &index.Document{Type: typ, ID: id, Text: text, Item: data}, nil
+}
@@ -9,14 +9,37 @@ import (
 	"strings"
 )
 
-// Search performs a search across all entities
-func (s *SearchService) Search(ctx context.Context, opts *SearchOptions) ([]interface{}, error) {
+// searchGraphQLTypes maps each search type to its GraphQL type name, which
+// the search is narrowed by and results are told apart by
+var searchGraphQLTypes = map[string]string{
+	SearchTypePart:          "Part",
+	SearchTypeOrder:         "Order",
+	SearchTypeSupplier:      "Supplier",
+	SearchTypeInventoryItem: "InventoryItem",
+}
+
+// Search performs a search across all entities, or those of opts.Types,
+// returning the results in rank order
+func (s *SearchService) Search(ctx context.Context, opts *SearchOptions) ([]*SearchResult, error) {
+	// The types argument is only sent when the search is narrowed
+	typesParam, typesArg := "", ""
+	wanted := map[string]bool{}
+	if len(opts.Types) > 0 {
+		typesParam, typesArg = ", $types: [String!]", ", types: $types"
+		for _, typ := range opts.Types {
+			if _, ok := searchGraphQLTypes[typ]; !ok {
+				return nil, fmt.Errorf("unknown search type %q", typ)
+			}
+			wanted[typ] = true
+		}
+	}
+
 	// GraphQL query to search across all entities
 	query := `
-		query Search($query: String!, $sort: String, $order: String, $page: Int, $perPage: Int) {
-			search(query: $query, sort: $sort, order: $order, page: $page, perPage: $perPage) {
+		query Search($query: String!` + typesParam + `, $sort: String, $order: String, $page: Int, $perPage: Int) {
+			search(query: $query` + typesArg + `, sort: $sort, order: $order, page: $page, perPage: $perPage) {
+				__typename
 				id
-				type
 				name
 				description
 				status
@@ -45,6 +68,13 @@ func (s *SearchService) Search(ctx context.Context, opts *SearchOptions) ([]inte
 		"query": opts.Query,
 	}
 
+	if len(opts.Types) > 0 {
+		types := make([]string, len(opts.Types))
+		for i, typ := range opts.Types {
+			types[i] = searchGraphQLTypes[typ]
+		}
+		variables["types"] = types
+	}
 	if opts.Sort != "" {
 		variables["sort"] = opts.Sort
 	}
@@ -117,43 +147,64 @@ func (s *SearchService) Search(ctx context.Context, opts *SearchOptions) ([]inte
 		return nil, fmt.Errorf("GraphQL error: %s", result.Errors[0].Message)
 	}
 
-	// Convert the search results to the appropriate types based on the "type" field
-	var results []interface{}
+	// Rank continues across pages
+	rank := 1
+	if opts.Page > 1 && opts.PerPage > 0 {
+		rank += (opts.Page - 1) * opts.PerPage
+	}
+
+	// Convert the search results to the appropriate types based on their GraphQL type
+	results := []*SearchResult{}
 	for _, item := range result.Data.Search {
-		itemType, ok := item["type"].(string)
+		typeName, ok := item["__typename"].(string)
 		if !ok {
 			continue
 		}
+		delete(item, "__typename")
+		typ := typeName
+		for searchType, name := range searchGraphQLTypes {
+			if name == typeName {
+				typ = searchType
+			}
+		}
+		// Results of other types are dropped, in case the API ignores types
+		if len(wanted) > 0 && !wanted[typ] {
+			continue
+		}
+
+		r := &SearchResult{Rank: rank}
+		rank++
 
-		switch itemType {
-		case "part":
+		switch typ {
+		case SearchTypePart:
 			var part Part
 			if err := mapToStruct(item, &part); err != nil {
 				return nil, fmt.Errorf("error converting search result to Part: %w", err)
 			}
-			results = append(results, &part)
-		case "order":
+			r.Type, r.Item = SearchTypePart, &part
+		case SearchTypeOrder:
 			var order Order
 			if err := mapToStruct(item, &order); err != nil {
 				return nil, fmt.Errorf("error converting search result to Order: %w", err)
 			}
-			results = append(results, &order)
-		case "supplier":
+			r.Type, r.Item = SearchTypeOrder, &order
+		case SearchTypeSupplier:
 			var supplier Supplier
 			if err := mapToStruct(item, &supplier); err != nil {
 				return nil, fmt.Errorf("error converting search result to Supplier: %w", err)
 			}
-			results = append(results, &supplier)
-		case "inventory_item":
+			r.Type, r.Item = SearchTypeSupplier, &supplier
+		case SearchTypeInventoryItem:
 			var inventoryItem InventoryItem
 			if err := mapToStruct(item, &inventoryItem); err != nil {
 				return nil, fmt.Errorf("error converting search result to InventoryItem: %w", err)
 			}
-			results = append(results, &inventoryItem)
+			r.Type, r.Item = SearchTypeInventoryItem, &inventoryItem
 		default:
 			// For unknown types, just add the raw map
-			results = append(results, item)
+			r.Type, r.Item = typeName, item
 		}
+		results = append(results, r)
 	}
 
 	return results, nil
@@ -8,12 +8,20 @@ type TranslationHelperFunc func(key string, defaultValue string) string
 
 // NewServer creates a new First Resonance MCP server with the specified client and logger.
 func NewServer(getClient GetClientFn, version string, readOnly bool, t TranslationHelperFunc) *server.MCPServer {
+	// Track callers so mutations can be attributed in the audit log
+	callers := &auditCallers{}
+	hooks := &server.Hooks{}
+	callers.register(hooks)
+	// Mask supplier contact info in results if the client asks for it
+	registerContactRedaction(hooks, getClient)
+
 	// Create a new MCP server
 	s := server.NewMCPServer(
 		"firstresonance-mcp-server",
 		version,
 		server.WithResourceCapabilities(true, true),
-		server.WithLogging())
+		server.WithLogging(),
+		server.WithHooks(hooks))
 
 	// Add First Resonance Resources
 	s.AddResourceTemplate(GetPartContent(getClient, t))
@@ -24,37 +32,77 @@ func NewServer(getClient GetClientFn, version string, readOnly bool, t Translati
 	// Add First Resonance tools - Parts
 	s.AddTool(GetPart(getClient, t))
 	s.AddTool(ListParts(getClient, t))
+	s.AddTool(FindDuplicateParts(getClient, t))
 	if !readOnly {
-		s.AddTool(CreatePart(getClient, t))
-		s.AddTool(UpdatePart(getClient, t))
+		s.AddTool(callers.wrap(CreatePart(getClient, t)))
+		s.AddTool(callers.wrap(UpdatePart(getClient, t)))
 	}
 
 	// Add First Resonance tools - Orders
 	s.AddTool(GetOrder(getClient, t))
 	s.AddTool(ListOrders(getClient, t))
+	s.AddTool(GetOrderTransitions(getClient, t))
+	s.AddTool(OrderRiskReport(getClient, t))
 	if !readOnly {
-		s.AddTool(CreateOrder(getClient, t))
-		s.AddTool(UpdateOrder(getClient, t))
+		s.AddTool(callers.wrap(CreateOrder(getClient, t)))
+		s.AddTool(callers.wrap(UpdateOrder(getClient, t)))
+		s.AddTool(callers.wrap(AddOrderItem(getClient, t)))
+		s.AddTool(callers.wrap(RemoveOrderItem(getClient, t)))
+		s.AddTool(callers.wrap(ReceiveOrder(getClient, t)))
 	}
 
 	// Add First Resonance tools - Suppliers
 	s.AddTool(GetSupplier(getClient, t))
 	s.AddTool(ListSuppliers(getClient, t))
+	s.AddTool(SupplierScorecard(getClient, t))
+	s.AddTool(ListPartSuppliers(getClient, t))
+	s.AddTool(ListSupplierParts(getClient, t))
 	if !readOnly {
-		s.AddTool(CreateSupplier(getClient, t))
-		s.AddTool(UpdateSupplier(getClient, t))
+		s.AddTool(callers.wrap(CreateSupplier(getClient, t)))
+		s.AddTool(callers.wrap(UpdateSupplier(getClient, t)))
 	}
 
 	// Add First Resonance tools - Inventory
 	s.AddTool(GetInventoryItem(getClient, t))
 	s.AddTool(ListInventoryItems(getClient, t))
 	if !readOnly {
-		s.AddTool(UpdateInventoryItem(getClient, t))
+		s.AddTool(callers.wrap(UpdateInventoryItem(getClient, t)))
+		s.AddTool(callers.wrap(AdjustInventory(getClient, t)))
+		s.AddTool(callers.wrap(TransferInventory(getClient, t)))
 	}
+	s.AddTool(LowStockReport(getClient, t))
+	s.AddTool(ListInventoryByLot(getClient, t))
+	s.AddTool(TraceSerial(getClient, t))
+	s.AddTool(ExpiringLots(getClient, t))
+
+	// Add First Resonance tools - ABOMs
+	s.AddTool(TraceGenealogy(getClient, t))
+
+	// Add First Resonance tools - Costs
+	s.AddTool(ListPartCosts(getClient, t))
+	s.AddTool(BomCostRollup(getClient, t))
+	s.AddTool(InventoryValuationReport(getClient, t))
+	s.AddTool(ABCAnalysisReport(getClient, t))
+
+	// Add First Resonance tools - Units of measure
+	s.AddTool(ListUnits(getClient, t))
+	s.AddTool(ConvertQuantity(getClient, t))
 
 	// Add First Resonance tools - Search
+	s.AddTool(Search(getClient, t))
 	s.AddTool(SearchParts(getClient, t))
 	s.AddTool(SearchOrders(getClient, t))
+	s.AddTool(SearchSuppliers(getClient, t))
+	s.AddTool(SearchInventory(getClient, t))
+	s.AddTool(SearchLocal(getClient, t))
+
+	// Add First Resonance tools - Import
+	if !readOnly {
+		s.AddTool(callers.wrap(ImportRecords(getClient, t)))
+	}
+
+	// Add First Resonance tools - Audit
+	s.AddTool(QueryAuditLog(getClient, t))
 
 	return s
 }
@@ -0,0 +1,268 @@
+package firstresonance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/contacts"
+)
+
+// ErrInvalidContactInfo is returned when a supplier's contact info fails validation
+var ErrInvalidContactInfo = errors.New("invalid contact info")
+
+// contactInfoFields has ContactInfo's fields without its lenient decoding
+type contactInfoFields ContactInfo
+
+// legacyContactKeys are the keys of a free-form contact_info map, as written
+// before contact info was typed, and the contact fields they hold
+var legacyContactKeys = map[string]string{
+	"name":         "name",
+	"contact_name": "name",
+	"contact":      "name",
+	"email":        "email",
+	"phone":        "phone",
+	"address":      "address",
+}
+
+// ParseContactInfo converts contact info decoded from JSON, such as tool
+// arguments, to ContactInfo, then validates and normalizes it. Unknown fields
+// are rejected.
+func ParseContactInfo(raw map[string]interface{}) (*ContactInfo, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidContactInfo, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var info contactInfoFields
+	if err := dec.Decode(&info); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidContactInfo, err)
+	}
+	c := ContactInfo(info)
+	if err := c.Normalize(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Normalize validates every contact's email and phone and rewrites them in
+// normal form. Every contact needs a name, email or phone, and at most one
+// can be primary; if none is, the first contact becomes primary. All
+// problems found are reported together.
+func (c *ContactInfo) Normalize() error {
+	var problems []string
+	primary := 0
+	for i := range c.Contacts {
+		contact := &c.Contacts[i]
+		n := i + 1
+		contact.Name = strings.TrimSpace(contact.Name)
+		contact.Role = strings.TrimSpace(contact.Role)
+		if contact.Name == "" && contact.Email == "" && contact.Phone == "" {
+			problems = append(problems, fmt.Sprintf("contact %d: a name, email or phone is required", n))
+		}
+		if contact.Email != "" {
+			email, err := contacts.NormalizeEmail(contact.Email)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("contact %d: %v", n, err))
+			}
+			contact.Email = email
+		}
+		if contact.Phone != "" {
+			phone, err := contacts.NormalizePhone(contact.Phone)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("contact %d: %v", n, err))
+			}
+			contact.Phone = phone
+		}
+		if contact.Primary {
+			primary++
+		}
+	}
+	if primary > 1 {
+		problems = append(problems, fmt.Sprintf("%d contacts are primary, only one can be", primary))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidContactInfo, strings.Join(problems, "; "))
+	}
+	if primary == 0 && len(c.Contacts) > 0 {
+		c.Contacts[0].Primary = true
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes contact info, reading a free-form map without
+// contacts, as written before contact info was typed, as a single primary
+// contact. Keys of such a map other than a name, email, phone and address
+// are dropped.
+func (c *ContactInfo) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if _, ok := fields["contacts"]; ok {
+		return json.Unmarshal(data, (*contactInfoFields)(c))
+	}
+
+	contact := Contact{Primary: true}
+	found := false
+	for key, raw := range fields {
+		field, ok := legacyContactKeys[key]
+		if !ok {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			// Addresses may already be objects
+			var address Address
+			if field != "address" || json.Unmarshal(raw, &address) != nil {
+				continue
+			}
+			contact.Address, found = &address, true
+			continue
+		}
+		if value == "" {
+			continue
+		}
+		switch field {
+		case "name":
+			contact.Name = value
+		case "email":
+			contact.Email = value
+		case "phone":
+			contact.Phone = value
+		case "address":
+			contact.Address = &Address{Street: value}
+		}
+		found = true
+	}
+
+	c.Contacts = []Contact{}
+	if found {
+		c.Contacts = append(c.Contacts, contact)
+	}
+	return nil
+}
+
+// Redacted returns a copy of the contact info with names reduced to
+// initials, emails and phones masked, and street addresses and postal codes
+// hidden. Roles, cities, regions and countries are kept.
+func (c *ContactInfo) Redacted() *ContactInfo {
+	redacted := &ContactInfo{Contacts: make([]Contact, len(c.Contacts))}
+	for i, contact := range c.Contacts {
+		contact.Name = contacts.MaskName(contact.Name)
+		if contact.Email != "" {
+			contact.Email = contacts.MaskEmail(contact.Email)
+		}
+		if contact.Phone != "" {
+			contact.Phone = contacts.MaskPhone(contact.Phone)
+		}
+		if contact.Address != nil {
+			address := *contact.Address
+			address.Street = contacts.Mask(address.Street)
+			address.PostalCode = contacts.Mask(address.PostalCode)
+			contact.Address = &address
+		}
+		redacted.Contacts[i] = contact
+	}
+	return redacted
+}
+
+// registerContactRedaction adds hooks that mask supplier contact info in
+// every tool and resource result when the client has contact redaction on
+func registerContactRedaction(hooks *server.Hooks, getClient GetClientFn) {
+	enabled := func(ctx context.Context) bool {
+		client, err := getClient(ctx)
+		return err == nil && client.redactContacts
+	}
+	hooks.AddAfterCallTool(func(ctx context.Context, _ any, _ *mcp.CallToolRequest, result *mcp.CallToolResult) {
+		if result == nil || !enabled(ctx) {
+			return
+		}
+		for i, content := range result.Content {
+			if text, ok := content.(mcp.TextContent); ok {
+				text.Text = redactContactText(text.Text)
+				result.Content[i] = text
+			}
+		}
+	})
+	hooks.AddAfterReadResource(func(ctx context.Context, _ any, _ *mcp.ReadResourceRequest, result *mcp.ReadResourceResult) {
+		if result == nil || !enabled(ctx) {
+			return
+		}
+		for i, content := range result.Contents {
+			if text, ok := content.(mcp.TextResourceContents); ok {
+				text.Text = redactContactText(text.Text)
+				result.Contents[i] = text
+			}
+		}
+	})
+}
+
+// redactContactText redacts every contact_info in a JSON document. Text
+// that is not JSON, or has no contact info, is returned unchanged.
+func redactContactText(text string) string {
+	if !strings.Contains(text, `"contact_info"`) {
+		return text
+	}
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return text
+	}
+	if !redactContactValues(doc) {
+		return text
+	}
+	r, err := json.Marshal(doc)
+	if err != nil {
+		return text
+	}
+	return string(r)
+}
+
+// redactContactValues replaces every contact_info object within v with its
+// redacted form and reports whether any was found
+func redactContactValues(v interface{}) bool {
+	found := false
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if key == "contact_info" && value != nil {
+				v[key] = redactContactValue(value)
+				found = true
+				continue
+			}
+			found = redactContactValues(value) || found
+		}
+	case []interface{}:
+		for _, value := range v {
+			found = redactContactValues(value) || found
+		}
+	}
+	return found
+}
+
+// redactContactValue returns the redacted form of a decoded contact_info
+// value, or a fully masked value if it cannot be read as contact info
+func redactContactValue(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return contacts.Masked
+	}
+	var info ContactInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return contacts.Masked
+	}
+	var redacted interface{}
+	if data, err = json.Marshal(info.Redacted()); err != nil || json.Unmarshal(data, &redacted) != nil {
+		return contacts.Masked
+	}
+	return redacted
+}
@@ -0,0 +1,148 @@
+package firstresonance
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/dates"
+)
+
+// Scorecard scores every supplier with orders due between since and until,
+// inclusive, on their deliveries: the share received on time, the average
+// days late and the share of lines received in a different quantity than
+// ordered. Suppliers are ranked by on-time rate, then discrepancy rate, then
+// average lateness, then order volume; suppliers with nothing delivered in
+// the window rank last.
+func (s *SuppliersService) Scorecard(ctx context.Context, since, until time.Time) ([]*SupplierScore, error) {
+	orders, err := s.client.Orders.analysisOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type tally struct {
+		score    *SupplierScore
+		onTime   int
+		daysLate int
+		lines    int
+		mismatch int
+	}
+	tallies := map[string]*tally{}
+	for _, order := range orders {
+		if order.SupplierID == "" {
+			continue
+		}
+		due, err := dates.Parse(order.DueDate)
+		if err != nil || dates.DaysBetween(since, due) < 0 || dates.DaysBetween(due, until) < 0 {
+			continue
+		}
+
+		t := tallies[order.SupplierID]
+		if t == nil {
+			t = &tally{score: &SupplierScore{SupplierID: order.SupplierID}}
+			tallies[order.SupplierID] = t
+		}
+		t.score.Orders++
+		t.score.Lines += len(order.Items)
+
+		delivered, ok := orderDelivered(order)
+		if !ok {
+			continue
+		}
+		t.score.Delivered++
+		if late := dates.DaysBetween(due, delivered); late > 0 {
+			t.daysLate += late
+		} else {
+			t.onTime++
+		}
+		for _, item := range order.Items {
+			t.lines++
+			if item.ReceivedQuantity != item.Quantity {
+				t.mismatch++
+			}
+		}
+	}
+
+	scores := make([]*SupplierScore, 0, len(tallies))
+	for _, t := range tallies {
+		score := t.score
+		if score.Delivered > 0 {
+			onTime := float64(t.onTime) / float64(score.Delivered)
+			daysLate := float64(t.daysLate) / float64(score.Delivered)
+			score.OnTimeRate, score.AverageDaysLate = &onTime, &daysLate
+		}
+		if t.lines > 0 {
+			discrepancy := float64(t.mismatch) / float64(t.lines)
+			score.DiscrepancyRate = &discrepancy
+		}
+		if supplier, err := s.Get(ctx, score.SupplierID); err == nil {
+			score.SupplierName = supplier.Name
+		}
+		scores = append(scores, score)
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		a, b := scores[i], scores[j]
+		if (a.Delivered > 0) != (b.Delivered > 0) {
+			return a.Delivered > 0
+		}
+		if a.Delivered > 0 {
+			if *a.OnTimeRate != *b.OnTimeRate {
+				return *a.OnTimeRate > *b.OnTimeRate
+			}
+			if *a.DiscrepancyRate != *b.DiscrepancyRate {
+				return *a.DiscrepancyRate < *b.DiscrepancyRate
+			}
+			if *a.AverageDaysLate != *b.AverageDaysLate {
+				return *a.AverageDaysLate < *b.AverageDaysLate
+			}
+		}
+		if a.Orders != b.Orders {
+			return a.Orders > b.Orders
+		}
+		return a.SupplierID < b.SupplierID
+	})
+	for i, score := range scores {
+		score.Rank = i + 1
+	}
+	return scores, nil
+}
+
+// ScorecardMarkdown renders supplier scores as a markdown table
+func ScorecardMarkdown(scores []*SupplierScore, since, until time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Supplier scorecard for orders due %s to %s\n\n", since.Format(time.DateOnly), until.Format(time.DateOnly))
+	b.WriteString("| Rank | Supplier | Orders | Lines | Delivered | On time | Avg days late | Discrepancies |\n")
+	b.WriteString("| ---: | --- | ---: | ---: | ---: | ---: | ---: | ---: |\n")
+	for _, score := range scores {
+		name := score.SupplierID
+		if score.SupplierName != "" {
+			name = fmt.Sprintf("%s (%s)", score.SupplierName, score.SupplierID)
+		}
+		fmt.Fprintf(&b, "| %d | %s | %d | %d | %d | %s | %s | %s |\n",
+			score.Rank, markdownCell(name), score.Orders, score.Lines, score.Delivered,
+			formatRate(score.OnTimeRate), formatDays(score.AverageDaysLate), formatRate(score.DiscrepancyRate))
+	}
+	if len(scores) == 0 {
+		b.WriteString("\nNo orders with a supplier were due in this window.\n")
+	}
+	return b.String()
+}
+
+// formatRate formats a share as a percentage, or n/a if there is none
+func formatRate(rate *float64) string {
+	if rate == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.0f%%", *rate*100)
+}
+
+// formatDays formats a number of days, or n/a if there is none
+func formatDays(days *float64) string {
+	if days == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.1f", *days)
+}
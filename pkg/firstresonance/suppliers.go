@@ -3,14 +3,46 @@ package firstresonance
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
+	"github.com/firstresonance/fr-mcp-server/pkg/dates"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// contactInfoProperties is the JSON schema of contact info in tool parameters
+var contactInfoProperties = map[string]interface{}{
+	"contacts": map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name":    map[string]interface{}{"type": "string", "description": "Contact name"},
+				"role":    map[string]interface{}{"type": "string", "description": "Role, e.g. sales or accounts payable"},
+				"email":   map[string]interface{}{"type": "string", "description": "Email address"},
+				"phone":   map[string]interface{}{"type": "string", "description": "Phone number, with a leading + and country code if international"},
+				"primary": map[string]interface{}{"type": "boolean", "description": "Whether this is the primary contact; at most one is, the first by default"},
+				"address": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"street":      map[string]interface{}{"type": "string"},
+						"city":        map[string]interface{}{"type": "string"},
+						"region":      map[string]interface{}{"type": "string"},
+						"postal_code": map[string]interface{}{"type": "string"},
+						"country":     map[string]interface{}{"type": "string"},
+					},
+					"additionalProperties": false,
+				},
+			},
+			"additionalProperties": false,
+		},
+	},
+}
+
 // GetSupplier creates a tool to get details of a specific supplier.
 func GetSupplier(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_supplier",
@@ -66,7 +98,9 @@ func ListSuppliers(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Too
 			mcp.WithString("direction",
 				mcp.Description("Sort direction"),
 			),
-			WithPagination(),
+			WithFilter(supplierFilterFields),
+			WithCursorPagination(),
+			WithFetchAll(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			status, err := OptionalParam[string](request, "status")
@@ -85,6 +119,14 @@ func ListSuppliers(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Too
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
+			fetchAll, err := OptionalParam[bool](request, "fetch_all")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			listFilter, err := OptionalFilterParam(request, supplierFilterFields)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 
 			opts := &ListSuppliersOptions{
 				Status:    status,
@@ -96,25 +138,49 @@ func ListSuppliers(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Too
 				},
 			}
 
+			// Narrow the query by the filter's equality conditions
+			if listFilter != nil {
+				opts.Filter = listFilter.String()
+				eq := pushdownEqualities(listFilter)
+				if opts.Status == "" {
+					opts.Status = eq["status"]
+				}
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
 			}
-			suppliers, resp, err := client.Suppliers.List(ctx, opts)
+			if fetchAll {
+				all, err := collect(filterSeq(listFilter, client.Suppliers.Iter(ctx, opts, fetchAllIterOptions())), fetchAllLimit)
+				if errors.Is(err, ErrTooManyResults) {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				if err != nil {
+					return nil, fmt.Errorf("failed to list suppliers: %w", err)
+				}
+
+				r, err := json.Marshal(completePage(all))
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal response: %w", err)
+				}
+
+				return mcp.NewToolResultText(string(r)), nil
+			}
+			page, err := client.Suppliers.ListPage(ctx, opts, pagination.cursor)
+			if errors.Is(err, ErrInvalidCursor) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 			if err != nil {
 				return nil, fmt.Errorf("failed to list suppliers: %w", err)
 			}
-			defer func() { _ = resp.Body.Close() }()
 
-			if resp.StatusCode != http.StatusOK {
-				body, err := io.ReadAll(resp.Body)
-				if err != nil {
-					return nil, fmt.Errorf("failed to read response body: %w", err)
-				}
-				return mcp.NewToolResultError(fmt.Sprintf("failed to list suppliers: %s", string(body))), nil
+			page, err = filterPage(listFilter, page)
+			if err != nil {
+				return nil, err
 			}
 
-			r, err := json.Marshal(suppliers)
+			r, err := json.Marshal(page)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
@@ -132,7 +198,8 @@ func CreateSupplier(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.To
 				mcp.Description("Supplier name"),
 			),
 			mcp.WithObject("contact_info",
-				mcp.Description("Contact information"),
+				mcp.Description("Contact information. Emails and phones are validated and normalized"),
+				mcp.Properties(contactInfoProperties),
 			),
 			mcp.WithString("status",
 				mcp.Description("Supplier status"),
@@ -144,9 +211,13 @@ func CreateSupplier(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.To
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
-			contactInfo, err := OptionalParam[map[string]interface{}](request, "contact_info")
-			if err != nil {
+			var contactInfo *ContactInfo
+			if rawContactInfo, ok, err := OptionalParamOK[map[string]interface{}](request, "contact_info"); err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
+			} else if ok {
+				if contactInfo, err = ParseContactInfo(rawContactInfo); err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
 			}
 
 			status, err := OptionalParam[string](request, "status")
@@ -165,6 +236,7 @@ func CreateSupplier(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.To
 				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
 			}
 			createdSupplier, resp, err := client.Suppliers.Create(ctx, supplier)
+			warnings, err := auditWarnings(err)
 			if err != nil {
 				return nil, fmt.Errorf("failed to create supplier: %w", err)
 			}
@@ -183,7 +255,7 @@ func CreateSupplier(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.To
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return withWarnings(mcp.NewToolResultText(string(r)), warnings), nil
 		}
 }
 
@@ -199,7 +271,8 @@ func UpdateSupplier(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.To
 				mcp.Description("New name"),
 			),
 			mcp.WithObject("contact_info",
-				mcp.Description("New contact information"),
+				mcp.Description("New contact information, replacing all existing contacts. Emails and phones are validated and normalized"),
+				mcp.Properties(contactInfoProperties),
 			),
 			mcp.WithString("status",
 				mcp.Description("New status"),
@@ -221,10 +294,14 @@ func UpdateSupplier(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.To
 				updateNeeded = true
 			}
 
-			if contactInfo, ok, err := OptionalParamOK[map[string]interface{}](request, "contact_info"); err != nil {
+			if rawContactInfo, ok, err := OptionalParamOK[map[string]interface{}](request, "contact_info"); err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			} else if ok {
-				update.ContactInfo = &contactInfo
+				contactInfo, err := ParseContactInfo(rawContactInfo)
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				update.ContactInfo = contactInfo
 				updateNeeded = true
 			}
 
@@ -244,6 +321,7 @@ func UpdateSupplier(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.To
 				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
 			}
 			updatedSupplier, resp, err := client.Suppliers.Update(ctx, supplierID, update)
+			warnings, err := auditWarnings(err)
 			if err != nil {
 				return nil, fmt.Errorf("failed to update supplier: %w", err)
 			}
@@ -262,6 +340,77 @@ func UpdateSupplier(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.To
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return withWarnings(mcp.NewToolResultText(string(r)), warnings), nil
 		}
-} 
\ No newline at end of file
+} 
+
+// defaultScorecardDays is the length of the scorecard window when no start is given
+const defaultScorecardDays = 90
+
+// SupplierScorecard creates a tool to score and rank suppliers on their deliveries.
+func SupplierScorecard(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("supplier_scorecard",
+			mcp.WithDescription(t("TOOL_SUPPLIER_SCORECARD_DESCRIPTION", "Score and rank suppliers on the orders due in a date window: order volume, on-time delivery rate, average days late and the share of lines received in a different quantity than ordered")),
+			mcp.WithString("since",
+				mcp.Description(fmt.Sprintf("Start of the window of due dates (default %d days before until)", defaultScorecardDays)),
+			),
+			mcp.WithString("until",
+				mcp.Description("End of the window of due dates, inclusive (default today)"),
+			),
+			mcp.WithString("format",
+				mcp.Description("Output format (default markdown)"),
+				mcp.Enum("markdown", "json"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			sinceParam, err := OptionalParam[string](request, "since")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			untilParam, err := OptionalParam[string](request, "until")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			format, err := OptionalParam[string](request, "format")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			until := time.Now().UTC()
+			if untilParam != "" {
+				if until, err = dates.Parse(untilParam); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid until: %s", err)), nil
+				}
+			}
+			since := until.AddDate(0, 0, -defaultScorecardDays)
+			if sinceParam != "" {
+				if since, err = dates.Parse(sinceParam); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid since: %s", err)), nil
+				}
+			}
+			if since.After(until) {
+				return mcp.NewToolResultError("since must not be after until"), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			scores, err := client.Suppliers.Scorecard(ctx, since, until)
+			if errors.Is(err, ErrTooManyResults) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to build supplier scorecard: %w", err)
+			}
+
+			if format == "json" {
+				r, err := json.Marshal(scores)
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal response: %w", err)
+				}
+				return mcp.NewToolResultText(string(r)), nil
+			}
+			return mcp.NewToolResultText(ScorecardMarkdown(scores, since, until)), nil
+		}
+}
@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"strings"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/audit"
 )
 
 // Get retrieves a supplier by its ID
@@ -191,8 +194,79 @@ func (s *SuppliersService) List(ctx context.Context, opts *ListSuppliersOptions)
 	return result.Data.Suppliers, nil
 }
 
-// Create creates a new supplier
+// Iter returns an iterator over every supplier matching opts, fetching pages as needed
+func (s *SuppliersService) Iter(ctx context.Context, opts *ListSuppliersOptions, iterOpts *IterOptions) iter.Seq2[*Supplier, error] {
+	return paginate(ctx, func(ctx context.Context, page, perPage int) ([]*Supplier, error) {
+		pageOpts := ListSuppliersOptions{}
+		if opts != nil {
+			pageOpts = *opts
+		}
+		pageOpts.Page, pageOpts.PerPage = page, perPage
+		return s.List(ctx, &pageOpts)
+	}, iterOpts)
+}
+
+// ListPage retrieves the page of suppliers that the cursor points to, or the
+// page in opts if the cursor is empty
+func (s *SuppliersService) ListPage(ctx context.Context, opts *ListSuppliersOptions, cursor string) (*Page[*Supplier], error) {
+	if opts == nil {
+		opts = &ListSuppliersOptions{}
+	}
+
+	// GraphQL query to fetch a page of the suppliers connection
+	query := `
+		query SuppliersConnection($status: String, $sort: String, $direction: String, $first: Int, $after: String) {
+			suppliersConnection(status: $status, sort: $sort, direction: $direction, first: $first, after: $after) {
+				totalCount
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+				nodes {
+					id
+					name
+					contact_info
+					status
+				}
+			}
+		}
+	`
+
+	// Create filter variables for the query
+	filters := map[string]interface{}{}
+	if opts.Status != "" {
+		filters["status"] = opts.Status
+	}
+	if opts.Sort != "" {
+		filters["sort"] = opts.Sort
+	}
+	if opts.Direction != "" {
+		filters["direction"] = opts.Direction
+	}
+
+	return listPage(ctx, cursor, opts.ListOptions, filters,
+		func(ctx context.Context, first int, after string) (*connection[*Supplier], error) {
+			return fetchConnection[*Supplier](ctx, s.client, "suppliersConnection", query, filters, first, after)
+		},
+		func(ctx context.Context, page, perPage int) ([]*Supplier, error) {
+			pageOpts := *opts
+			pageOpts.Page, pageOpts.PerPage = page, perPage
+			return s.List(ctx, &pageOpts)
+		})
+}
+
+// Create creates a new supplier and records it in the audit log
 func (s *SuppliersService) Create(ctx context.Context, supplier *Supplier) (*Supplier, error) {
+	created, err := s.create(ctx, supplier)
+	entry := &audit.Entry{Entity: auditEntitySupplier, Action: audit.ActionCreate, After: created}
+	if created != nil {
+		entry.EntityID = created.ID
+	}
+	return created, s.client.Audit.record(ctx, entry, err)
+}
+
+// create sends the createSupplier mutation
+func (s *SuppliersService) create(ctx context.Context, supplier *Supplier) (*Supplier, error) {
 	// GraphQL mutation to create a new supplier
 	query := `
 		mutation CreateSupplier($input: CreateSupplierInput!) {
@@ -276,8 +350,16 @@ func (s *SuppliersService) Create(ctx context.Context, supplier *Supplier) (*Sup
 	return result.Data.CreateSupplier, nil
 }
 
-// Update updates an existing supplier
+// Update updates an existing supplier and records the change in the audit log
 func (s *SuppliersService) Update(ctx context.Context, id string, update *SupplierUpdateRequest) (*Supplier, error) {
+	before := auditSnapshot(ctx, s.client, s.Get, id)
+	updated, err := s.update(ctx, id, update)
+	entry := &audit.Entry{Entity: auditEntitySupplier, EntityID: id, Action: audit.ActionUpdate, Before: before, After: updated}
+	return updated, s.client.Audit.record(ctx, entry, err)
+}
+
+// update sends the updateSupplier mutation
+func (s *SuppliersService) update(ctx context.Context, id string, update *SupplierUpdateRequest) (*Supplier, error) {
 	// GraphQL mutation to update an existing supplier
 	query := `
 		mutation UpdateSupplier($id: ID!, $input: UpdateSupplierInput!) {
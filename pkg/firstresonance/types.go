@@ -1,9 +1,20 @@
 package firstresonance
 
+import (
+	"time"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/audit"
+	"github.com/firstresonance/fr-mcp-server/pkg/dedupe"
+	"github.com/firstresonance/fr-mcp-server/pkg/lifecycle"
+)
+
 // ListOptions represents pagination parameters
 type ListOptions struct {
 	Page    int
 	PerPage int
+	// Filter is the expression the caller filters the page by, if any.
+	// Cursors are only accepted with the filter they were issued for.
+	Filter string
 }
 
 // Part represents a part in First Resonance
@@ -23,6 +34,20 @@ type PartUpdateRequest struct {
 	Status      *string `json:"status,omitempty"`
 }
 
+// DuplicatePartCluster is a group of parts that are likely duplicates of each other
+type DuplicatePartCluster struct {
+	Parts []*Part `json:"parts"`
+	// Confidence is the highest confidence of the matches in the cluster, from 0 to 1
+	Confidence float64        `json:"confidence"`
+	Matches    []dedupe.Match `json:"matches"`
+}
+
+// SimilarPart is an existing part that is likely a duplicate of another
+type SimilarPart struct {
+	Part       *Part   `json:"part"`
+	Confidence float64 `json:"confidence"`
+}
+
 // ListPartsOptions represents options for listing parts
 type ListPartsOptions struct {
 	Status string
@@ -32,19 +57,85 @@ type ListPartsOptions struct {
 
 // Order represents an order in First Resonance
 type Order struct {
-	ID         string        `json:"id"`
-	CustomerID string        `json:"customer_id"`
-	Items      []interface{} `json:"items"`
-	Priority   string        `json:"priority,omitempty"`
-	DueDate    string        `json:"due_date,omitempty"`
-	Status     string        `json:"status,omitempty"`
+	ID         string      `json:"id"`
+	CustomerID string      `json:"customer_id"`
+	SupplierID string      `json:"supplier_id,omitempty"`
+	Items      []OrderItem `json:"items"`
+	Priority   string      `json:"priority,omitempty"`
+	DueDate    string      `json:"due_date,omitempty"`
+	Status     string      `json:"status,omitempty"`
+}
+
+// OrderItem represents a line item of an order
+type OrderItem struct {
+	PartID        string  `json:"part_id"`
+	Quantity      float64 `json:"quantity"`
+	UnitPrice     float64 `json:"unit_price,omitempty"`
+	Unit          string  `json:"unit,omitempty"`
+	RequestedDate string  `json:"requested_date,omitempty"`
+	// ReceivedQuantity, ReceiptStatus and ReceivedDate are set by receiving
+	// the order. ReceivedDate is the date the line was received in full.
+	ReceivedQuantity float64 `json:"received_quantity,omitempty"`
+	ReceiptStatus    string  `json:"receipt_status,omitempty"`
+	ReceivedDate     string  `json:"received_date,omitempty"`
+}
+
+// Receipt statuses of order items
+const (
+	ReceiptStatusPartial  = "partially_received"
+	ReceiptStatusReceived = "received"
+)
+
+// OrderReceipt represents goods received against an order's lines
+type OrderReceipt struct {
+	// Location is where received stock is put (default DefaultReceivingLocation)
+	Location string             `json:"location,omitempty"`
+	Lines    []OrderReceiptLine `json:"lines"`
+	Note     string             `json:"note,omitempty"`
+}
+
+// OrderReceiptLine is the quantity received of a line, numbered from 1
+type OrderReceiptLine struct {
+	Line     int     `json:"line"`
+	Quantity float64 `json:"quantity"`
+}
+
+// ReceiptDiscrepancy is a line whose received quantity differs from the
+// quantity ordered after a receipt
+type ReceiptDiscrepancy struct {
+	Line     int     `json:"line"`
+	PartID   string  `json:"part_id"`
+	Ordered  float64 `json:"ordered"`
+	Received float64 `json:"received"`
+	// Kind is "short" if less than ordered has been received so far, or
+	// "over" if more
+	Kind string `json:"kind"`
+}
+
+// OrderReceiptResult reports the outcome of receiving an order
+type OrderReceiptResult struct {
+	Order         *Order               `json:"order"`
+	Inventory     []*InventoryItem     `json:"inventory"`
+	Discrepancies []ReceiptDiscrepancy `json:"discrepancies"`
+	// Complete is set when every line has been received in full
+	Complete bool `json:"complete"`
+}
+
+// OrderTransitions lists the status moves out of an order's current status
+type OrderTransitions struct {
+	OrderID string `json:"order_id"`
+	Status  string `json:"status"`
+	// Final is set if the status is final and the order cannot move on
+	Final bool             `json:"final"`
+	Moves []lifecycle.Move `json:"moves"`
 }
 
 // OrderUpdateRequest represents a request to update an order
 type OrderUpdateRequest struct {
-	Status   *string `json:"status,omitempty"`
-	Priority *string `json:"priority,omitempty"`
-	DueDate  *string `json:"due_date,omitempty"`
+	Status   *string      `json:"status,omitempty"`
+	Priority *string      `json:"priority,omitempty"`
+	DueDate  *string      `json:"due_date,omitempty"`
+	Items    *[]OrderItem `json:"items,omitempty"`
 }
 
 // ListOrdersOptions represents options for listing orders
@@ -57,17 +148,42 @@ type ListOrdersOptions struct {
 
 // Supplier represents a supplier in First Resonance
 type Supplier struct {
-	ID          string                 `json:"id"`
-	Name        string                 `json:"name"`
-	ContactInfo map[string]interface{} `json:"contact_info,omitempty"`
-	Status      string                 `json:"status,omitempty"`
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	ContactInfo *ContactInfo `json:"contact_info,omitempty"`
+	Status      string       `json:"status,omitempty"`
 }
 
 // SupplierUpdateRequest represents a request to update a supplier
 type SupplierUpdateRequest struct {
-	Name        *string                 `json:"name,omitempty"`
-	ContactInfo *map[string]interface{} `json:"contact_info,omitempty"`
-	Status      *string                 `json:"status,omitempty"`
+	Name        *string      `json:"name,omitempty"`
+	ContactInfo *ContactInfo `json:"contact_info,omitempty"`
+	Status      *string      `json:"status,omitempty"`
+}
+
+// ContactInfo holds a supplier's contacts
+type ContactInfo struct {
+	Contacts []Contact `json:"contacts"`
+}
+
+// Contact is a person or desk at a supplier
+type Contact struct {
+	Name    string   `json:"name,omitempty"`
+	Role    string   `json:"role,omitempty"`
+	Email   string   `json:"email,omitempty"`
+	Phone   string   `json:"phone,omitempty"`
+	Address *Address `json:"address,omitempty"`
+	// Primary marks the contact to use first; a supplier has one
+	Primary bool `json:"primary,omitempty"`
+}
+
+// Address is a postal address
+type Address struct {
+	Street     string `json:"street,omitempty"`
+	City       string `json:"city,omitempty"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country,omitempty"`
 }
 
 // ListSuppliersOptions represents options for listing suppliers
@@ -80,43 +196,235 @@ type ListSuppliersOptions struct {
 
 // InventoryItem represents an inventory item in First Resonance
 type InventoryItem struct {
-	ID       string `json:"id"`
-	Quantity int    `json:"quantity"`
-	Location string `json:"location,omitempty"`
-	Status   string `json:"status,omitempty"`
+	ID       string  `json:"id"`
+	PartID   string  `json:"part_id,omitempty"`
+	Quantity float64 `json:"quantity"`
+	Location string  `json:"location,omitempty"`
+	Status   string  `json:"status,omitempty"`
+	// LotNumber is the production or supplier lot the stock belongs to
+	LotNumber string `json:"lot_number,omitempty"`
+	// SerialNumber identifies a single unit; serialized items hold a quantity
+	// of one, or zero once consumed
+	SerialNumber string `json:"serial_number,omitempty"`
+	// ExpiryDate is the date the stock expires (YYYY-MM-DD)
+	ExpiryDate string `json:"expiry_date,omitempty"`
 }
 
 // InventoryItemUpdateRequest represents a request to update an inventory item
 type InventoryItemUpdateRequest struct {
-	Quantity *int    `json:"quantity,omitempty"`
-	Location *string `json:"location,omitempty"`
-	Status   *string `json:"status,omitempty"`
+	Quantity     *float64 `json:"quantity,omitempty"`
+	Location     *string  `json:"location,omitempty"`
+	Status       *string  `json:"status,omitempty"`
+	LotNumber    *string  `json:"lot_number,omitempty"`
+	SerialNumber *string  `json:"serial_number,omitempty"`
+	ExpiryDate   *string  `json:"expiry_date,omitempty"`
+}
+
+// Reason codes for inventory adjustments
+const (
+	AdjustmentReasonScrap       = "scrap"
+	AdjustmentReasonCycleCount  = "cycle_count"
+	AdjustmentReasonReceipt     = "receipt"
+	AdjustmentReasonConsumption = "consumption"
+)
+
+// InventoryAdjustment represents a relative change to an inventory item's quantity
+type InventoryAdjustment struct {
+	Delta            float64  `json:"delta"`
+	Reason           string   `json:"reason"`
+	Note             string   `json:"note,omitempty"`
+	ExpectedQuantity *float64 `json:"expected_quantity,omitempty"`
+}
+
+// InventoryTransfer represents a movement of stock from one inventory item to another.
+// The source is given either by item ID or by part, location and lot; the
+// destination either by item ID or by location, in which case the item for the
+// same part and lot at that location is incremented or created.
+type InventoryTransfer struct {
+	SourceItemID        string  `json:"source_item_id,omitempty"`
+	PartID              string  `json:"part_id,omitempty"`
+	SourceLocation      string  `json:"source_location,omitempty"`
+	LotNumber           string  `json:"lot_number,omitempty"`
+	DestinationItemID   string  `json:"destination_item_id,omitempty"`
+	DestinationLocation string  `json:"destination_location,omitempty"`
+	Quantity            float64 `json:"quantity"`
+	Note                string  `json:"note,omitempty"`
+}
+
+// InventoryTransferResult reports the balances of both records after a transfer
+type InventoryTransferResult struct {
+	Source             *InventoryItem `json:"source"`
+	Destination        *InventoryItem `json:"destination"`
+	CreatedDestination bool           `json:"created_destination"`
+}
+
+// SerialTrace is where a serial number is held and how it got there
+type SerialTrace struct {
+	SerialNumber string           `json:"serial_number"`
+	Items        []*InventoryItem `json:"items"`
+	// History holds the audit log entries of the items, oldest first
+	History []*audit.Entry `json:"history"`
+}
+
+// LotExpiry is the stock of a lot of a part that has expired or expires soon
+type LotExpiry struct {
+	PartID     string `json:"part_id"`
+	LotNumber  string `json:"lot_number,omitempty"`
+	ExpiryDate string `json:"expiry_date"`
+	// DaysUntilExpiry is negative for lots already expired
+	DaysUntilExpiry int                `json:"days_until_expiry"`
+	Expired         bool               `json:"expired"`
+	Quantity        float64            `json:"quantity"`
+	Locations       map[string]float64 `json:"locations"`
+	ItemIDs         []string           `json:"item_ids"`
 }
 
 // ListInventoryItemsOptions represents options for listing inventory items
 type ListInventoryItemsOptions struct {
-	Location  string
-	Status    string
-	Sort      string
-	Direction string
+	PartID       string
+	Location     string
+	Status       string
+	LotNumber    string
+	SerialNumber string
+	Sort         string
+	Direction    string
 	ListOptions
 }
 
+// Categories of order risk, from most to least urgent
+const (
+	OrderRiskOverdue     = "overdue"
+	OrderRiskDueThisWeek = "due_this_week"
+	OrderRiskAtRisk      = "at_risk"
+)
+
+// OrderRisk is an open order that needs attention and why
+type OrderRisk struct {
+	Order    *Order `json:"order"`
+	Category string `json:"category"`
+	// DueDate is the order's due date as YYYY-MM-DD, if it could be parsed
+	DueDate string `json:"due_date,omitempty"`
+	// DaysUntilDue is negative for overdue orders
+	DaysUntilDue *int     `json:"days_until_due,omitempty"`
+	Reasons      []string `json:"reasons"`
+}
+
+// SupplierScore is a supplier's delivery performance over a date window
+type SupplierScore struct {
+	Rank         int    `json:"rank"`
+	SupplierID   string `json:"supplier_id"`
+	SupplierName string `json:"supplier_name,omitempty"`
+	// Orders and Lines are the orders due in the window and their lines
+	Orders int `json:"orders"`
+	Lines  int `json:"lines"`
+	// Delivered is how many of the orders were received in full
+	Delivered int `json:"delivered"`
+	// OnTimeRate is the share of delivered orders received by their due date
+	OnTimeRate *float64 `json:"on_time_rate"`
+	// AverageDaysLate is the mean days past due of delivered orders, counting
+	// orders on time as zero
+	AverageDaysLate *float64 `json:"average_days_late"`
+	// DiscrepancyRate is the share of lines of delivered orders received in a
+	// different quantity than ordered
+	DiscrepancyRate *float64 `json:"discrepancy_rate"`
+}
+
+// ReorderPoint represents the stock thresholds for a part
+type ReorderPoint struct {
+	PartID          string  `json:"part_id"`
+	MinStock        float64 `json:"min_stock"`
+	ReorderQuantity float64 `json:"reorder_quantity,omitempty"`
+	SupplierID      string  `json:"supplier_id,omitempty"`
+	// Unit is the unit of MinStock and ReorderQuantity, by default the
+	// part's base unit
+	Unit string `json:"unit,omitempty"`
+}
+
+// LowStockItem represents a part whose on-hand quantity is below its minimum stock
+type LowStockItem struct {
+	PartID            string             `json:"part_id"`
+	PartName          string             `json:"part_name,omitempty"`
+	OnHand            float64            `json:"on_hand"`
+	Locations         map[string]float64 `json:"locations,omitempty"`
+	MinStock          float64            `json:"min_stock"`
+	Shortfall         float64            `json:"shortfall"`
+	ReorderQuantity   float64            `json:"reorder_quantity,omitempty"`
+	SuggestedQuantity float64            `json:"suggested_quantity"`
+	LastSupplierID    string             `json:"last_supplier_id,omitempty"`
+	// Unit is the part's base unit, which every quantity is in
+	Unit string `json:"unit,omitempty"`
+}
+
+// Approved vendor statuses
+const (
+	VendorStatusApproved     = "approved"
+	VendorStatusConditional  = "conditional"
+	VendorStatusPending      = "pending"
+	VendorStatusDisqualified = "disqualified"
+)
+
+// ApprovedVendor ties a part to a supplier that can supply it
+type ApprovedVendor struct {
+	PartID             string `json:"part_id"`
+	SupplierID         string `json:"supplier_id"`
+	SupplierPartNumber string `json:"supplier_part_number,omitempty"`
+	// LeadTimeDays is how many days the supplier takes to deliver the part
+	LeadTimeDays int          `json:"lead_time_days,omitempty"`
+	PriceBreaks  []PriceBreak `json:"price_breaks,omitempty"`
+	// Status is one of the VendorStatus constants
+	Status string `json:"status"`
+}
+
+// PriceBreak is the unit price a supplier charges from a minimum quantity up
+type PriceBreak struct {
+	MinQuantity float64 `json:"min_quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+}
+
+// ListApprovedVendorsOptions represents options for listing approved vendors
+type ListApprovedVendorsOptions struct {
+	PartID     string `json:"part_id,omitempty"`
+	SupplierID string `json:"supplier_id,omitempty"`
+}
+
 // SearchOptions represents options for search operations
 type SearchOptions struct {
 	Query string
+	// Types restricts results to these entity types; empty searches all
+	Types []string
 	Sort  string
 	Order string
 	ListOptions
 }
 
+// Search result entity types
+const (
+	SearchTypePart          = "part"
+	SearchTypeOrder         = "order"
+	SearchTypeSupplier      = "supplier"
+	SearchTypeInventoryItem = "inventory_item"
+)
+
+// SearchResult is a single search hit tagged with its entity type
+type SearchResult struct {
+	Type string `json:"type"`
+	// Rank is the 1-based position of the hit in the full result set
+	Rank int `json:"rank"`
+	// Score is the relevance of the hit, for results from the local index
+	Score float64     `json:"score,omitempty"`
+	Item  interface{} `json:"item"`
+}
+
 // ABomItem represents an item in an ABOM
 type ABomItem struct {
-	ID       string `json:"id"`
-	PartID   string `json:"part_id"`
-	Quantity int    `json:"quantity"`
-	Unit     string `json:"unit,omitempty"`
-	Notes    string `json:"notes,omitempty"`
+	ID       string  `json:"id"`
+	PartID   string  `json:"part_id"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit,omitempty"`
+	Notes    string  `json:"notes,omitempty"`
+	// LotNumber and SerialNumber identify the stock consumed into the unit
+	LotNumber    string `json:"lot_number,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
 }
 
 // ABom represents an ABOM in First Resonance
@@ -129,15 +437,20 @@ type ABom struct {
 	CreatedAt   string     `json:"created_at,omitempty"`
 	UpdatedAt   string     `json:"updated_at,omitempty"`
 	Items       []ABomItem `json:"items,omitempty"`
+	// PartID and SerialNumber identify the unit the ABOM records the build of
+	PartID       string `json:"part_id,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
 }
 
 // ABomUpdateRequest represents a request to update an ABOM
 type ABomUpdateRequest struct {
-	Name        *string     `json:"name,omitempty"`
-	Description *string     `json:"description,omitempty"`
-	Version     *string     `json:"version,omitempty"`
-	Status      *string     `json:"status,omitempty"`
-	Items       *[]ABomItem `json:"items,omitempty"`
+	Name         *string     `json:"name,omitempty"`
+	PartID       *string     `json:"part_id,omitempty"`
+	SerialNumber *string     `json:"serial_number,omitempty"`
+	Description  *string     `json:"description,omitempty"`
+	Version      *string     `json:"version,omitempty"`
+	Status       *string     `json:"status,omitempty"`
+	Items        *[]ABomItem `json:"items,omitempty"`
 }
 
 // ListABomsOptions represents options for listing ABOMs
@@ -148,6 +461,261 @@ type ListABomsOptions struct {
 	ListOptions
 }
 
+// Directions of a genealogy trace
+const (
+	// GenealogyDown traces the lots and serials that went into a unit
+	GenealogyDown = "down"
+	// GenealogyUp traces the units a lot or serial went into
+	GenealogyUp = "up"
+)
+
+// TraceGenealogyOptions selects the start and direction of a genealogy trace.
+// A trace down starts from a serial number; a trace up starts from a serial
+// or lot number. PartID narrows the start to one part.
+type TraceGenealogyOptions struct {
+	SerialNumber string
+	LotNumber    string
+	PartID       string
+	Direction    string
+}
+
+// GenealogyNode is a unit, serial or lot in a genealogy tree. Tracing down,
+// a node's children are what was consumed into it; tracing up, they are the
+// units it was consumed into.
+type GenealogyNode struct {
+	PartID       string `json:"part_id,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	LotNumber    string `json:"lot_number,omitempty"`
+	// Quantity is how many were consumed into the parent (down) or into
+	// this unit (up); it is zero for the root
+	Quantity float64 `json:"quantity,omitempty"`
+	// ABomID is the as-built record of the node, if it is a built unit
+	ABomID   string           `json:"abom_id,omitempty"`
+	Children []*GenealogyNode `json:"children,omitempty"`
+}
+
+// AffectedUnit is a lot or unit in the scope of a genealogy trace
+type AffectedUnit struct {
+	PartID       string `json:"part_id,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	LotNumber    string `json:"lot_number,omitempty"`
+	ABomID       string `json:"abom_id,omitempty"`
+	// Depth is the number of levels between the unit and the root
+	Depth int `json:"depth"`
+}
+
+// Genealogy is the result of a genealogy trace, as a tree and as a flat list.
+// Tracing down, AffectedUnits holds every lot and serial that went into the
+// unit; tracing up, it holds every finished unit, one not consumed into
+// anything else, that contains the lot or serial.
+type Genealogy struct {
+	Direction     string          `json:"direction"`
+	Root          *GenealogyNode  `json:"root"`
+	AffectedUnits []*AffectedUnit `json:"affected_units"`
+}
+
+// Cost bases a part's cost can be taken on
+const (
+	CostBasisStandard = "standard"
+	CostBasisAverage  = "average"
+)
+
+// Sources of a part's standard cost
+const (
+	CostSourceTable     = "cost_table"
+	CostSourceLastOrder = "last_order"
+)
+
+// CostTableEntry is a part's standard cost in the cost table
+type CostTableEntry struct {
+	PartID       string  `json:"part_id"`
+	StandardCost float64 `json:"standard_cost"`
+	// Unit is the unit StandardCost is per, by default the part's base unit
+	Unit string `json:"unit,omitempty"`
+}
+
+// PartCost is what one of a part's base unit costs
+type PartCost struct {
+	PartID string `json:"part_id"`
+	// Unit is the part's base unit, which the costs are per
+	Unit string `json:"unit,omitempty"`
+	// StandardCost is the cost table's cost or else the unit price of the
+	// part's most recent order line; StandardSource says which
+	StandardCost   float64 `json:"standard_cost"`
+	StandardSource string  `json:"standard_source"`
+	// AverageCost is the mean unit price of the part's order lines, weighted
+	// by the quantity ordered, over OrderLines lines
+	AverageCost *float64 `json:"average_cost"`
+	OrderLines  int      `json:"order_lines"`
+}
+
+// CostRollupOptions selects the unit a cost roll-up starts from: an ABOM by
+// ID, a unit by serial number, or the most recently updated ABOM of a part
+type CostRollupOptions struct {
+	ABomID       string
+	SerialNumber string
+	PartID       string
+	CostBasis    string
+}
+
+// CostRollupNode is a unit or an ABOM item in a cost roll-up. A built unit's
+// cost is the sum of its children's; an item's is its quantity times its
+// part's unit cost.
+type CostRollupNode struct {
+	PartID       string `json:"part_id,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	LotNumber    string `json:"lot_number,omitempty"`
+	// Quantity is in Unit, the part's base unit; it is 1 for the root
+	Quantity float64  `json:"quantity"`
+	Unit     string   `json:"unit,omitempty"`
+	UnitCost *float64 `json:"unit_cost,omitempty"`
+	Cost     float64  `json:"cost"`
+	// ABomID is the as-built record of the node, if it is a built unit
+	ABomID string `json:"abom_id,omitempty"`
+	// Uncosted is set for an item whose part has no cost, so Cost leaves it out
+	Uncosted bool              `json:"uncosted,omitempty"`
+	Children []*CostRollupNode `json:"children,omitempty"`
+}
+
+// CostRollup is the material cost of a unit through its multi-level ABOM
+type CostRollup struct {
+	CostBasis    string          `json:"cost_basis"`
+	Root         *CostRollupNode `json:"root"`
+	MaterialCost float64         `json:"material_cost"`
+	// UncostedParts are the parts with no cost, which MaterialCost is missing
+	UncostedParts []string `json:"uncosted_parts"`
+}
+
+// InventoryValuationOptions represents options for valuing inventory
+type InventoryValuationOptions struct {
+	PartID    string
+	Location  string
+	CostBasis string
+}
+
+// ValuationLine is the on-hand stock of a part at a location and its value
+type ValuationLine struct {
+	PartID   string `json:"part_id"`
+	PartName string `json:"part_name,omitempty"`
+	Category string `json:"category"`
+	Location string `json:"location"`
+	// Quantity is in Unit, the part's base unit
+	Quantity float64  `json:"quantity"`
+	Unit     string   `json:"unit,omitempty"`
+	UnitCost *float64 `json:"unit_cost"`
+	Value    float64  `json:"value"`
+}
+
+// ValuationTotal is the on-hand value of a location or part category
+type ValuationTotal struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+	Lines int     `json:"lines"`
+}
+
+// InventoryValuation is the value of on-hand inventory, by location and by
+// part category, with a line per part and location
+type InventoryValuation struct {
+	CostBasis  string            `json:"cost_basis"`
+	TotalValue float64           `json:"total_value"`
+	ByLocation []*ValuationTotal `json:"by_location"`
+	ByCategory []*ValuationTotal `json:"by_category"`
+	Lines      []*ValuationLine  `json:"lines"`
+	// UncostedParts are the parts on hand with no cost, which TotalValue is
+	// missing
+	UncostedParts []string `json:"uncosted_parts"`
+}
+
+// ABC classes, by share of annual usage value
+const (
+	ABCClassA = "A"
+	ABCClassB = "B"
+	ABCClassC = "C"
+)
+
+// Movement statuses of stock on hand
+const (
+	MovementActive     = "active"
+	MovementSlowMoving = "slow_moving"
+	MovementDead       = "dead"
+	MovementUnknown    = "unknown"
+)
+
+// ABCAnalysisOptions represents options for an ABC analysis. Zero values
+// take the defaults.
+type ABCAnalysisOptions struct {
+	AsOf time.Time
+	// PeriodDays is how many days of consumption are annualized, default 365
+	PeriodDays int
+	// SlowDays and DeadDays are how many days without movement make stock
+	// slow moving or dead, default 90 and 365
+	SlowDays  int
+	DeadDays  int
+	CostBasis string
+	// AThreshold and BThreshold are the cumulative shares of annual usage
+	// value classes A and B cover, default 0.8 and 0.95
+	AThreshold float64
+	BThreshold float64
+}
+
+// ABCPart is a part's class, usage and movement in an ABC analysis
+type ABCPart struct {
+	PartID   string `json:"part_id"`
+	PartName string `json:"part_name,omitempty"`
+	Class    string `json:"class"`
+	// OnHand, Consumed and AnnualUsage are in Unit, the part's base unit.
+	// Consumed is the quantity consumed over the analysis period.
+	OnHand           float64  `json:"on_hand"`
+	Unit             string   `json:"unit,omitempty"`
+	UnitCost         *float64 `json:"unit_cost"`
+	OnHandValue      float64  `json:"on_hand_value"`
+	Consumed         float64  `json:"consumed"`
+	AnnualUsage      float64  `json:"annual_usage"`
+	AnnualUsageValue float64  `json:"annual_usage_value"`
+	// UsageShare is the part's share of the total annual usage value, and
+	// CumulativeShare the share of it and every part ranked above it
+	UsageShare      float64 `json:"usage_share"`
+	CumulativeShare float64 `json:"cumulative_share"`
+	// LastMovement is the date stock of the part last moved, if the audit
+	// log records it within the analysis window; DaysSinceMovement counts
+	// from it, or else from the start of the audit log or of the window,
+	// whichever is later
+	LastMovement      string `json:"last_movement,omitempty"`
+	DaysSinceMovement *int   `json:"days_since_movement,omitempty"`
+	// Movement is one of the Movement constants, for parts with stock on hand
+	Movement string `json:"movement,omitempty"`
+}
+
+// ABCClassSummary totals the parts of an ABC class
+type ABCClassSummary struct {
+	Class            string  `json:"class"`
+	Parts            int     `json:"parts"`
+	AnnualUsageValue float64 `json:"annual_usage_value"`
+	UsageShare       float64 `json:"usage_share"`
+	OnHandValue      float64 `json:"on_hand_value"`
+}
+
+// ABCAnalysis classifies parts by annual usage value and flags slow moving
+// and dead stock
+type ABCAnalysis struct {
+	AsOf       string             `json:"as_of"`
+	PeriodDays int                `json:"period_days"`
+	SlowDays   int                `json:"slow_days"`
+	DeadDays   int                `json:"dead_days"`
+	CostBasis  string             `json:"cost_basis"`
+	Classes    []*ABCClassSummary `json:"classes"`
+	// SlowMoving and Dead count the parts with stock on hand that have not
+	// moved for SlowDays and DeadDays, and the value of their stock
+	SlowMoving      int     `json:"slow_moving"`
+	SlowMovingValue float64 `json:"slow_moving_value"`
+	Dead            int     `json:"dead"`
+	DeadValue       float64 `json:"dead_value"`
+	// Parts are ranked by annual usage value, highest first
+	Parts []*ABCPart `json:"parts"`
+	// UncostedParts are the parts with no cost, which are ranked last
+	UncostedParts []string `json:"uncosted_parts"`
+}
+
 // PartsService handles operations on parts
 type PartsService struct {
 	client *Client
@@ -177,3 +745,43 @@ type SearchService struct {
 type ABomService struct {
 	client *Client
 }
+
+// AuditService handles access to the audit log of mutations
+type AuditService struct {
+	client *Client
+}
+
+// ReorderPointsService handles per-part reorder points kept in a local file
+type ReorderPointsService struct {
+	client *Client
+}
+
+// ApprovedVendorsService handles the approved vendor list, kept upstream or in a local file
+type ApprovedVendorsService struct {
+	client *Client
+}
+
+// UnitsService handles units of measure and their conversion
+type UnitsService struct {
+	client *Client
+}
+
+// CostsService handles part costs, from order line prices and a local cost table
+type CostsService struct {
+	client *Client
+}
+
+// ImportService handles bulk imports of parts, suppliers and inventory items
+type ImportService struct {
+	client *Client
+}
+
+// ExportService handles exports of full result sets to files
+type ExportService struct {
+	client *Client
+}
+
+// SearchIndexService handles the local full-text index of parts, suppliers and orders
+type SearchIndexService struct {
+	client *Client
+}
@@ -0,0 +1,114 @@
+package firstresonance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/uom"
+)
+
+// ListUnits creates a tool to list the known units of measure, or the units of a part.
+func ListUnits(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("list_units",
+			mcp.WithDescription(t("TOOL_LIST_UNITS_DESCRIPTION", "List the known units of measure with their dimensions and conversion factors, or a part's base unit, which its stock is counted in, and its alternate units")),
+			mcp.WithString("part_id",
+				mcp.Description("Part ID, to list the part's units"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			partID, err := OptionalParam[string](request, "part_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			var result interface{} = client.Units.List()
+			if partID != "" {
+				units, ok := client.Units.Part(partID)
+				if !ok {
+					return mcp.NewToolResultError(fmt.Sprintf("part %s has no base unit", partID)), nil
+				}
+				result = struct {
+					PartID string `json:"part_id"`
+					uom.PartUnits
+				}{partID, units}
+			}
+
+			r, err := json.Marshal(result)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
+
+// ConvertQuantity creates a tool to convert a quantity between units of measure.
+func ConvertQuantity(getClient GetClientFn, t TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("convert_quantity",
+			mcp.WithDescription(t("TOOL_CONVERT_QUANTITY_DESCRIPTION", "Convert a quantity between units of measure of the same dimension or, for a part, between the part's base and alternate units")),
+			mcp.WithNumber("quantity",
+				mcp.Required(),
+				mcp.Description("Quantity to convert"),
+			),
+			mcp.WithString("from_unit",
+				mcp.Description("Unit of the quantity; for a part, empty for its base unit"),
+			),
+			mcp.WithString("to_unit",
+				mcp.Description("Unit to convert to; for a part, empty for its base unit"),
+			),
+			mcp.WithString("part_id",
+				mcp.Description("Part ID, to use the part's base and alternate units"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			quantity, err := requiredParam[float64](request, "quantity")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			from, err := OptionalParam[string](request, "from_unit")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			to, err := OptionalParam[string](request, "to_unit")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			partID, err := OptionalParam[string](request, "part_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			client, err := getClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get First Resonance client: %w", err)
+			}
+			converted, err := client.Units.Convert(partID, quantity, from, to)
+			if isUnitError(err) {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert quantity: %w", err)
+			}
+
+			r, err := json.Marshal(map[string]interface{}{
+				"part_id":   partID,
+				"quantity":  converted,
+				"unit":      to,
+				"from":      quantity,
+				"from_unit": from,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response: %w", err)
+			}
+
+			return mcp.NewToolResultText(string(r)), nil
+		}
+}
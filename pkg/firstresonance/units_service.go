@@ -0,0 +1,110 @@
+package firstresonance
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/firstresonance/fr-mcp-server/pkg/uom"
+)
+
+// SetUnitsFile adds the units and per-part units read from a JSON file to
+// the default units of measure
+func (c *Client) SetUnitsFile(path string) error {
+	r, err := uom.Load(path)
+	if err != nil {
+		return err
+	}
+	c.units = r
+	return nil
+}
+
+// List returns the known units of measure, by dimension and then by size
+func (s *UnitsService) List() []uom.Unit {
+	units := append([]uom.Unit{}, s.client.units.Units...)
+	sort.SliceStable(units, func(i, j int) bool {
+		if units[i].Dimension != units[j].Dimension {
+			return units[i].Dimension < units[j].Dimension
+		}
+		return units[i].Factor < units[j].Factor
+	})
+	return units
+}
+
+// Part returns the base and alternate units of a part, if it has any
+func (s *UnitsService) Part(partID string) (uom.PartUnits, bool) {
+	return s.client.units.Part(partID)
+}
+
+// Convert converts a quantity between two units. With a part, either unit
+// may be one of the part's alternate units, or empty for its base unit.
+func (s *UnitsService) Convert(partID string, quantity float64, from, to string) (float64, error) {
+	if partID == "" {
+		return s.client.units.Convert(quantity, from, to)
+	}
+	return s.client.units.ConvertFor(partID, quantity, from, to)
+}
+
+// BaseQuantity converts a quantity of a part in a unit to the part's base
+// unit, rounded by the base unit's rules, and returns it with the base
+// unit's symbol. An empty unit is the base unit. For a part without units
+// the quantity is converted to the base unit of its unit's dimension, so
+// quantities of such a part can only be added together when their symbols
+// match.
+func (s *UnitsService) BaseQuantity(partID string, quantity float64, unit string) (float64, string, error) {
+	return s.client.units.ToBase(partID, quantity, unit)
+}
+
+// StockQuantity converts a quantity of a part in a unit to the unit its
+// stock is counted in, as BaseQuantity does, and returns it with that
+// unit's symbol. The stock of a part without units is counted in each, so a
+// quantity of such a part with no unit is in each, and one in any other
+// dimension, such as kilograms, is rejected with uom.ErrIncompatibleUnits.
+func (s *UnitsService) StockQuantity(partID string, quantity float64, unit string) (float64, string, error) {
+	q, symbol, err := s.BaseQuantity(partID, quantity, unit)
+	if err != nil {
+		return 0, "", err
+	}
+	if _, ok := s.Part(partID); ok {
+		return q, symbol, nil
+	}
+	if symbol == "" {
+		// Keep the quantity as given, since stock without a unit may have
+		// been counted in fractions
+		if each, ok := s.client.units.DimensionBase(uom.Count); ok {
+			symbol = each.Symbol
+		}
+		return q, symbol, nil
+	}
+	if u, err := s.client.units.Lookup(symbol); err != nil || u.Dimension != uom.Count {
+		return 0, "", fmt.Errorf("%w: part %s has no base unit, so its stock is counted in each, not %s; give it a base unit in the units file", uom.ErrIncompatibleUnits, partID, unit)
+	}
+	return q, symbol, nil
+}
+
+// round keeps a sum or difference of quantities of a part in its base unit
+// to the unit's precision, dropping floating point error
+func (s *UnitsService) round(partID string, quantity float64) float64 {
+	if rounded, _, err := s.BaseQuantity(partID, quantity, ""); err == nil {
+		return rounded
+	}
+	return quantity
+}
+
+// roundIn keeps a sum or difference of quantities in a unit, such as the
+// unit of an order line, to the unit's precision. Quantities in units the
+// registry does not know, such as a part's alternate units, are kept to
+// six decimal places.
+func (s *UnitsService) roundIn(unit string, quantity float64) float64 {
+	if rounded, err := s.client.units.Round(quantity, unit); err == nil {
+		return rounded
+	}
+	rounded, _ := s.client.units.Round(quantity, "")
+	return rounded
+}
+
+// isUnitError reports whether err is a unit of measure that is unknown,
+// incompatible or finer than the unit allows
+func isUnitError(err error) bool {
+	return errors.Is(err, uom.ErrUnknownUnit) || errors.Is(err, uom.ErrIncompatibleUnits) || errors.Is(err, uom.ErrInexactQuantity)
+}
@@ -0,0 +1,257 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapRows(t *testing.T) {
+	table, err := ReadCSV(strings.NewReader("\ufeffPart Name,Type,Notes\nFlange,mechanical,x\n,,\nBracket,,y\n"))
+	require.NoError(t, err)
+
+	t.Run("maps columns by mapping and by name", func(t *testing.T) {
+		rows, err := table.MapRows([]string{"name", "type", "status"}, map[string]string{"name": "part name"})
+
+		require.NoError(t, err)
+		require.Len(t, rows, 2, "blank rows are skipped")
+		assert.Equal(t, 2, rows[0].Line)
+		assert.Equal(t, map[string]string{"name": "Flange", "type": "mechanical"}, rows[0].Values)
+		assert.Equal(t, 4, rows[1].Line)
+		assert.Equal(t, map[string]string{"name": "Bracket"}, rows[1].Values)
+		assert.NotEqual(t, rows[0].Hash, rows[1].Hash)
+	})
+
+	t.Run("rejects unknown fields and missing columns", func(t *testing.T) {
+		_, err := table.MapRows([]string{"name"}, map[string]string{"color": "Notes"})
+		assert.ErrorContains(t, err, `unknown field "color"`)
+
+		_, err = table.MapRows([]string{"name"}, map[string]string{"name": "Title"})
+		assert.ErrorContains(t, err, `missing column "Title"`)
+	})
+}
+
+// buildXLSX builds a minimal workbook with one sheet using shared and inline strings
+func buildXLSX(t *testing.T) []byte {
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Parts" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>name</t></si><si><t>quantity</t></si><si><r><t>Flan</t></r><r><t>ge</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>` +
+			`<row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2"><v>12</v></c></row>` +
+			`<row r="3"><c r="A3" t="inlineStr"><is><t>Bracket</t></is></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestReadXLSX(t *testing.T) {
+	data := buildXLSX(t)
+
+	table, err := ReadXLSX(bytes.NewReader(data), int64(len(data)), "")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"name", "", "quantity"}, table.Header)
+	assert.Equal(t, [][]string{{"Flange", "", "12"}, {"Bracket"}}, table.Rows)
+
+	_, err = ReadXLSX(bytes.NewReader(data), int64(len(data)), "Suppliers")
+	assert.ErrorContains(t, err, `no worksheet "Suppliers"`)
+}
+
+func TestXLSXColumn(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    int
+		wantErr bool
+	}{
+		{ref: "A1", want: 0},
+		{ref: "AB12", want: 27},
+		{ref: "XFD1", want: 16383},
+		{ref: "XFE1", wantErr: true},
+		{ref: "ZZZZZZZZZZZZZZ1", wantErr: true},
+		{ref: "12", wantErr: true},
+		{ref: "AB", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.ref, func(t *testing.T) {
+			col, err := xlsxColumn(tc.ref)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, col)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	rows := make([]Row, 10)
+	for i := range rows {
+		rows[i] = Row{Line: i + 2, Values: map[string]string{"name": fmt.Sprintf("part %d", i)}, Hash: fmt.Sprintf("h%d", i)}
+	}
+	validate := func(apply func(Row) (string, error)) Validator {
+		return func(row Row) (*Op, error) {
+			if row.Values["name"] == "" {
+				return nil, errors.New("name is required")
+			}
+			return &Op{Action: ActionCreate, Apply: func(context.Context) (string, error) { return apply(row) }}, nil
+		}
+	}
+
+	t.Run("dry run validates without applying", func(t *testing.T) {
+		bad := append([]Row{{Line: 12, Values: map[string]string{}, Hash: "bad"}}, rows...)
+		report, err := Run(ctx, bad, validate(func(Row) (string, error) {
+			t.Fatal("applied in dry run")
+			return "", nil
+		}), Options{DryRun: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, 10, report.Valid)
+		assert.Equal(t, 1, report.Invalid)
+		assert.Equal(t, StatusInvalid, report.Results[0].Status)
+		assert.Equal(t, StatusValid, report.Results[1].Status)
+	})
+
+	t.Run("invalid rows stop the import", func(t *testing.T) {
+		bad := append([]Row{{Line: 12, Values: map[string]string{}, Hash: "bad"}}, rows...)
+		_, err := Run(ctx, bad, validate(func(Row) (string, error) {
+			t.Fatal("applied with invalid rows")
+			return "", nil
+		}), Options{ResultsPath: filepath.Join(t.TempDir(), "results.jsonl")})
+
+		assert.ErrorIs(t, err, ErrInvalidRows)
+	})
+
+	t.Run("applies in bounded batches and resumes after failures", func(t *testing.T) {
+		results := filepath.Join(t.TempDir(), "results.jsonl")
+		var running, peak atomic.Int32
+		var mu sync.Mutex
+		applied := map[int]int{}
+		apply := func(fail bool) func(Row) (string, error) {
+			return func(row Row) (string, error) {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				mu.Lock()
+				applied[row.Line]++
+				mu.Unlock()
+				if fail && row.Line%3 == 0 {
+					return "", errors.New("boom")
+				}
+				return fmt.Sprintf("id-%d", row.Line), nil
+			}
+		}
+
+		report, err := Run(ctx, rows, validate(apply(true)), Options{Concurrency: 2, BatchSize: 4, ResultsPath: results})
+		require.NoError(t, err)
+		assert.Equal(t, 7, report.Created)
+		assert.Equal(t, 3, report.Failed)
+		assert.LessOrEqual(t, peak.Load(), int32(2))
+
+		report, err = Run(ctx, rows, validate(apply(false)), Options{ResultsPath: results})
+		require.NoError(t, err)
+		assert.Equal(t, 7, report.Skipped)
+		assert.Equal(t, 3, report.Created)
+		assert.Equal(t, 0, report.Failed)
+		for line, n := range applied {
+			if line%3 == 0 {
+				assert.Equal(t, 2, n, "failed line %d retried", line)
+			} else {
+				assert.Equal(t, 1, n, "done line %d not repeated", line)
+			}
+		}
+	})
+
+	t.Run("rows applied with a warning are done", func(t *testing.T) {
+		results := filepath.Join(t.TempDir(), "results.jsonl")
+		var applied atomic.Int32
+		apply := func(row Row) (string, error) {
+			applied.Add(1)
+			if row.Line == 2 {
+				return "id-2", &Warning{Err: errors.New("not audited")}
+			}
+			return fmt.Sprintf("id-%d", row.Line), nil
+		}
+
+		report, err := Run(ctx, rows, validate(apply), Options{ResultsPath: results})
+		require.NoError(t, err)
+		assert.Equal(t, 10, report.Created)
+		assert.Equal(t, 0, report.Failed)
+		assert.Equal(t, 1, report.Warnings)
+		require.Len(t, report.Results, 1)
+		assert.Equal(t, StatusDone, report.Results[0].Status)
+		assert.Equal(t, "id-2", report.Results[0].ID)
+		assert.Equal(t, "not audited", report.Results[0].Warning)
+
+		report, err = Run(ctx, rows, validate(apply), Options{ResultsPath: results})
+		require.NoError(t, err)
+		assert.Equal(t, 10, report.Skipped)
+		assert.Equal(t, int32(10), applied.Load(), "no row is applied twice")
+	})
+
+	t.Run("results are written as rows are applied", func(t *testing.T) {
+		results := filepath.Join(t.TempDir(), "results.jsonl")
+		apply := func(row Row) (string, error) {
+			if row.Line == 6 {
+				done, err := readDone(results)
+				require.NoError(t, err)
+				assert.Len(t, done, 4, "rows before this one in the batch are already recorded")
+			}
+			return fmt.Sprintf("id-%d", row.Line), nil
+		}
+
+		report, err := Run(ctx, rows, validate(apply), Options{Concurrency: 1, BatchSize: 10, ResultsPath: results})
+		require.NoError(t, err)
+		assert.Equal(t, 10, report.Created)
+	})
+
+	t.Run("refuses to write results over the input file", func(t *testing.T) {
+		dir := t.TempDir()
+		input := filepath.Join(dir, "parts.csv")
+		require.NoError(t, os.WriteFile(input, []byte("name\n"), 0600))
+		require.NoError(t, os.Symlink(input, filepath.Join(dir, "link.csv")))
+		apply := func(Row) (string, error) {
+			t.Fatal("applied with the input file as results file")
+			return "", nil
+		}
+
+		for _, results := range []string{input, filepath.Join(dir, ".", "parts.csv"), filepath.Join(dir, "link.csv")} {
+			_, err := Run(ctx, rows, validate(apply), Options{ResultsPath: results, InputPath: input})
+			assert.ErrorIs(t, err, ErrResultsPath, results)
+		}
+		content, err := os.ReadFile(input)
+		require.NoError(t, err)
+		assert.Equal(t, "name\n", string(content))
+	})
+}
@@ -0,0 +1,302 @@
+package importer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Row actions
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+)
+
+// Row statuses
+const (
+	// StatusValid is reported for valid rows in a dry run
+	StatusValid   = "valid"
+	StatusInvalid = "invalid"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+	// StatusSkipped is reported for rows already done by an earlier run
+	StatusSkipped = "skipped"
+)
+
+// Defaults for running an import
+const (
+	DefaultConcurrency = 4
+	DefaultBatchSize   = 50
+)
+
+// Op is the change a valid row makes
+type Op struct {
+	Action string
+	// Apply makes the change, returning the ID of the created or updated record
+	Apply func(ctx context.Context) (string, error)
+}
+
+// Warning wraps an error Apply returns after making its change, such as a
+// failure to record the change elsewhere. The row is done, so a rerun does
+// not repeat it, and the error is reported as a warning.
+type Warning struct {
+	Err error
+}
+
+func (w *Warning) Error() string {
+	return w.Err.Error()
+}
+
+func (w *Warning) Unwrap() error {
+	return w.Err
+}
+
+// Validator checks a row and returns the change it makes
+type Validator func(row Row) (*Op, error)
+
+// Options controls an import
+type Options struct {
+	// DryRun validates every row without applying any
+	DryRun bool
+	// Concurrency is how many rows are applied at once
+	Concurrency int
+	// BatchSize is how many rows are applied before the import checks
+	// whether it was cancelled
+	BatchSize int
+	// ResultsPath is the JSON Lines file the result of every applied row is
+	// appended to as soon as the row is applied. Rows it records as done are
+	// skipped, so rerunning an import with the same file resumes it.
+	// Required unless DryRun is set.
+	ResultsPath string
+	// InputPath is the file the rows were read from, if any, which the
+	// results file may not be
+	InputPath string
+}
+
+// Result is the outcome of a row
+type Result struct {
+	Line   int    `json:"line"`
+	Hash   string `json:"hash"`
+	Action string `json:"action,omitempty"`
+	Status string `json:"status"`
+	ID     string `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// Warning is set for a row that was applied with a Warning
+	Warning string    `json:"warning,omitempty"`
+	Time    time.Time `json:"time,omitempty"`
+}
+
+// Report summarizes an import
+type Report struct {
+	DryRun  bool `json:"dry_run"`
+	Total   int  `json:"total"`
+	Valid   int  `json:"valid"`
+	Invalid int  `json:"invalid"`
+	Created int  `json:"created"`
+	Updated int  `json:"updated"`
+	Failed  int  `json:"failed"`
+	Skipped int  `json:"skipped"`
+	// Warnings counts the rows applied with a warning
+	Warnings int `json:"warnings"`
+	// Results holds a result for every row in a dry run, and otherwise for
+	// every row that was not applied successfully or was applied with a
+	// warning
+	Results []*Result `json:"results"`
+}
+
+// ErrInvalidRows is returned when an import is not run because rows are invalid
+var ErrInvalidRows = errors.New("rows are invalid")
+
+// ErrResultsPath is returned when an import has no results file to write, or
+// one that is its input file
+var ErrResultsPath = errors.New("invalid results file")
+
+// Run validates every row and, unless opts.DryRun is set and if every row is
+// valid, applies them in batches of opts.BatchSize with up to
+// opts.Concurrency at a time. A failed row does not stop the others.
+func Run(ctx context.Context, rows []Row, validate Validator, opts Options) (*Report, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if !opts.DryRun {
+		if opts.ResultsPath == "" {
+			return nil, fmt.Errorf("%w: a results file is required", ErrResultsPath)
+		}
+		if opts.InputPath != "" && sameFile(opts.InputPath, opts.ResultsPath) {
+			return nil, fmt.Errorf("%w: %s is the input file", ErrResultsPath, opts.ResultsPath)
+		}
+	}
+
+	report := &Report{DryRun: opts.DryRun, Total: len(rows), Results: []*Result{}}
+
+	done := map[int]string{}
+	if !opts.DryRun {
+		var err error
+		if done, err = readDone(opts.ResultsPath); err != nil {
+			return nil, err
+		}
+	}
+
+	type pending struct {
+		row Row
+		op  *Op
+	}
+	var todo []pending
+	for _, row := range rows {
+		if hash, ok := done[row.Line]; ok && hash == row.Hash {
+			report.Skipped++
+			continue
+		}
+		op, err := validate(row)
+		if err != nil {
+			report.Invalid++
+			report.Results = append(report.Results, &Result{Line: row.Line, Hash: row.Hash, Status: StatusInvalid, Error: err.Error()})
+			continue
+		}
+		report.Valid++
+		if opts.DryRun {
+			report.Results = append(report.Results, &Result{Line: row.Line, Hash: row.Hash, Action: op.Action, Status: StatusValid})
+		}
+		todo = append(todo, pending{row: row, op: op})
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+	if report.Invalid > 0 {
+		return report, fmt.Errorf("%w: %d of %d, nothing was imported", ErrInvalidRows, report.Invalid, report.Total)
+	}
+
+	out, err := os.OpenFile(opts.ResultsPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results file: %w", err)
+	}
+	defer func() { _ = out.Close() }()
+
+	// Each result is written as soon as its row is applied, so a crash
+	// mid-batch loses no record of rows already changed
+	var mu sync.Mutex
+	var writeErr error
+	for start := 0; start < len(todo); start += opts.BatchSize {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		batch := todo[start:min(start+opts.BatchSize, len(todo))]
+
+		results := make([]*Result, len(batch))
+		sem := make(chan struct{}, opts.Concurrency)
+		var wg sync.WaitGroup
+		for i, p := range batch {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				result := &Result{Line: p.row.Line, Hash: p.row.Hash, Action: p.op.Action, Status: StatusDone}
+				id, err := p.op.Apply(ctx)
+				result.ID, result.Time = id, time.Now().UTC()
+				var warning *Warning
+				if errors.As(err, &warning) {
+					result.Warning = err.Error()
+				} else if err != nil {
+					result.Status, result.Error = StatusFailed, err.Error()
+				}
+				results[i] = result
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err := writeResult(out, result); err != nil && writeErr == nil {
+					writeErr = err
+				}
+			}()
+		}
+		wg.Wait()
+
+		if writeErr != nil {
+			return report, writeErr
+		}
+		for _, r := range results {
+			switch {
+			case r.Status == StatusFailed:
+				report.Failed++
+				report.Results = append(report.Results, r)
+				continue
+			case r.Action == ActionCreate:
+				report.Created++
+			default:
+				report.Updated++
+			}
+			if r.Warning != "" {
+				report.Warnings++
+				report.Results = append(report.Results, r)
+			}
+		}
+	}
+	return report, nil
+}
+
+// writeResult appends a result to the results file and syncs it
+func writeResult(out *os.File, result *Result) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	if _, err := out.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write results file: %w", err)
+	}
+	if err := out.Sync(); err != nil {
+		return fmt.Errorf("failed to sync results file: %w", err)
+	}
+	return nil
+}
+
+// sameFile reports whether two paths name the same file, following links,
+// or the same path if either does not exist yet
+func sameFile(a, b string) bool {
+	ai, aErr := os.Stat(a)
+	bi, bErr := os.Stat(b)
+	if aErr == nil && bErr == nil {
+		return os.SameFile(ai, bi)
+	}
+	absA, aErr := filepath.Abs(a)
+	absB, bErr := filepath.Abs(b)
+	return aErr == nil && bErr == nil && absA == absB
+}
+
+// readDone returns the hash of every line a results file records as done.
+// A missing file records nothing.
+func readDone(path string) (map[int]string, error) {
+	done := map[int]string{}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Result
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			// A partially written last line from an interrupted run
+			continue
+		}
+		if r.Status == StatusDone {
+			done[r.Line] = r.Hash
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read results file: %w", err)
+	}
+	return done, nil
+}
@@ -0,0 +1,321 @@
+// Package importer reads tabular files and applies their rows in
+// bounded-concurrency batches, recording a per-row result file so that an
+// interrupted import can be resumed.
+package importer
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Table is the header and rows of a file
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Row is a row of a table with its values keyed by field name
+type Row struct {
+	// Line is the 1-based line of the row in the file, counting the header
+	Line   int
+	Values map[string]string
+	// Hash identifies the row's contents, so a resumed import can tell if a row changed
+	Hash string
+}
+
+// ReadFile reads a CSV or XLSX file, chosen by extension. For XLSX, sheet
+// names the worksheet to read; the first is read if it is empty.
+func ReadFile(filename, sheet string) (*Table, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filename, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return ReadCSV(f)
+	case ".xlsx":
+		info, err := f.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", filename, err)
+		}
+		return ReadXLSX(f, info.Size(), sheet)
+	default:
+		return nil, fmt.Errorf("unsupported file type %q, expected .csv or .xlsx", filepath.Ext(filename))
+	}
+}
+
+// ReadCSV reads a CSV file whose first record is the header
+func ReadCSV(r io.Reader) (*Table, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	return newTable(records)
+}
+
+// newTable splits records into a header and rows
+func newTable(records [][]string) (*Table, error) {
+	if len(records) == 0 {
+		return nil, errors.New("file has no header row")
+	}
+	t := &Table{Header: records[0], Rows: records[1:]}
+	for i := range t.Header {
+		t.Header[i] = strings.TrimSpace(strings.TrimPrefix(t.Header[i], "\ufeff"))
+	}
+	return t, nil
+}
+
+// MapRows maps the table's columns to fields. mapping gives the column for a
+// field; fields it does not mention are read from the column of the same
+// name, ignoring case, if there is one. Blank rows are skipped.
+func (t *Table) MapRows(fields []string, mapping map[string]string) ([]Row, error) {
+	columns := map[string]int{}
+	for i, h := range t.Header {
+		columns[strings.ToLower(h)] = i
+	}
+
+	known := map[string]bool{}
+	for _, f := range fields {
+		known[f] = true
+	}
+	index := map[string]int{}
+	for field, column := range mapping {
+		if !known[field] {
+			return nil, fmt.Errorf("mapping for unknown field %q, expected one of: %s", field, strings.Join(fields, ", "))
+		}
+		i, ok := columns[strings.ToLower(column)]
+		if !ok {
+			return nil, fmt.Errorf("mapping for %s names missing column %q", field, column)
+		}
+		index[field] = i
+	}
+	for _, f := range fields {
+		if _, ok := index[f]; ok {
+			continue
+		}
+		if i, ok := columns[strings.ToLower(f)]; ok {
+			index[f] = i
+		}
+	}
+	if len(index) == 0 {
+		return nil, fmt.Errorf("no columns map to fields %s", strings.Join(fields, ", "))
+	}
+
+	var rows []Row
+	for n, record := range t.Rows {
+		row := Row{Line: n + 2, Values: map[string]string{}}
+		blank := true
+		for field, i := range index {
+			if i < len(record) {
+				if v := strings.TrimSpace(record[i]); v != "" {
+					row.Values[field] = v
+					blank = false
+				}
+			}
+		}
+		if blank {
+			continue
+		}
+		row.Hash = hashValues(row.Values)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// hashValues hashes a row's values in field order
+func hashValues(values map[string]string) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%q\n", k, values[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// ReadXLSX reads a worksheet of an XLSX workbook whose first row is the header
+func ReadXLSX(r io.ReaderAt, size int64, sheet string) (*Table, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open XLSX: %w", err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := xlsxSheetPath(files, sheet)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := xlsxSharedStrings(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var ws struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := readXML(files, sheetPath, &ws); err != nil {
+		return nil, err
+	}
+
+	var records [][]string
+	for _, row := range ws.Rows {
+		var record []string
+		for i, c := range row.Cells {
+			col := i
+			if c.Ref != "" {
+				if col, err = xlsxColumn(c.Ref); err != nil {
+					return nil, err
+				}
+			} else if col >= maxXLSXColumns {
+				return nil, fmt.Errorf("row has more than %d cells", maxXLSXColumns)
+			}
+			for len(record) <= col {
+				record = append(record, "")
+			}
+			switch c.Type {
+			case "s":
+				n, err := strconv.Atoi(c.Value)
+				if err != nil || n < 0 || n >= len(shared) {
+					return nil, fmt.Errorf("cell %s refers to a missing shared string", c.Ref)
+				}
+				record[col] = shared[n]
+			case "inlineStr":
+				record[col] = c.Inline
+			default:
+				record[col] = c.Value
+			}
+		}
+		records = append(records, record)
+	}
+	return newTable(records)
+}
+
+// xlsxSheetPath returns the path in the archive of the named worksheet, or the first
+func xlsxSheetPath(files map[string]*zip.File, sheet string) (string, error) {
+	var wb struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := readXML(files, "xl/workbook.xml", &wb); err != nil {
+		return "", err
+	}
+	var rels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := readXML(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return "", err
+	}
+
+	for _, s := range wb.Sheets {
+		if sheet != "" && s.Name != sheet {
+			continue
+		}
+		for _, rel := range rels.Rels {
+			if rel.ID == s.RID {
+				if strings.HasPrefix(rel.Target, "/") {
+					return strings.TrimPrefix(rel.Target, "/"), nil
+				}
+				return path.Join("xl", rel.Target), nil
+			}
+		}
+		return "", fmt.Errorf("worksheet %q has no part in the workbook", s.Name)
+	}
+	if sheet != "" {
+		return "", fmt.Errorf("workbook has no worksheet %q", sheet)
+	}
+	return "", errors.New("workbook has no worksheets")
+}
+
+// xlsxSharedStrings reads the workbook's shared string table, if it has one
+func xlsxSharedStrings(files map[string]*zip.File) ([]string, error) {
+	if files["xl/sharedStrings.xml"] == nil {
+		return nil, nil
+	}
+	var sst struct {
+		Items []struct {
+			Text string   `xml:"t"`
+			Runs []string `xml:"r>t"`
+		} `xml:"si"`
+	}
+	if err := readXML(files, "xl/sharedStrings.xml", &sst); err != nil {
+		return nil, err
+	}
+	strs := make([]string, len(sst.Items))
+	for i, si := range sst.Items {
+		strs[i] = si.Text + strings.Join(si.Runs, "")
+	}
+	return strs, nil
+}
+
+// maxXLSXColumns is the number of columns in a worksheet, up to XFD
+const maxXLSXColumns = 16384
+
+// xlsxColumn returns the 0-based column of a cell reference such as "AB12"
+func xlsxColumn(ref string) (int, error) {
+	col := 0
+	for i, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			if col = col*26 + int(r-'A'+1); col > maxXLSXColumns {
+				return 0, fmt.Errorf("cell reference %q is past the last column XFD", ref)
+			}
+			continue
+		}
+		if i == 0 {
+			break
+		}
+		return col - 1, nil
+	}
+	return 0, fmt.Errorf("invalid cell reference %q", ref)
+}
+
+// readXML decodes an XML file in the archive
+func readXML(files map[string]*zip.File, name string, v interface{}) error {
+	f := files[name]
+	if f == nil {
+		return fmt.Errorf("XLSX is missing %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer func() { _ = rc.Close() }()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
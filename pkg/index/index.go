@@ -0,0 +1,316 @@
+// Package index provides an in-process full-text index with BM25 ranking,
+// prefix search and typo-tolerant fuzzy matching.
+package index
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Weights applied to terms that only match a query term by prefix or within an edit distance
+const (
+	prefixWeight = 0.8
+	fuzzyWeight  = 0.6
+)
+
+// Document is a record in the index
+type Document struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	// Text is the searchable text of the document, by field
+	Text map[string]string `json:"text"`
+	// Item is the record itself, returned with search results
+	Item json.RawMessage `json:"item,omitempty"`
+}
+
+// key identifies a document across types
+func (d *Document) key() string {
+	return d.Type + "/" + d.ID
+}
+
+// Result is a document matching a search
+type Result struct {
+	Document *Document
+	Score    float64
+}
+
+// SearchOptions controls a search
+type SearchOptions struct {
+	// Types restricts results to these document types; empty searches all
+	Types []string
+	// Limit caps the number of results; 0 means no limit
+	Limit int
+}
+
+// Index is an inverted index of documents. It is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*Document
+	postings map[string]map[string]int // term -> document key -> term frequency
+	lengths  map[string]int            // document key -> number of terms
+	totalLen int
+	builtAt  time.Time
+}
+
+// New creates an empty index
+func New() *Index {
+	return &Index{
+		docs:     map[string]*Document{},
+		postings: map[string]map[string]int{},
+		lengths:  map[string]int{},
+	}
+}
+
+// Replace atomically replaces the contents of the index with docs
+func (ix *Index) Replace(docs []*Document, builtAt time.Time) {
+	next := New()
+	for _, doc := range docs {
+		next.add(doc)
+	}
+	next.builtAt = builtAt
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.docs, ix.postings, ix.lengths, ix.totalLen, ix.builtAt = next.docs, next.postings, next.lengths, next.totalLen, next.builtAt
+}
+
+// add indexes a document, which must not already be in the index
+func (ix *Index) add(doc *Document) {
+	key := doc.key()
+	if _, ok := ix.docs[key]; ok {
+		return
+	}
+	ix.docs[key] = doc
+
+	n := 0
+	for _, text := range doc.Text {
+		for _, term := range Tokenize(text) {
+			if ix.postings[term] == nil {
+				ix.postings[term] = map[string]int{}
+			}
+			ix.postings[term][key]++
+			n++
+		}
+	}
+	ix.lengths[key] = n
+	ix.totalLen += n
+}
+
+// Len returns the number of documents in the index
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// BuiltAt returns when the contents of the index were built
+func (ix *Index) BuiltAt() time.Time {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.builtAt
+}
+
+// Search returns the documents matching query, best first. Each query term
+// matches index terms exactly, by prefix, or within a small edit distance;
+// a document's score is the sum over query terms of the BM25 score of its
+// best matching term, discounted for inexact matches.
+func (ix *Index) Search(query string, opts *SearchOptions) []*Result {
+	if opts == nil {
+		opts = &SearchOptions{}
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	if len(ix.docs) == 0 {
+		return []*Result{}
+	}
+	avgLen := float64(ix.totalLen) / float64(len(ix.docs))
+
+	scores := map[string]float64{}
+	for _, q := range Tokenize(query) {
+		best := map[string]float64{}
+		for term, weight := range ix.expand(q) {
+			postings := ix.postings[term]
+			idf := math.Log(1 + (float64(len(ix.docs))-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+			for key, tf := range postings {
+				norm := bm25K1 * (1 - bm25B + bm25B*float64(ix.lengths[key])/avgLen)
+				score := weight * idf * float64(tf) * (bm25K1 + 1) / (float64(tf) + norm)
+				best[key] = max(best[key], score)
+			}
+		}
+		for key, score := range best {
+			scores[key] += score
+		}
+	}
+
+	results := []*Result{}
+	for key, score := range scores {
+		doc := ix.docs[key]
+		if len(opts.Types) > 0 && !slices.Contains(opts.Types, doc.Type) {
+			continue
+		}
+		results = append(results, &Result{Document: doc, Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Document.key() < results[j].Document.key()
+	})
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results
+}
+
+// expand returns the index terms matching a query term, with their weights
+func (ix *Index) expand(q string) map[string]float64 {
+	terms := map[string]float64{}
+	if _, ok := ix.postings[q]; ok {
+		terms[q] = 1
+	}
+
+	maxEdits := maxEdits(q)
+	for term := range ix.postings {
+		if term == q {
+			continue
+		}
+		weight := 0.0
+		if len([]rune(q)) >= 2 && strings.HasPrefix(term, q) {
+			weight = prefixWeight
+		}
+		if maxEdits > 0 && weight < fuzzyWeight {
+			if d := editDistance(q, term, maxEdits); d <= maxEdits {
+				weight = fuzzyWeight / float64(d)
+			}
+		}
+		if weight > 0 {
+			terms[term] = weight
+		}
+	}
+	return terms
+}
+
+// maxEdits is the number of typos tolerated in a query term, growing with its length
+func maxEdits(q string) int {
+	switch n := len([]rune(q)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance returns the Levenshtein distance between a and b, or a value
+// greater than limit as soon as the distance is known to exceed it.
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return limit + 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// Tokenize splits text into lowercase terms of letters and digits
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// snapshot is the on-disk form of an index
+type snapshot struct {
+	BuiltAt   time.Time   `json:"built_at"`
+	Documents []*Document `json:"documents"`
+}
+
+// Save writes the documents of the index to path, replacing it atomically
+func (ix *Index) Save(path string) error {
+	ix.mu.RLock()
+	snap := snapshot{BuiltAt: ix.builtAt, Documents: make([]*Document, 0, len(ix.docs))}
+	for _, doc := range ix.docs {
+		snap.Documents = append(snap.Documents, doc)
+	}
+	ix.mu.RUnlock()
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create index file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write index file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write index file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace index file: %w", err)
+	}
+	return nil
+}
+
+// Load reads an index saved with Save. A missing file gives an empty index.
+func Load(path string) (*Index, error) {
+	ix := New()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ix, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index file: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to parse index file: %w", err)
+	}
+	ix.Replace(snap.Documents, snap.BuiltAt)
+	return ix, nil
+}
@@ -0,0 +1,105 @@
+package index
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDocuments() []*Document {
+	return []*Document{
+		{Type: "part", ID: "p1", Text: map[string]string{"name": "Flange 6061-T6 aluminum", "description": "Mounting flange"}},
+		{Type: "part", ID: "p2", Text: map[string]string{"name": "Flange 304 stainless"}},
+		{Type: "part", ID: "p3", Text: map[string]string{"name": "Bracket 6061 aluminum"}},
+		{Type: "supplier", ID: "s1", Text: map[string]string{"name": "Acme Flange Works"}},
+	}
+}
+
+func resultIDs(results []*Result) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.Document.ID
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	ix := New()
+	ix.Replace(testDocuments(), time.Now())
+
+	tests := []struct {
+		name  string
+		query string
+		opts  *SearchOptions
+		want  []string
+	}{
+		{
+			name:  "all terms rank highest",
+			query: "flange 6061",
+			want:  []string{"p1", "p3", "p2", "s1"},
+		},
+		{
+			name:  "misspelled terms match",
+			query: "flamge 6061",
+			want:  []string{"p1", "p3", "p2", "s1"},
+		},
+		{
+			name:  "prefix matches, shorter documents first",
+			query: "alum",
+			want:  []string{"p3", "p1"},
+		},
+		{
+			name:  "short terms must match exactly",
+			query: "305",
+			want:  []string{},
+		},
+		{
+			name:  "type filter and limit",
+			query: "flange",
+			opts:  &SearchOptions{Types: []string{"part"}, Limit: 1},
+			want:  []string{"p1"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ix.Search(tc.query, tc.opts)
+			assert.Equal(t, tc.want, resultIDs(got))
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("flange", "flange", 2))
+	assert.Equal(t, 1, editDistance("flange", "flamge", 2))
+	assert.Equal(t, 1, editDistance("flange", "flanges", 2))
+	assert.Equal(t, 2, editDistance("flange", "falnge", 2))
+	assert.Equal(t, 2, editDistance("flange", "bracket", 1), "stops once the limit is exceeded")
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+
+	t.Run("Load on missing file returns an empty index", func(t *testing.T) {
+		ix, err := Load(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, ix.Len())
+	})
+
+	t.Run("Save and load round trip", func(t *testing.T) {
+		builtAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		ix := New()
+		ix.Replace(testDocuments(), builtAt)
+		require.NoError(t, ix.Save(path))
+
+		loaded, err := Load(path)
+
+		require.NoError(t, err)
+		assert.Equal(t, 4, loaded.Len())
+		assert.True(t, builtAt.Equal(loaded.BuiltAt()))
+		assert.Equal(t, resultIDs(ix.Search("flange 6061", nil)), resultIDs(loaded.Search("flange 6061", nil)))
+	})
+}
@@ -0,0 +1,258 @@
+// Package lifecycle enforces a configurable state machine over records that
+// have a status, such as orders.
+//
+// A lifecycle names its states, the fields a record must have to enter each
+// state, and the transitions allowed between them. A transition may also
+// name guards: checks, supplied by the caller, that the record must pass
+// before it can move.
+package lifecycle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// State is a state of a lifecycle
+type State struct {
+	// Requires are the fields a record must have set to enter the state
+	Requires []string `json:"requires,omitempty"`
+	// Final states have no transitions out of them, and their records can't
+	// be edited
+	Final bool `json:"final,omitempty"`
+	// Locked states keep their records from being edited, but not from
+	// moving out of them
+	Locked bool `json:"locked,omitempty"`
+}
+
+// Transition is an allowed move between two states
+type Transition struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Guards name the checks a record must pass to make the move
+	Guards []string `json:"guards,omitempty"`
+}
+
+// Lifecycle is a state machine of statuses
+type Lifecycle struct {
+	// Initial is the state of records without a status
+	Initial     string           `json:"initial"`
+	States      map[string]State `json:"states"`
+	Transitions []Transition     `json:"transitions"`
+}
+
+// Guard checks whether a record may make a transition, returning the reason
+// it may not, or "" if it may
+type Guard func(record map[string]interface{}) string
+
+// Guards are the guards transitions can name, by name
+type Guards map[string]Guard
+
+// Move is a transition out of a record's current state and whether the
+// record can make it
+type Move struct {
+	To      string `json:"to"`
+	Allowed bool   `json:"allowed"`
+	// Blockers are the reasons the record cannot make the move
+	Blockers []string `json:"blockers,omitempty"`
+}
+
+// ErrNotAllowed is returned when a record cannot make a transition
+var ErrNotAllowed = errors.New("transition not allowed")
+
+// Load reads a lifecycle from a JSON file and validates it against guards
+func Load(path string, guards Guards) (*Lifecycle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lifecycle: %w", err)
+	}
+	var l Lifecycle
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("failed to parse lifecycle: %w", err)
+	}
+	if err := l.Validate(guards); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// Validate checks that the initial state and every state a transition
+// names are defined, that no transition leaves a final state, and that every
+// guard is known
+func (l *Lifecycle) Validate(guards Guards) error {
+	var problems []string
+	if _, ok := l.States[l.Initial]; !ok {
+		problems = append(problems, fmt.Sprintf("initial state %q is not defined", l.Initial))
+	}
+	for _, t := range l.Transitions {
+		from, ok := l.States[t.From]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("transition %s → %s: state %q is not defined", t.From, t.To, t.From))
+		} else if from.Final {
+			problems = append(problems, fmt.Sprintf("transition %s → %s: %q is final", t.From, t.To, t.From))
+		}
+		if _, ok := l.States[t.To]; !ok {
+			problems = append(problems, fmt.Sprintf("transition %s → %s: state %q is not defined", t.From, t.To, t.To))
+		}
+		for _, g := range t.Guards {
+			if _, ok := guards[g]; !ok {
+				problems = append(problems, fmt.Sprintf("transition %s → %s: unknown guard %q", t.From, t.To, g))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid lifecycle: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Current returns a record's state given its status, which is the initial
+// state if the status is empty
+func (l *Lifecycle) Current(status string) (string, error) {
+	if status == "" {
+		return l.Initial, nil
+	}
+	if _, ok := l.States[status]; !ok {
+		return "", fmt.Errorf("status %q is not a state of the lifecycle, expected one of %s", status, strings.Join(l.stateNames(), ", "))
+	}
+	return status, nil
+}
+
+// Moves returns every transition out of the state a record's status is in,
+// and whether the record can make each
+func (l *Lifecycle) Moves(status string, record map[string]interface{}, guards Guards) ([]Move, error) {
+	from, err := l.Current(status)
+	if err != nil {
+		return nil, err
+	}
+
+	moves := []Move{}
+	for _, t := range l.Transitions {
+		if t.From != from {
+			continue
+		}
+		blockers := l.blockers(t, record, guards)
+		moves = append(moves, Move{To: t.To, Allowed: len(blockers) == 0, Blockers: blockers})
+	}
+	return moves, nil
+}
+
+// Check returns an error wrapping ErrNotAllowed if a record cannot move from
+// the state its status is in to the state to. Staying in the same state is
+// always allowed.
+func (l *Lifecycle) Check(status, to string, record map[string]interface{}, guards Guards) error {
+	from, err := l.Current(status)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNotAllowed, err)
+	}
+	if from == to {
+		return nil
+	}
+
+	var allowed []string
+	for _, t := range l.Transitions {
+		if t.From != from {
+			continue
+		}
+		if t.To == to {
+			if blockers := l.blockers(t, record, guards); len(blockers) > 0 {
+				return fmt.Errorf("%w: %s → %s: %s", ErrNotAllowed, from, to, strings.Join(blockers, "; "))
+			}
+			return nil
+		}
+		allowed = append(allowed, t.To)
+	}
+	if len(allowed) == 0 {
+		return fmt.Errorf("%w: %s → %s: no transitions out of %s", ErrNotAllowed, from, to, from)
+	}
+	return fmt.Errorf("%w: %s → %s: from %s a record can move to %s", ErrNotAllowed, from, to, from, strings.Join(allowed, ", "))
+}
+
+// CheckEdit returns an error wrapping ErrNotAllowed if a record in the state
+// its status is in can't be edited to record: the state must be neither
+// final nor locked, and the edited record must keep the fields the state
+// requires
+func (l *Lifecycle) CheckEdit(status string, record map[string]interface{}) error {
+	state, err := l.Current(status)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNotAllowed, err)
+	}
+	if err := l.CheckEditable(state); err != nil {
+		return err
+	}
+	var missing []string
+	for _, field := range l.States[state].Requires {
+		if isEmpty(record[field]) {
+			missing = append(missing, fmt.Sprintf("%s is required in %s", field, state))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrNotAllowed, strings.Join(missing, "; "))
+	}
+	return nil
+}
+
+// CheckEditable returns an error wrapping ErrNotAllowed if records in the
+// state a status is in can't be edited, because it is final or locked
+func (l *Lifecycle) CheckEditable(status string) error {
+	state, err := l.Current(status)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNotAllowed, err)
+	}
+	if s := l.States[state]; s.Final || s.Locked {
+		return fmt.Errorf("%w: records that are %s can't be edited", ErrNotAllowed, state)
+	}
+	return nil
+}
+
+// blockers returns the reasons a record cannot make a transition
+func (l *Lifecycle) blockers(t Transition, record map[string]interface{}, guards Guards) []string {
+	var blockers []string
+	for _, field := range l.States[t.To].Requires {
+		if isEmpty(record[field]) {
+			blockers = append(blockers, fmt.Sprintf("%s is required in %s", field, t.To))
+		}
+	}
+	for _, name := range t.Guards {
+		guard, ok := guards[name]
+		if !ok {
+			blockers = append(blockers, fmt.Sprintf("unknown guard %q", name))
+			continue
+		}
+		if reason := guard(record); reason != "" {
+			blockers = append(blockers, reason)
+		}
+	}
+	return blockers
+}
+
+// stateNames returns the names of the states in order
+func (l *Lifecycle) stateNames() []string {
+	names := make([]string, 0, len(l.States))
+	for name := range l.States {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isEmpty reports whether a field value is missing, empty or zero
+func isEmpty(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case float64:
+		return v == 0
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
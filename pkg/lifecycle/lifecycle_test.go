@@ -0,0 +1,126 @@
+package lifecycle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLifecycle() *Lifecycle {
+	return &Lifecycle{
+		Initial: "draft",
+		States: map[string]State{
+			"draft":     {},
+			"submitted": {Requires: []string{"supplier_id"}},
+			"shipped":   {Locked: true},
+			"closed":    {Final: true},
+			"cancelled": {Final: true},
+		},
+		Transitions: []Transition{
+			{From: "draft", To: "submitted", Guards: []string{"has_items"}},
+			{From: "draft", To: "cancelled"},
+			{From: "submitted", To: "shipped"},
+			{From: "shipped", To: "closed"},
+		},
+	}
+}
+
+var testGuards = Guards{
+	"has_items": func(record map[string]interface{}) string {
+		if items, _ := record["items"].([]interface{}); len(items) == 0 {
+			return "the record has no items"
+		}
+		return ""
+	},
+}
+
+func TestCheck(t *testing.T) {
+	l := testLifecycle()
+	ready := map[string]interface{}{"supplier_id": "s1", "items": []interface{}{"a"}}
+
+	assert.NoError(t, l.Check("draft", "submitted", ready, testGuards))
+	assert.NoError(t, l.Check("", "submitted", ready, testGuards), "empty status is the initial state")
+	assert.NoError(t, l.Check("closed", "closed", nil, testGuards), "staying put is allowed")
+
+	err := l.Check("draft", "submitted", map[string]interface{}{"supplier_id": " "}, testGuards)
+	assert.ErrorIs(t, err, ErrNotAllowed)
+	assert.ErrorContains(t, err, "supplier_id is required in submitted; the record has no items")
+
+	err = l.Check("draft", "closed", ready, testGuards)
+	assert.ErrorContains(t, err, "from draft a record can move to submitted, cancelled")
+
+	err = l.Check("cancelled", "draft", ready, testGuards)
+	assert.ErrorContains(t, err, "no transitions out of cancelled")
+
+	assert.NoError(t, l.Check("shipped", "closed", ready, testGuards), "locked records can still move")
+
+	err = l.Check("returned", "closed", ready, testGuards)
+	assert.ErrorIs(t, err, ErrNotAllowed)
+	assert.ErrorContains(t, err, `status "returned" is not a state`)
+}
+
+func TestCheckEdit(t *testing.T) {
+	l := testLifecycle()
+
+	assert.NoError(t, l.CheckEdit("", map[string]interface{}{}), "empty status is the initial state")
+	assert.NoError(t, l.CheckEdit("submitted", map[string]interface{}{"supplier_id": "s1"}))
+
+	err := l.CheckEdit("submitted", map[string]interface{}{"supplier_id": ""})
+	assert.ErrorIs(t, err, ErrNotAllowed)
+	assert.ErrorContains(t, err, "supplier_id is required in submitted")
+
+	for _, status := range []string{"shipped", "closed"} {
+		err = l.CheckEdit(status, map[string]interface{}{"supplier_id": "s1"})
+		assert.ErrorIs(t, err, ErrNotAllowed, status)
+		assert.ErrorContains(t, err, "records that are "+status+" can't be edited")
+	}
+
+	err = l.CheckEditable("returned")
+	assert.ErrorIs(t, err, ErrNotAllowed)
+}
+
+func TestMoves(t *testing.T) {
+	l := testLifecycle()
+
+	moves, err := l.Moves("draft", map[string]interface{}{"supplier_id": "s1"}, testGuards)
+
+	require.NoError(t, err)
+	assert.Equal(t, []Move{
+		{To: "submitted", Allowed: false, Blockers: []string{"the record has no items"}},
+		{To: "cancelled", Allowed: true},
+	}, moves)
+
+	moves, err = l.Moves("closed", nil, testGuards)
+	require.NoError(t, err)
+	assert.Empty(t, moves)
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "lifecycle.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"initial": "draft",
+		"states": {"draft": {}, "done": {"final": true}},
+		"transitions": [{"from": "draft", "to": "done", "guards": ["has_items"]}]
+	}`), 0600))
+
+	l, err := Load(path, testGuards)
+	require.NoError(t, err)
+	assert.Equal(t, "draft", l.Initial)
+	assert.True(t, l.States["done"].Final)
+
+	bad := filepath.Join(dir, "bad.json")
+	require.NoError(t, os.WriteFile(bad, []byte(`{
+		"initial": "new",
+		"states": {"draft": {}, "done": {"final": true}},
+		"transitions": [{"from": "done", "to": "draft"}, {"from": "draft", "to": "gone", "guards": ["magic"]}]
+	}`), 0600))
+	_, err = Load(bad, testGuards)
+	assert.ErrorContains(t, err, `initial state "new" is not defined`)
+	assert.ErrorContains(t, err, `"done" is final`)
+	assert.ErrorContains(t, err, `state "gone" is not defined`)
+	assert.ErrorContains(t, err, `unknown guard "magic"`)
+}
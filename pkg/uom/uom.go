@@ -0,0 +1,453 @@
+// Package uom keeps a registry of units of measure and converts quantities
+// between them.
+//
+// Every unit has a dimension, such as length or mass, and a factor: how many
+// of its dimension's base unit one of it is. Quantities convert between units
+// of the same dimension only. Parts can also have a base unit, which their
+// stock is counted in, and alternate units of their own, such as a reel of
+// 305 m or a box of 50 each, which may convert across dimensions.
+//
+// Quantities are fractional. Each unit keeps them to a number of decimal
+// places, rounding to the nearest step, up or down, or rejecting finer
+// quantities, as units counted in whole pieces do.
+package uom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Rounding modes of units
+const (
+	// RoundNearest rounds to the nearest step, halves away from zero
+	RoundNearest = "nearest"
+	// RoundUp rounds away from zero
+	RoundUp = "up"
+	// RoundDown rounds toward zero
+	RoundDown = "down"
+	// RoundExact rejects quantities finer than the unit's precision
+	RoundExact = "exact"
+)
+
+// unitlessPrecision is the precision of quantities with no unit, enough to
+// absorb floating point error without losing any real quantity
+const unitlessPrecision = 6
+
+// maxPrecision bounds the decimal places of a unit
+const maxPrecision = 9
+
+// Dimensions of the default units
+const (
+	Count  = "count"
+	Length = "length"
+	Mass   = "mass"
+	Volume = "volume"
+	Area   = "area"
+)
+
+// ErrUnknownUnit is returned for a unit that is not in the registry
+var ErrUnknownUnit = errors.New("unknown unit of measure")
+
+// ErrIncompatibleUnits is returned when a quantity cannot be converted
+// between two units, such as from meters to kilograms
+var ErrIncompatibleUnits = errors.New("incompatible units of measure")
+
+// ErrInexactQuantity is returned for a quantity finer than the precision of
+// a unit that rounds exactly, such as half of a unit counted in each
+var ErrInexactQuantity = errors.New("quantity is finer than its unit allows")
+
+// Unit is a unit of measure
+type Unit struct {
+	Symbol    string `json:"symbol"`
+	Name      string `json:"name,omitempty"`
+	Dimension string `json:"dimension"`
+	// Factor is how many of the dimension's base unit one of the unit is
+	Factor  float64  `json:"factor"`
+	Aliases []string `json:"aliases,omitempty"`
+	// Precision is the decimal places quantities in the unit are kept to,
+	// and Rounding how they are rounded to it (default RoundNearest)
+	Precision int    `json:"precision"`
+	Rounding  string `json:"rounding,omitempty"`
+}
+
+// PartUnits are the units a part is counted in
+type PartUnits struct {
+	// BaseUnit is the unit the part's stock is counted in
+	BaseUnit string `json:"base_unit"`
+	// Alternates are further units of the part by how many of the base unit
+	// one of them holds
+	Alternates map[string]float64 `json:"alternates,omitempty"`
+}
+
+// Registry is a set of units and the units of parts
+type Registry struct {
+	Units []Unit               `json:"units"`
+	Parts map[string]PartUnits `json:"parts,omitempty"`
+
+	bySymbol map[string]*Unit
+}
+
+// defaultUnits are the units every registry has
+var defaultUnits = []Unit{
+	{Symbol: "ea", Name: "each", Dimension: Count, Factor: 1, Aliases: []string{"each", "pc", "pcs", "piece", "pieces", "unit", "units"}, Precision: 0, Rounding: RoundExact},
+	{Symbol: "pair", Name: "pair", Dimension: Count, Factor: 2, Aliases: []string{"pr", "pairs"}, Precision: 0, Rounding: RoundExact},
+	{Symbol: "dozen", Name: "dozen", Dimension: Count, Factor: 12, Aliases: []string{"dz", "doz"}, Precision: 0, Rounding: RoundExact},
+	{Symbol: "mm", Name: "millimeter", Dimension: Length, Factor: 0.001, Precision: 1},
+	{Symbol: "cm", Name: "centimeter", Dimension: Length, Factor: 0.01, Precision: 2},
+	{Symbol: "m", Name: "meter", Dimension: Length, Factor: 1, Aliases: []string{"meter", "meters", "metre", "metres"}, Precision: 3},
+	{Symbol: "km", Name: "kilometer", Dimension: Length, Factor: 1000, Precision: 6},
+	{Symbol: "in", Name: "inch", Dimension: Length, Factor: 0.0254, Aliases: []string{"inch", "inches"}, Precision: 2},
+	{Symbol: "ft", Name: "foot", Dimension: Length, Factor: 0.3048, Aliases: []string{"foot", "feet"}, Precision: 3},
+	{Symbol: "yd", Name: "yard", Dimension: Length, Factor: 0.9144, Aliases: []string{"yard", "yards"}, Precision: 3},
+	{Symbol: "mg", Name: "milligram", Dimension: Mass, Factor: 0.000001, Precision: 0},
+	{Symbol: "g", Name: "gram", Dimension: Mass, Factor: 0.001, Aliases: []string{"gram", "grams"}, Precision: 3},
+	{Symbol: "kg", Name: "kilogram", Dimension: Mass, Factor: 1, Aliases: []string{"kilogram", "kilograms"}, Precision: 6},
+	{Symbol: "oz", Name: "ounce", Dimension: Mass, Factor: 0.028349523125, Aliases: []string{"ounce", "ounces"}, Precision: 3},
+	{Symbol: "lb", Name: "pound", Dimension: Mass, Factor: 0.45359237, Aliases: []string{"lbs", "pound", "pounds"}, Precision: 4},
+	{Symbol: "ml", Name: "milliliter", Dimension: Volume, Factor: 0.001, Precision: 1},
+	{Symbol: "l", Name: "liter", Dimension: Volume, Factor: 1, Aliases: []string{"liter", "liters", "litre", "litres"}, Precision: 4},
+	{Symbol: "gal", Name: "US gallon", Dimension: Volume, Factor: 3.785411784, Aliases: []string{"gallon", "gallons"}, Precision: 4},
+	{Symbol: "mm2", Name: "square millimeter", Dimension: Area, Factor: 0.000001, Precision: 0},
+	{Symbol: "cm2", Name: "square centimeter", Dimension: Area, Factor: 0.0001, Precision: 2},
+	{Symbol: "m2", Name: "square meter", Dimension: Area, Factor: 1, Aliases: []string{"sqm"}, Precision: 4},
+	{Symbol: "in2", Name: "square inch", Dimension: Area, Factor: 0.00064516, Aliases: []string{"sqin"}, Precision: 2},
+	{Symbol: "ft2", Name: "square foot", Dimension: Area, Factor: 0.09290304, Aliases: []string{"sqft"}, Precision: 3},
+}
+
+// Default returns a registry of the default units and no part units
+func Default() *Registry {
+	r := &Registry{Units: append([]Unit{}, defaultUnits...)}
+	if err := r.Validate(); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Load reads units and part units from a JSON file and adds them to the
+// default units. A unit with the symbol of a default unit replaces it.
+func Load(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read units: %w", err)
+	}
+	var file Registry
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse units: %w", err)
+	}
+
+	r := &Registry{Parts: file.Parts}
+	replaced := map[string]bool{}
+	for _, u := range file.Units {
+		replaced[normalize(u.Symbol)] = true
+	}
+	for _, u := range defaultUnits {
+		if !replaced[normalize(u.Symbol)] {
+			r.Units = append(r.Units, u)
+		}
+	}
+	r.Units = append(r.Units, file.Units...)
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Validate checks that every unit has a symbol, a dimension and a positive
+// factor, that no symbol or alias names two units, and that every part's
+// base unit is known and its alternates are positive. It indexes the units
+// for lookup.
+func (r *Registry) Validate() error {
+	var problems []string
+	bySymbol := map[string]*Unit{}
+	for i := range r.Units {
+		u := &r.Units[i]
+		if strings.TrimSpace(u.Symbol) == "" {
+			problems = append(problems, fmt.Sprintf("unit %d has no symbol", i+1))
+			continue
+		}
+		if u.Dimension == "" {
+			problems = append(problems, fmt.Sprintf("unit %s has no dimension", u.Symbol))
+		}
+		if !(u.Factor > 0) || math.IsInf(u.Factor, 0) {
+			problems = append(problems, fmt.Sprintf("unit %s must have a positive factor", u.Symbol))
+		}
+		if u.Precision < 0 || u.Precision > maxPrecision {
+			problems = append(problems, fmt.Sprintf("unit %s must have a precision of 0 to %d decimal places", u.Symbol, maxPrecision))
+		}
+		switch u.Rounding {
+		case "", RoundNearest, RoundUp, RoundDown, RoundExact:
+		default:
+			problems = append(problems, fmt.Sprintf("unit %s has unknown rounding %q, it must be %s, %s, %s or %s", u.Symbol, u.Rounding, RoundNearest, RoundUp, RoundDown, RoundExact))
+		}
+		for _, name := range append([]string{u.Symbol}, u.Aliases...) {
+			key := normalize(name)
+			if other, ok := bySymbol[key]; ok {
+				problems = append(problems, fmt.Sprintf("%q names both %s and %s", name, other.Symbol, u.Symbol))
+				continue
+			}
+			bySymbol[key] = u
+		}
+	}
+	r.bySymbol = bySymbol
+
+	partIDs := make([]string, 0, len(r.Parts))
+	for id := range r.Parts {
+		partIDs = append(partIDs, id)
+	}
+	sort.Strings(partIDs)
+	for _, id := range partIDs {
+		p := r.Parts[id]
+		if _, ok := bySymbol[normalize(p.BaseUnit)]; !ok {
+			problems = append(problems, fmt.Sprintf("part %s: base unit %q is not a known unit", id, p.BaseUnit))
+		}
+		for name, factor := range p.Alternates {
+			if !(factor > 0) || math.IsInf(factor, 0) {
+				problems = append(problems, fmt.Sprintf("part %s: alternate unit %q must hold a positive quantity of %s", id, name, p.BaseUnit))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("invalid units: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Lookup returns the unit a symbol or alias names, ignoring case
+func (r *Registry) Lookup(symbol string) (Unit, error) {
+	u, ok := r.bySymbol[normalize(symbol)]
+	if !ok {
+		return Unit{}, fmt.Errorf("%w: %q", ErrUnknownUnit, symbol)
+	}
+	return *u, nil
+}
+
+// Round rounds a quantity to the unit's precision by its rounding mode. A
+// unit that rounds exactly rejects quantities finer than its precision with
+// ErrInexactQuantity.
+func (u Unit) Round(quantity float64) (float64, error) {
+	scale := math.Pow10(u.Precision)
+	scaled := quantity * scale
+	// Snap values a floating point error away from a step onto it, so 0.1+0.2
+	// is taken as 0.3 rather than rounded up past it
+	if nearest := math.Round(scaled); math.Abs(scaled-nearest) < 1e-6 {
+		scaled = nearest
+	}
+	switch u.Rounding {
+	case RoundUp:
+		if scaled < 0 {
+			return math.Floor(scaled) / scale, nil
+		}
+		return math.Ceil(scaled) / scale, nil
+	case RoundDown:
+		return math.Trunc(scaled) / scale, nil
+	case RoundExact:
+		if scaled != math.Trunc(scaled) {
+			return 0, fmt.Errorf("%w: %s is kept to %d decimal places, %g has more", ErrInexactQuantity, u.Symbol, u.Precision, quantity)
+		}
+		return scaled / scale, nil
+	default:
+		return math.Round(scaled) / scale, nil
+	}
+}
+
+// Round rounds a quantity in a unit by the unit's rules. Quantities with no
+// unit are rounded to the nearest millionth.
+func (r *Registry) Round(quantity float64, unit string) (float64, error) {
+	if strings.TrimSpace(unit) == "" {
+		return Unit{Precision: unitlessPrecision}.Round(quantity)
+	}
+	u, err := r.Lookup(unit)
+	if err != nil {
+		return 0, err
+	}
+	return u.Round(quantity)
+}
+
+// Convert converts a quantity between two units of the same dimension
+func (r *Registry) Convert(quantity float64, from, to string) (float64, error) {
+	f, err := r.Lookup(from)
+	if err != nil {
+		return 0, err
+	}
+	t, err := r.Lookup(to)
+	if err != nil {
+		return 0, err
+	}
+	if f.Dimension != t.Dimension {
+		return 0, fmt.Errorf("%w: %s is a unit of %s and %s a unit of %s", ErrIncompatibleUnits, f.Symbol, f.Dimension, t.Symbol, t.Dimension)
+	}
+	if f.Symbol == t.Symbol {
+		return quantity, nil
+	}
+	return quantity * f.Factor / t.Factor, nil
+}
+
+// Part returns the units of a part, if it has any
+func (r *Registry) Part(partID string) (PartUnits, bool) {
+	p, ok := r.Parts[partID]
+	return p, ok
+}
+
+// ToBase converts a quantity of a part to the part's base unit, rounded by
+// the base unit's rules, and returns it with the symbol of the base unit. A
+// quantity with no unit is already in the base unit. The part's alternate
+// units are tried before the registry's.
+//
+// A part without units has no base unit: its quantities are converted to
+// the base unit of their unit's dimension, so 2 dozen is 24 each, and
+// returned with that unit's symbol.
+func (r *Registry) ToBase(partID string, quantity float64, unit string) (float64, string, error) {
+	p, ok := r.Parts[partID]
+	if !ok {
+		if strings.TrimSpace(unit) == "" {
+			q, err := r.Round(quantity, "")
+			return q, "", err
+		}
+		u, err := r.Lookup(unit)
+		if err != nil {
+			return 0, "", err
+		}
+		base := r.dimensionBase(u)
+		q, err := base.Round(quantity * u.Factor / base.Factor)
+		if err != nil {
+			return 0, "", err
+		}
+		return q, base.Symbol, nil
+	}
+
+	base, err := r.Lookup(p.BaseUnit)
+	if err != nil {
+		return 0, "", err
+	}
+	converted, err := r.toBase(partID, p, base, quantity, unit)
+	if err != nil {
+		return 0, "", err
+	}
+	q, err := base.Round(converted)
+	if err != nil {
+		return 0, "", fmt.Errorf("part %s: %w", partID, err)
+	}
+	return q, base.Symbol, nil
+}
+
+// DimensionBase returns the unit of a dimension with a factor of 1,
+// preferring a default unit such as ea or kg, and whether there is one
+func (r *Registry) DimensionBase(dimension string) (Unit, bool) {
+	for _, d := range defaultUnits {
+		if d.Dimension != dimension || d.Factor != 1 {
+			continue
+		}
+		if base, err := r.Lookup(d.Symbol); err == nil && base.Dimension == dimension && base.Factor == 1 {
+			return base, true
+		}
+	}
+	for _, base := range r.Units {
+		if base.Dimension == dimension && base.Factor == 1 {
+			return base, true
+		}
+	}
+	return Unit{}, false
+}
+
+// dimensionBase returns the base unit of a unit's dimension, or the unit
+// itself if its dimension has none
+func (r *Registry) dimensionBase(u Unit) Unit {
+	if base, ok := r.DimensionBase(u.Dimension); ok {
+		return base
+	}
+	return u
+}
+
+// toBase converts a quantity of a part to its base unit without rounding
+func (r *Registry) toBase(partID string, p PartUnits, base Unit, quantity float64, unit string) (float64, error) {
+	if strings.TrimSpace(unit) == "" {
+		return quantity, nil
+	}
+	for name, factor := range p.Alternates {
+		if normalize(name) == normalize(unit) {
+			return quantity * factor, nil
+		}
+	}
+	converted, err := r.Convert(quantity, unit, base.Symbol)
+	if errors.Is(err, ErrIncompatibleUnits) {
+		return 0, fmt.Errorf("%w: part %s is counted in %s, which %s does not convert to", ErrIncompatibleUnits, partID, base.Symbol, unit)
+	}
+	return converted, err
+}
+
+// BaseFactor returns how many of a part's base unit one unit is, unrounded,
+// with the base unit's symbol, for converting a price or rate per unit to
+// one per base unit. A part without units is counted in the base unit of
+// the unit's dimension, as ToBase converts it.
+func (r *Registry) BaseFactor(partID, unit string) (float64, string, error) {
+	p, ok := r.Parts[partID]
+	if !ok {
+		if strings.TrimSpace(unit) == "" {
+			return 1, "", nil
+		}
+		u, err := r.Lookup(unit)
+		if err != nil {
+			return 0, "", err
+		}
+		base := r.dimensionBase(u)
+		return u.Factor / base.Factor, base.Symbol, nil
+	}
+	base, err := r.Lookup(p.BaseUnit)
+	if err != nil {
+		return 0, "", err
+	}
+	factor, err := r.toBase(partID, p, base, 1, unit)
+	if err != nil {
+		return 0, "", err
+	}
+	return factor, base.Symbol, nil
+}
+
+// ConvertFor converts a quantity of a part between two units, either of
+// which may be one of the part's alternate units or empty for its base unit
+func (r *Registry) ConvertFor(partID string, quantity float64, from, to string) (float64, error) {
+	p, ok := r.Parts[partID]
+	if !ok {
+		if strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+			return 0, fmt.Errorf("%w: part %s has no base unit, give both units", ErrIncompatibleUnits, partID)
+		}
+		return r.Convert(quantity, from, to)
+	}
+	base, err := r.Lookup(p.BaseUnit)
+	if err != nil {
+		return 0, err
+	}
+	inBase, err := r.toBase(partID, p, base, quantity, from)
+	if err != nil {
+		return 0, err
+	}
+	one, err := r.toBase(partID, p, base, 1, to)
+	if err != nil {
+		return 0, err
+	}
+	return inBase / one, nil
+}
+
+// Compatible reports whether quantities in two units can be added together,
+// for a part without units: both are empty, or both are units of the same
+// dimension
+func (r *Registry) Compatible(a, b string) bool {
+	if strings.TrimSpace(a) == "" || strings.TrimSpace(b) == "" {
+		return strings.TrimSpace(a) == strings.TrimSpace(b)
+	}
+	ua, errA := r.Lookup(a)
+	ub, errB := r.Lookup(b)
+	return errA == nil && errB == nil && ua.Dimension == ub.Dimension
+}
+
+// normalize returns the lookup key of a unit symbol
+func normalize(symbol string) string {
+	return strings.ToLower(strings.TrimSpace(symbol))
+}
@@ -0,0 +1,257 @@
+package uom
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeUnits(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "units.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestConvert(t *testing.T) {
+	r := Default()
+
+	q, err := r.Convert(150, "cm", "m")
+	require.NoError(t, err)
+	assert.InDelta(t, 1.5, q, 1e-9)
+
+	q, err = r.Convert(2, "Dozen", "EA")
+	require.NoError(t, err)
+	assert.InDelta(t, 24, q, 1e-9)
+
+	q, err = r.Convert(1, "lb", "g")
+	require.NoError(t, err)
+	assert.InDelta(t, 453.59237, q, 1e-9)
+
+	_, err = r.Convert(1, "m", "kg")
+	assert.ErrorIs(t, err, ErrIncompatibleUnits)
+
+	_, err = r.Convert(1, "furlong", "m")
+	assert.ErrorIs(t, err, ErrUnknownUnit)
+}
+
+func TestLoad(t *testing.T) {
+	path := writeUnits(t, `{
+		"units": [
+			{"symbol": "reel", "dimension": "count", "factor": 1},
+			{"symbol": "m", "name": "meter", "dimension": "length", "factor": 1, "aliases": ["mtr"]}
+		],
+		"parts": {
+			"cable": {"base_unit": "m", "alternates": {"spool": 305, "kg": 12.5}},
+			"bolt": {"base_unit": "ea", "alternates": {"box": 50}}
+		}
+	}`)
+	r, err := Load(path)
+	require.NoError(t, err)
+
+	u, err := r.Lookup("MTR")
+	require.NoError(t, err)
+	assert.Equal(t, "m", u.Symbol)
+	_, err = r.Lookup("meter")
+	assert.ErrorIs(t, err, ErrUnknownUnit, "replacing a default unit drops its aliases")
+
+	p, ok := r.Part("cable")
+	require.True(t, ok)
+	assert.Equal(t, "m", p.BaseUnit)
+	_, ok = r.Part("washer")
+	assert.False(t, ok)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"no factor":      `{"units": [{"symbol": "reel", "dimension": "count"}]}`,
+		"no dimension":   `{"units": [{"symbol": "reel", "factor": 1}]}`,
+		"alias clash":    `{"units": [{"symbol": "reel", "dimension": "count", "factor": 1, "aliases": ["ea"]}]}`,
+		"unknown base":   `{"parts": {"cable": {"base_unit": "furlong"}}}`,
+		"zero alternate": `{"parts": {"cable": {"base_unit": "m", "alternates": {"spool": 0}}}}`,
+		"not json":       `units`,
+	} {
+		_, err := Load(writeUnits(t, content))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestToBase(t *testing.T) {
+	r := Default()
+	r.Parts = map[string]PartUnits{
+		"cable": {BaseUnit: "m", Alternates: map[string]float64{"spool": 305, "kg": 12.5}},
+		"bolt":  {BaseUnit: "ea", Alternates: map[string]float64{"Box": 50}},
+	}
+	require.NoError(t, r.Validate())
+
+	for _, tc := range []struct {
+		part, unit string
+		quantity   float64
+		want       float64
+		base       string
+	}{
+		{"cable", "", 3, 3, "m"},
+		{"cable", "ft", 10, 3.048, "m"},
+		{"cable", "spool", 2, 610, "m"},
+		{"cable", "kg", 2, 25, "m"},
+		{"bolt", "box", 3, 150, "ea"},
+		{"bolt", "dozen", 1, 12, "ea"},
+		{"washer", "pcs", 7, 7, "ea"},
+		{"washer", "", 7, 7, ""},
+		{"washer", "dozen", 2, 24, "ea"},
+		{"washer", "g", 500, 0.5, "kg"},
+	} {
+		got, base, err := r.ToBase(tc.part, tc.quantity, tc.unit)
+		require.NoError(t, err, tc.part+" "+tc.unit)
+		assert.InDelta(t, tc.want, got, 1e-9, tc.part+" "+tc.unit)
+		assert.Equal(t, tc.base, base, tc.part+" "+tc.unit)
+	}
+
+	_, _, err := r.ToBase("cable", 1, "l")
+	assert.ErrorIs(t, err, ErrIncompatibleUnits)
+	_, _, err = r.ToBase("bolt", 1, "m")
+	assert.ErrorIs(t, err, ErrIncompatibleUnits)
+	_, _, err = r.ToBase("washer", 1, "furlong")
+	assert.ErrorIs(t, err, ErrUnknownUnit)
+}
+
+func TestBaseFactor(t *testing.T) {
+	r := Default()
+	r.Parts = map[string]PartUnits{"cable": {BaseUnit: "m", Alternates: map[string]float64{"spool": 305}}}
+	require.NoError(t, r.Validate())
+
+	f, base, err := r.BaseFactor("cable", "in")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.0254, f, 1e-12, "the factor is not rounded to the base unit's precision")
+	assert.Equal(t, "m", base)
+
+	f, _, err = r.BaseFactor("cable", "spool")
+	require.NoError(t, err)
+	assert.InDelta(t, 305, f, 1e-9)
+
+	f, base, err = r.BaseFactor("washer", "pcs")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, f)
+	assert.Equal(t, "ea", base)
+
+	f, base, err = r.BaseFactor("washer", "dozen")
+	require.NoError(t, err)
+	assert.Equal(t, 12.0, f)
+	assert.Equal(t, "ea", base)
+
+	_, _, err = r.BaseFactor("cable", "kg")
+	assert.ErrorIs(t, err, ErrIncompatibleUnits)
+}
+
+func TestDimensionBase(t *testing.T) {
+	r := Default()
+	r.Units = append([]Unit{{Symbol: "reel", Dimension: Count, Factor: 1}}, r.Units...)
+	require.NoError(t, r.Validate())
+
+	u, ok := r.DimensionBase(Count)
+	require.True(t, ok)
+	assert.Equal(t, "ea", u.Symbol, "a default unit is preferred")
+	u, ok = r.DimensionBase(Mass)
+	require.True(t, ok)
+	assert.Equal(t, "kg", u.Symbol)
+	_, ok = r.DimensionBase("time")
+	assert.False(t, ok)
+}
+
+func TestConvertFor(t *testing.T) {
+	r := Default()
+	r.Parts = map[string]PartUnits{"cable": {BaseUnit: "m", Alternates: map[string]float64{"spool": 305}}}
+	require.NoError(t, r.Validate())
+
+	q, err := r.ConvertFor("cable", 610, "", "spool")
+	require.NoError(t, err)
+	assert.InDelta(t, 2, q, 1e-9)
+
+	q, err = r.ConvertFor("cable", 1, "spool", "ft")
+	require.NoError(t, err)
+	assert.InDelta(t, 305/0.3048, q, 1e-9)
+
+	q, err = r.ConvertFor("washer", 2, "dozen", "ea")
+	require.NoError(t, err)
+	assert.InDelta(t, 24, q, 1e-9)
+
+	_, err = r.ConvertFor("washer", 2, "", "ea")
+	assert.ErrorIs(t, err, ErrIncompatibleUnits)
+}
+
+func TestCompatible(t *testing.T) {
+	r := Default()
+	assert.True(t, r.Compatible("", ""))
+	assert.True(t, r.Compatible("m", "ft"))
+	assert.False(t, r.Compatible("m", "kg"))
+	assert.False(t, r.Compatible("m", ""))
+	assert.False(t, r.Compatible("furlong", "furlong"))
+}
+
+func TestRound(t *testing.T) {
+	r := Default()
+
+	q, err := r.Round(0.1+0.2, "m")
+	require.NoError(t, err)
+	assert.Equal(t, 0.3, q)
+
+	q, err = r.Round(1.23456, "m")
+	require.NoError(t, err)
+	assert.Equal(t, 1.235, q)
+
+	q, err = r.Round(0.1+0.2, "")
+	require.NoError(t, err)
+	assert.Equal(t, 0.3, q)
+
+	_, err = r.Round(1.5, "ea")
+	assert.ErrorIs(t, err, ErrInexactQuantity)
+	q, err = r.Round(3, "pcs")
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, q)
+
+	up := Unit{Symbol: "sheet", Dimension: Count, Factor: 1, Precision: 1, Rounding: RoundUp}
+	q, err = up.Round(2.01)
+	require.NoError(t, err)
+	assert.Equal(t, 2.1, q)
+	q, err = up.Round(-2.01)
+	require.NoError(t, err)
+	assert.Equal(t, -2.1, q)
+
+	down := Unit{Symbol: "sheet", Dimension: Count, Factor: 1, Precision: 1, Rounding: RoundDown}
+	q, err = down.Round(2.09)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, q)
+}
+
+func TestToBaseRounding(t *testing.T) {
+	r := Default()
+	r.Parts = map[string]PartUnits{
+		"bolt":     {BaseUnit: "ea", Alternates: map[string]float64{"box": 50}},
+		"adhesive": {BaseUnit: "g"},
+	}
+	require.NoError(t, r.Validate())
+
+	q, _, err := r.ToBase("bolt", 1.5, "box")
+	require.NoError(t, err)
+	assert.Equal(t, 75.0, q)
+
+	_, _, err = r.ToBase("bolt", 1.01, "box")
+	assert.ErrorIs(t, err, ErrInexactQuantity)
+
+	q, _, err = r.ToBase("adhesive", 0.0123456, "kg")
+	require.NoError(t, err)
+	assert.Equal(t, 12.346, q)
+}
+
+func TestLoadInvalidRounding(t *testing.T) {
+	for name, content := range map[string]string{
+		"negative precision": `{"units": [{"symbol": "reel", "dimension": "count", "factor": 1, "precision": -1}]}`,
+		"unknown rounding":   `{"units": [{"symbol": "reel", "dimension": "count", "factor": 1, "rounding": "bankers"}]}`,
+	} {
+		_, err := Load(writeUnits(t, content))
+		assert.Error(t, err, name)
+	}
+}